@@ -10,6 +10,7 @@ import (
 
 	"github.com/spf13/viper"
 	"tts/configs"
+	"tts/internal/prosody"
 )
 
 // Config 包含应用程序的所有配置
@@ -60,6 +61,7 @@ type TTSConfig struct {
 	MaxTextLength     int               `mapstructure:"max_text_length"`
 	RequestTimeout    int               `mapstructure:"request_timeout"`
 	MaxConcurrent     int               `mapstructure:"max_concurrent"`
+	QueueTimeout      int               `mapstructure:"queue_timeout"`
 	SegmentThreshold  int               `mapstructure:"segment_threshold"`
 	MinSentenceLength int               `mapstructure:"min_sentence_length"`
 	MaxSentenceLength int               `mapstructure:"max_sentence_length"`
@@ -67,17 +69,154 @@ type TTSConfig struct {
 	
 	// 长文本处理配置
 	LongText LongTextConfig `mapstructure:"long_text"`
+
+	// 多角色剧本配置
+	Dialogue DialogueConfig `mapstructure:"dialogue"`
+
+	// 自动语言检测配置
+	Language LanguageConfig `mapstructure:"language"`
+
+	// 文本规范化配置
+	Normalize NormalizeConfig `mapstructure:"normalize"`
+
+	// 发音词典配置
+	Dictionary DictionaryConfig `mapstructure:"dictionary"`
+
+	// 文本替换规则配置
+	Replace ReplaceConfig `mapstructure:"replace"`
+
+	// Markdown 处理配置
+	Markdown MarkdownConfig `mapstructure:"markdown"`
+
+	// 行内指令配置
+	Directives DirectivesConfig `mapstructure:"directives"`
+
+	// 简繁转换配置
+	Chinese ChineseConfig `mapstructure:"chinese"`
+
+	// 请求脚本配置
+	Scripts ScriptsConfig `mapstructure:"scripts"`
+
+	// 语音能力检查配置
+	VoiceCheck VoiceCheckConfig `mapstructure:"voice_check"`
+
+	// 语音列表配置
+	VoiceList VoiceListConfig `mapstructure:"voice_list"`
+}
+
+// VoiceListConfig 语音列表（/api/voices）配置
+type VoiceListConfig struct {
+	Sort             []string `mapstructure:"sort"`              // 默认排序字段，前缀 - 表示降序（默认 preferred、local_name）
+	PreferredVoices  []string `mapstructure:"preferred_voices"`  // 排在最前面的语音，支持 * 通配符（默认 *xiaoxiao*）
+	PreferredLocales []string `mapstructure:"preferred_locales"` // 其次是这些语言区域前缀的语音（默认 zh）
+}
+
+// VoiceCheckConfig 语音能力检查配置：请求发往上游之前按语音列表检查语音、风格、角色和音频格式
+type VoiceCheckConfig struct {
+	Mode string `mapstructure:"mode"` // off 不检查，fallback 不支持的风格和角色改用默认值，strict 拒绝请求（默认 fallback）
+}
+
+// ScriptsConfig 请求脚本配置：合成前按文件名顺序执行目录中的 Lua 脚本
+type ScriptsConfig struct {
+	Dir            string `mapstructure:"dir"`             // 脚本目录（*.lua），为空时不执行脚本
+	ReloadInterval int    `mapstructure:"reload_interval"` // 检查脚本变化的间隔（秒，默认 10）
+	TimeoutMs      int    `mapstructure:"timeout_ms"`      // 每个请求执行全部脚本的时间上限（毫秒，默认 50）
+	MaxCallDepth   int    `mapstructure:"max_call_depth"`  // 脚本调用栈深度上限（默认 200）
+	OnError        string `mapstructure:"on_error"`        // 脚本出错或超时：skip 丢弃该脚本的修改，reject 请求失败（默认 skip）
+}
+
+// ChineseConfig 简繁转换配置
+type ChineseConfig struct {
+	AutoConvert bool              `mapstructure:"auto_convert"` // 按语音的语言自动转换：zh-TW、zh-HK 转繁体，zh-CN 转简体
+	Locales     map[string]string `mapstructure:"locales"`      // 语言 -> 转换方式，覆盖默认的对应关系，none 表示不转换
+	DictDir     string            `mapstructure:"dict_dir"`     // OpenCC 格式词典目录，补充内置的常用字词表
+}
+
+// DirectivesConfig 行内指令配置
+type DirectivesConfig struct {
+	Enabled bool `mapstructure:"enabled"` // 默认解析行内指令（也可按请求开启），关闭时 [note: x] 等方括号按原文朗读
+}
+
+// MarkdownConfig Markdown 处理配置
+type MarkdownConfig struct {
+	Mode             string `mapstructure:"mode"`               // markdown 解析文档结构，plain 删除 *#>~ 符号，off 不处理（默认 markdown）
+	HeadingPauseMs   *int   `mapstructure:"heading_pause_ms"`   // 标题后的停顿（毫秒，默认 700，0 表示不停顿）
+	ParagraphPauseMs *int   `mapstructure:"paragraph_pause_ms"` // 段落、代码块和分隔线后的停顿（毫秒，默认 400，0 表示不停顿）
+	ListPauseMs      *int   `mapstructure:"list_pause_ms"`      // 列表项和表格行之间的停顿（毫秒，默认 300，0 表示不停顿）
+	Emphasis         bool   `mapstructure:"emphasis"`           // 标题和粗体使用强调
+	Code             string `mapstructure:"code"`               // 代码块：skip 跳过，announce 提示已省略（默认 skip）
+}
+
+// ReplaceConfig 文本替换规则配置，规则集名称和 API 密钥不区分大小写
+type ReplaceConfig struct {
+	Default  string                         `mapstructure:"default"`   // 默认规则集，为空时不替换
+	Keys     map[string]string              `mapstructure:"keys"`      // API 密钥 -> 规则集
+	RuleSets map[string][]ReplaceRuleConfig `mapstructure:"rule_sets"` // 规则集名称 -> 按顺序执行的规则
+}
+
+// ReplaceRuleConfig 一条文本替换规则
+type ReplaceRuleConfig struct {
+	Name        string `mapstructure:"name"`
+	Pattern     string `mapstructure:"pattern"`
+	Replacement string `mapstructure:"replacement"`
+	Regex       bool   `mapstructure:"regex"`   // 为 false 时按字面文本匹配
+	Scope       string `mapstructure:"scope"`   // 适用的语言，如 zh、en-US，为空时适用于全部语言
+	Enabled     *bool  `mapstructure:"enabled"` // 为空时启用
+}
+
+// DictionaryConfig 发音词典配置
+type DictionaryConfig struct {
+	Path           string `mapstructure:"path"`            // 词典文件（YAML），为空时词典只保存在内存中
+	ReloadInterval int    `mapstructure:"reload_interval"` // 检查词典文件变化的间隔（秒，默认 10）
+}
+
+// NormalizeConfig 文本规范化配置
+type NormalizeConfig struct {
+	Enabled bool     `mapstructure:"enabled"` // 默认启用规范化（也可按请求指定 normalize）
+	Mode    string   `mapstructure:"mode"`    // rewrite 改写为读法，say_as 日期、时间、电话用 say-as 标注（默认 rewrite）
+	Rules   []string `mapstructure:"rules"`   // 启用的规则，为空时启用全部规则
+}
+
+// LanguageConfig 自动语言检测配置
+type LanguageConfig struct {
+	AutoDetect   bool              `mapstructure:"auto_detect"`    // 默认启用自动语言检测（也可按请求开启）
+	MinRunLength int               `mapstructure:"min_run_length"` // 切换语言所需的最少字母数（默认 8）
+	LocaleVoices map[string]string `mapstructure:"locale_voices"`  // 语言 -> 语音，如 en-US: en-US-JennyNeural
+}
+
+// DialogueConfig 多角色剧本合成配置
+type DialogueConfig struct {
+	LineGapMs *int                  `mapstructure:"line_gap_ms"` // 行间静音时长（毫秒，默认 400，0 表示不插入静音）
+	Cast      map[string]CastConfig `mapstructure:"cast"`        // 默认角色表，角色名 -> 语音参数（可按请求覆盖）
+}
+
+// CastConfig 角色使用的语音参数
+type CastConfig struct {
+	Voice string `mapstructure:"voice"`
+	Style string `mapstructure:"style"`
+	Rate  string `mapstructure:"rate"`
+	Pitch string `mapstructure:"pitch"`
 }
 
 // LongTextConfig 长文本 TTS 处理配置
 type LongTextConfig struct {
-	Enabled            bool   `mapstructure:"enabled"`              // 是否启用长文本优化处理
-	MaxSegmentLength   int    `mapstructure:"max_segment_length"`   // 每个分段的最大字符数（默认 500）
-	WorkerCount        int    `mapstructure:"worker_count"`         // 并发 worker 数量（默认 5）
-	MinTextForSplit    int    `mapstructure:"min_text_for_split"`   // 触发分段的最小文本长度（默认 1000）
-	FFmpegPath         string `mapstructure:"ffmpeg_path"`          // FFmpeg 可执行文件路径（留空使用系统 PATH）
-	UseSmartSegment    bool   `mapstructure:"use_smart_segment"`    // 是否使用智能分段（基于句子边界）
-	UseFFmpegMerge     bool   `mapstructure:"use_ffmpeg_merge"`     // 是否使用 FFmpeg 合并音频（推荐）
+	Enabled             bool   `mapstructure:"enabled"`              // 是否启用长文本优化处理
+	MaxSegmentLength    int    `mapstructure:"max_segment_length"`   // 每个分段的最大字符数（默认 500）
+	WorkerCount         int    `mapstructure:"worker_count"`         // 并发 worker 数量（默认 5）
+	MinTextForSplit     int    `mapstructure:"min_text_for_split"`   // 触发分段的最小文本长度（默认 1000）
+	FFmpegPath          string `mapstructure:"ffmpeg_path"`          // FFmpeg 可执行文件路径（留空使用系统 PATH）
+	UseSmartSegment     bool   `mapstructure:"use_smart_segment"`    // 是否使用智能分段（基于句子边界）
+	SegmentStrategy     string `mapstructure:"segment_strategy"`     // 默认分段策略：smart、fixed、paragraph、line（留空由 use_smart_segment 决定）
+	SegmentBudget       string `mapstructure:"segment_budget"`       // 分段长度单位：runes（字符数，默认）或 ssml_bytes（XML 转义后字节数）
+	UseFFmpegMerge      bool   `mapstructure:"use_ffmpeg_merge"`     // 是否使用 FFmpeg 合并音频（推荐）
+	AdaptiveConcurrency bool   `mapstructure:"adaptive_concurrency"` // 是否根据上游表现自动调整并发（AIMD），上限为 worker_count
+	TargetLatencyMs     int    `mapstructure:"target_latency_ms"`    // 自适应并发的目标分段延迟（毫秒，默认 5000）
+	SegmentRetries      int    `mapstructure:"segment_retries"`      // 失败分段的最大重试轮数（默认 2）
+	RetryBackoffMs      int    `mapstructure:"retry_backoff_ms"`     // 分段重试的初始退避时间（毫秒，默认 500，每轮翻倍）
+	FallbackVoice       string `mapstructure:"fallback_voice"`       // 上游拒绝分段时使用的备用语音（留空不启用）
+	BestEffort          bool   `mapstructure:"best_effort"`          // 默认启用尽力模式：最终失败的分段以静音替代
+	SpillDir            string `mapstructure:"spill_dir"`            // 分段音频临时目录（留空使用系统临时目录）
+	MaxSpillMB          int    `mapstructure:"max_spill_mb"`         // 单个请求的分段音频总大小上限（MB，默认 1024）
 }
 
 var (
@@ -176,6 +315,9 @@ func setDefaults(cfg *Config) {
 	if cfg.TTS.MaxConcurrent == 0 {
 		cfg.TTS.MaxConcurrent = 20
 	}
+	if cfg.TTS.QueueTimeout == 0 {
+		cfg.TTS.QueueTimeout = 15
+	}
 
 	// 长文本处理默认值
 	if cfg.TTS.LongText.MaxSegmentLength == 0 {
@@ -187,6 +329,98 @@ func setDefaults(cfg *Config) {
 	if cfg.TTS.LongText.MinTextForSplit == 0 {
 		cfg.TTS.LongText.MinTextForSplit = 1000
 	}
+	if cfg.TTS.LongText.TargetLatencyMs == 0 {
+		cfg.TTS.LongText.TargetLatencyMs = 5000
+	}
+	if cfg.TTS.LongText.SegmentRetries == 0 {
+		cfg.TTS.LongText.SegmentRetries = 2
+	}
+	if cfg.TTS.LongText.RetryBackoffMs == 0 {
+		cfg.TTS.LongText.RetryBackoffMs = 500
+	}
+	if cfg.TTS.LongText.MaxSpillMB == 0 {
+		cfg.TTS.LongText.MaxSpillMB = 1024
+	}
+
+	// 多角色剧本默认值
+	if cfg.TTS.Dialogue.LineGapMs == nil {
+		gap := 400
+		cfg.TTS.Dialogue.LineGapMs = &gap
+	}
+
+	// 自动语言检测默认值
+	if cfg.TTS.Language.MinRunLength == 0 {
+		cfg.TTS.Language.MinRunLength = 8
+	}
+
+	// 文本规范化默认值
+	if cfg.TTS.Normalize.Mode == "" {
+		cfg.TTS.Normalize.Mode = "rewrite"
+	}
+
+	// Markdown 默认值
+	if cfg.TTS.Markdown.Mode == "" {
+		cfg.TTS.Markdown.Mode = "markdown"
+	}
+	for _, pause := range []struct {
+		value **int
+		ms    int
+	}{
+		{&cfg.TTS.Markdown.HeadingPauseMs, 700},
+		{&cfg.TTS.Markdown.ParagraphPauseMs, 400},
+		{&cfg.TTS.Markdown.ListPauseMs, 300},
+	} {
+		if *pause.value == nil {
+			ms := pause.ms
+			*pause.value = &ms
+		}
+	}
+	if cfg.TTS.Markdown.Code == "" {
+		cfg.TTS.Markdown.Code = "skip"
+	}
+
+	// 发音词典默认值
+	if cfg.TTS.Dictionary.ReloadInterval == 0 {
+		cfg.TTS.Dictionary.ReloadInterval = 10
+	}
+
+	// 请求脚本默认值
+	if cfg.TTS.Scripts.ReloadInterval == 0 {
+		cfg.TTS.Scripts.ReloadInterval = 10
+	}
+	if cfg.TTS.Scripts.TimeoutMs == 0 {
+		cfg.TTS.Scripts.TimeoutMs = 50
+	}
+	if cfg.TTS.Scripts.MaxCallDepth == 0 {
+		cfg.TTS.Scripts.MaxCallDepth = 200
+	}
+	if cfg.TTS.Scripts.OnError == "" {
+		cfg.TTS.Scripts.OnError = "skip"
+	}
+
+	// SSML 策略默认值
+	if cfg.SSML.MaxBreakMs == 0 {
+		cfg.SSML.MaxBreakMs = 5000
+	}
+	if cfg.SSML.MaxVoiceSwitches == 0 {
+		cfg.SSML.MaxVoiceSwitches = 50
+	}
+
+	// 语音列表默认值：晓晓优先，然后是中文语音，最后按本地名称排序
+	if len(cfg.TTS.VoiceList.Sort) == 0 {
+		cfg.TTS.VoiceList.Sort = []string{"preferred", "local_name"}
+	}
+	if len(cfg.TTS.VoiceList.PreferredVoices) == 0 {
+		cfg.TTS.VoiceList.PreferredVoices = []string{"*xiaoxiao*"}
+	}
+	if len(cfg.TTS.VoiceList.PreferredLocales) == 0 {
+		cfg.TTS.VoiceList.PreferredLocales = []string{"zh"}
+	}
+
+	// 语音能力检查默认值
+	if cfg.TTS.VoiceCheck.Mode == "" {
+		cfg.TTS.VoiceCheck.Mode = "fallback"
+	}
 
 	// 日志默认值
 	if cfg.Log.Level == "" {
@@ -219,6 +453,12 @@ func validate(cfg *Config) error {
 	if cfg.TTS.MaxTextLength < 1 {
 		return fmt.Errorf("max_text_length 必须大于 0")
 	}
+	if _, err := prosody.ParseRate(cfg.TTS.DefaultRate); err != nil {
+		return fmt.Errorf("无效的 default_rate: %v", err)
+	}
+	if _, err := prosody.ParsePitch(cfg.TTS.DefaultPitch); err != nil {
+		return fmt.Errorf("无效的 default_pitch: %v", err)
+	}
 	if cfg.TTS.RequestTimeout < 1 {
 		return fmt.Errorf("request_timeout 必须大于 0")
 	}
@@ -228,6 +468,9 @@ func validate(cfg *Config) error {
 	if cfg.TTS.MaxConcurrent > 100 {
 		return fmt.Errorf("max_concurrent 不能超过 100")
 	}
+	if cfg.TTS.QueueTimeout < 0 {
+		return fmt.Errorf("queue_timeout 不能为负数")
+	}
 
 	// 长文本处理验证
 	if cfg.TTS.LongText.Enabled {
@@ -243,6 +486,111 @@ func validate(cfg *Config) error {
 		if cfg.TTS.LongText.MinTextForSplit < cfg.TTS.LongText.MaxSegmentLength {
 			return fmt.Errorf("min_text_for_split 应大于等于 max_segment_length")
 		}
+		if cfg.TTS.LongText.TargetLatencyMs < 0 {
+			return fmt.Errorf("target_latency_ms 不能为负数")
+		}
+		if cfg.TTS.LongText.SegmentRetries < 0 || cfg.TTS.LongText.SegmentRetries > 10 {
+			return fmt.Errorf("segment_retries 必须在 0 到 10 之间")
+		}
+		if cfg.TTS.LongText.RetryBackoffMs < 0 {
+			return fmt.Errorf("retry_backoff_ms 不能为负数")
+		}
+		if cfg.TTS.LongText.MaxSpillMB < 0 {
+			return fmt.Errorf("max_spill_mb 不能为负数")
+		}
+		switch cfg.TTS.LongText.SegmentStrategy {
+		case "", "smart", "fixed", "paragraph", "line":
+		default:
+			return fmt.Errorf("无效的分段策略: %s", cfg.TTS.LongText.SegmentStrategy)
+		}
+		switch cfg.TTS.LongText.SegmentBudget {
+		case "", "runes", "ssml_bytes":
+		default:
+			return fmt.Errorf("无效的分段长度单位: %s", cfg.TTS.LongText.SegmentBudget)
+		}
+	}
+
+	// 多角色剧本验证
+	if *cfg.TTS.Dialogue.LineGapMs < 0 || *cfg.TTS.Dialogue.LineGapMs > 10000 {
+		return fmt.Errorf("dialogue.line_gap_ms 必须在 0 到 10000 之间")
+	}
+	for name, member := range cfg.TTS.Dialogue.Cast {
+		if member.Voice == "" {
+			return fmt.Errorf("角色 %s 未配置语音", name)
+		}
+	}
+
+	// 自动语言检测验证
+	if cfg.TTS.Language.MinRunLength < 1 {
+		return fmt.Errorf("language.min_run_length 必须大于 0")
+	}
+
+	// 文本规范化验证（规则名称在创建规范化器时校验）
+	if cfg.TTS.Normalize.Mode != "rewrite" && cfg.TTS.Normalize.Mode != "say_as" {
+		return fmt.Errorf("无效的规范化模式: %s", cfg.TTS.Normalize.Mode)
+	}
+
+	// Markdown 验证
+	switch cfg.TTS.Markdown.Mode {
+	case "markdown", "plain", "off":
+	default:
+		return fmt.Errorf("无效的 Markdown 处理方式: %s", cfg.TTS.Markdown.Mode)
+	}
+	if cfg.TTS.Markdown.Code != "skip" && cfg.TTS.Markdown.Code != "announce" {
+		return fmt.Errorf("无效的代码块处理方式: %s", cfg.TTS.Markdown.Code)
+	}
+	for name, ms := range map[string]int{
+		"heading_pause_ms":   *cfg.TTS.Markdown.HeadingPauseMs,
+		"paragraph_pause_ms": *cfg.TTS.Markdown.ParagraphPauseMs,
+		"list_pause_ms":      *cfg.TTS.Markdown.ListPauseMs,
+	} {
+		if ms < 0 || ms > 20000 {
+			return fmt.Errorf("markdown.%s 必须在 0 到 20000 之间", name)
+		}
+	}
+
+	// 发音词典验证
+	if cfg.TTS.Dictionary.ReloadInterval < 1 {
+		return fmt.Errorf("dictionary.reload_interval 必须大于 0")
+	}
+
+	// 请求脚本验证
+	if cfg.TTS.Scripts.ReloadInterval < 1 {
+		return fmt.Errorf("scripts.reload_interval 必须大于 0")
+	}
+	if cfg.TTS.Scripts.TimeoutMs < 1 || cfg.TTS.Scripts.TimeoutMs > 10000 {
+		return fmt.Errorf("scripts.timeout_ms 必须在 1 到 10000 之间")
+	}
+	if cfg.TTS.Scripts.MaxCallDepth < 10 {
+		return fmt.Errorf("scripts.max_call_depth 不能小于 10")
+	}
+	if cfg.TTS.Scripts.OnError != "skip" && cfg.TTS.Scripts.OnError != "reject" {
+		return fmt.Errorf("无效的脚本出错处理方式: %s", cfg.TTS.Scripts.OnError)
+	}
+
+	// SSML 策略验证
+	if cfg.SSML.MaxBreakMs < 0 {
+		return fmt.Errorf("ssml.max_break_ms 不能小于 0")
+	}
+	if cfg.SSML.MaxVoiceSwitches < 0 {
+		return fmt.Errorf("ssml.max_voice_switches 不能小于 0")
+	}
+	// Markdown 生成的停顿同样按策略检查，超过上限的停顿会让所有 Markdown 请求失败
+	for name, ms := range map[string]int{
+		"heading_pause_ms":   *cfg.TTS.Markdown.HeadingPauseMs,
+		"paragraph_pause_ms": *cfg.TTS.Markdown.ParagraphPauseMs,
+		"list_pause_ms":      *cfg.TTS.Markdown.ListPauseMs,
+	} {
+		if cfg.SSML.MaxBreakMs > 0 && ms > cfg.SSML.MaxBreakMs {
+			return fmt.Errorf("markdown.%s 不能超过 ssml.max_break_ms (%d)", name, cfg.SSML.MaxBreakMs)
+		}
+	}
+
+	// 语音能力检查验证
+	switch cfg.TTS.VoiceCheck.Mode {
+	case "off", "fallback", "strict":
+	default:
+		return fmt.Errorf("无效的语音检查模式: %s", cfg.TTS.VoiceCheck.Mode)
 	}
 
 	// 日志级别验证
@@ -281,10 +629,12 @@ func Get() *Config {
 	return &config
 }
 
-// SSMLConfig 存储SSML标签配置
+// SSMLConfig 客户端提交的 SSML 的安全策略，API 密钥不区分大小写
 type SSMLConfig struct {
-	// 此结构体现在为空，因为新的处理器会处理所有标签。
-	// 保留它是为了与配置结构兼容。
+	Elements         map[string][]string `mapstructure:"elements"`           // 允许的元素 -> 允许的属性（不含命名空间前缀），为空时使用内置列表
+	MaxBreakMs       int                 `mapstructure:"max_break_ms"`       // <break> 和 <mstts:silence> 的最长时长（毫秒，默认 5000）
+	MaxVoiceSwitches int                 `mapstructure:"max_voice_switches"` // 一个文档中 <voice> 元素的最大数量（默认 50）
+	Voices           map[string][]string `mapstructure:"voices"`             // API 密钥 -> 允许的语音（支持 * 通配符），未配置的密钥不限制
 }
 
 // SSMLProcessor 处理SSML内容
@@ -326,7 +676,10 @@ func (p *SSMLProcessor) EscapeSSML(ssml string) string {
 		case xml.StartElement:
 			builder.WriteString("<" + t.Name.Local)
 			for _, attr := range t.Attr {
-				builder.WriteString(fmt.Sprintf(` %s="%s"`, attr.Name.Local, attr.Value))
+				// 属性值同样需要转义，否则值中的引号会闭合属性，注入新的属性或元素
+				var escapedValue bytes.Buffer
+				_ = xml.EscapeText(&escapedValue, []byte(attr.Value))
+				builder.WriteString(fmt.Sprintf(` %s="%s"`, attr.Name.Local, escapedValue.String()))
 			}
 			builder.WriteString(">")
 		case xml.EndElement:
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// loadYAML 按 Load 的方式解析配置并填充默认值
+func loadYAML(t *testing.T, content string) *Config {
+	t.Helper()
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(strings.NewReader(content)); err != nil {
+		t.Fatal(err)
+	}
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	setDefaults(&cfg)
+	return &cfg
+}
+
+// TestSetDefaults_ExplicitZero 测试显式配置为 0 的值不会被默认值覆盖
+func TestSetDefaults_ExplicitZero(t *testing.T) {
+	if got := *loadYAML(t, "tts:\n  dialogue:\n    line_gap_ms: 0\n").TTS.Dialogue.LineGapMs; got != 0 {
+		t.Errorf("line_gap_ms = %d, want 0", got)
+	}
+	if got := *loadYAML(t, "tts: {}\n").TTS.Dialogue.LineGapMs; got != 400 {
+		t.Errorf("未配置时 line_gap_ms = %d, want 400", got)
+	}
+
+	markdown := loadYAML(t, "tts:\n  markdown:\n    heading_pause_ms: 0\n    list_pause_ms: 0\n").TTS.Markdown
+	if *markdown.HeadingPauseMs != 0 || *markdown.ListPauseMs != 0 || *markdown.ParagraphPauseMs != 400 {
+		t.Errorf("markdown 停顿 = %d/%d/%d, want 0/400/0",
+			*markdown.HeadingPauseMs, *markdown.ParagraphPauseMs, *markdown.ListPauseMs)
+	}
+}
+
+// TestEscapeSSML 测试文本节点和属性值都被转义，属性值中的引号不能注入新的属性
+func TestEscapeSSML(t *testing.T) {
+	p, _ := NewSSMLProcessor(&SSMLConfig{})
+	tests := []struct {
+		in, want string
+	}{
+		{"AT&amp;T", "AT&amp;T"},
+		{`<break time="500ms"/>你好`, `<break time="500ms"></break>你好`},
+		{`<voice name='a" onload="x'>你好</voice>`, `<voice name="a&#34; onload=&#34;x">你好</voice>`},
+		{`<sub alias='&lt;b&gt;'>B</sub>`, `<sub alias="&lt;b&gt;">B</sub>`},
+	}
+	for _, tt := range tests {
+		if got := p.EscapeSSML(tt.in); got != tt.want {
+			t.Errorf("EscapeSSML(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestValidate_MarkdownPause 测试 Markdown 停顿不能超过 SSML 策略的最长停顿
+func TestValidate_MarkdownPause(t *testing.T) {
+	if err := validate(loadYAML(t, "tts:\n  markdown:\n    heading_pause_ms: 5000\n")); err != nil {
+		t.Errorf("validate() error = %v", err)
+	}
+	err := validate(loadYAML(t, "tts:\n  markdown:\n    heading_pause_ms: 6000\nssml:\n  max_break_ms: 3000\n"))
+	if err == nil || !strings.Contains(err.Error(), "max_break_ms") {
+		t.Errorf("validate() error = %v, want max_break_ms error", err)
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	custom_errors "tts/internal/errors"
+	"tts/internal/http/middleware"
+	"tts/internal/textproc"
+)
+
+// 词典范围：global 为全局词条，key 为当前请求通过验证的 API 密钥的词条
+const (
+	dictionaryScopeGlobal = "global"
+	dictionaryScopeKey    = "key"
+)
+
+// dictionaryRequest 添加或更新词条的请求
+type dictionaryRequest struct {
+	Scope   string           `json:"scope"`
+	Entries []textproc.Entry `json:"entries"`
+}
+
+// DictionaryHandler 处理发音词典的管理请求
+type DictionaryHandler struct {
+	store *textproc.DictionaryStore
+}
+
+// NewDictionaryHandler 创建一个新的发音词典处理器
+func NewDictionaryHandler(store *textproc.DictionaryStore) *DictionaryHandler {
+	return &DictionaryHandler{
+		store: store,
+	}
+}
+
+// HandleList 返回全局或当前 API 密钥的词条
+func (h *DictionaryHandler) HandleList(c *gin.Context) {
+	scope, apiKey, err := dictionaryScope(c, c.Query("scope"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	entries := h.store.Entries(apiKey)
+	c.JSON(http.StatusOK, gin.H{
+		"scope":   scope,
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// HandlePut 添加或更新词条，同名词条被覆盖
+func (h *DictionaryHandler) HandlePut(c *gin.Context) {
+	var req dictionaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(fmt.Errorf("%w: 无效的JSON请求: %v", custom_errors.ErrInvalidInput, err))
+		return
+	}
+	if len(req.Entries) == 0 {
+		_ = c.Error(fmt.Errorf("%w: entries 不能为空", custom_errors.ErrInvalidInput))
+		return
+	}
+	scope, apiKey, err := dictionaryScope(c, req.Scope)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := h.store.Put(apiKey, req.Entries); err != nil {
+		if errors.Is(err, textproc.ErrInvalidDictionary) {
+			_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+			return
+		}
+		_ = c.Error(fmt.Errorf("%w: 保存发音词典失败: %v", custom_errors.ErrInternalServer, err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"scope": scope,
+		"count": len(h.store.Entries(apiKey)),
+	})
+}
+
+// HandleDelete 删除词条
+func (h *DictionaryHandler) HandleDelete(c *gin.Context) {
+	term := strings.TrimSpace(c.Query("term"))
+	if term == "" {
+		_ = c.Error(fmt.Errorf("%w: 必须提供 term 参数", custom_errors.ErrInvalidInput))
+		return
+	}
+	scope, apiKey, err := dictionaryScope(c, c.Query("scope"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	deleted, err := h.store.Delete(apiKey, term)
+	if err != nil {
+		_ = c.Error(fmt.Errorf("%w: 保存发音词典失败: %v", custom_errors.ErrInternalServer, err))
+		return
+	}
+	if !deleted {
+		_ = c.Error(fmt.Errorf("%w: 词条不存在: %s", custom_errors.ErrNotFound, term))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"scope": scope, "deleted": term})
+}
+
+// HandleReload 立即重新加载词典文件
+func (h *DictionaryHandler) HandleReload(c *gin.Context) {
+	reloaded, err := h.store.Reload()
+	if err != nil {
+		_ = c.Error(fmt.Errorf("%w: 加载发音词典失败: %v", custom_errors.ErrInternalServer, err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"reloaded": reloaded,
+		"count":    len(h.store.Entries("")),
+	})
+}
+
+// dictionaryScope 解析词典范围，返回范围名称和对应的 API 密钥（全局为空）
+func dictionaryScope(c *gin.Context, scope string) (string, string, error) {
+	switch scope {
+	case "", dictionaryScopeGlobal:
+		return dictionaryScopeGlobal, "", nil
+	case dictionaryScopeKey:
+		apiKey := middleware.APIKey(c)
+		if apiKey == "" {
+			return "", "", fmt.Errorf("%w: scope=key 需要通过验证的 API 密钥", custom_errors.ErrInvalidInput)
+		}
+		return dictionaryScopeKey, apiKey, nil
+	}
+	return "", "", fmt.Errorf("%w: 无效的 scope: %s", custom_errors.ErrInvalidInput, scope)
+}
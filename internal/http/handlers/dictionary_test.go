@@ -0,0 +1,82 @@
+package handlers_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tts/internal/config"
+)
+
+// TestDictionary_RequireAPIKey 测试未配置 API 密钥时不能修改或重新加载词典，也不能使用 scope=key
+func TestDictionary_RequireAPIKey(t *testing.T) {
+	router, _ := newTestServer(t, func(cfg *config.Config) {
+		cfg.TTS.ApiKey = ""
+	})
+	tests := []struct {
+		method, target, body string
+		want                 int
+	}{
+		{http.MethodPost, "/api/dictionary", `{"scope": "global", "entries": [{"term": "行长", "pinyin": "hang2 zhang3"}]}`, http.StatusForbidden},
+		{http.MethodDelete, "/api/dictionary?term=行长", "", http.StatusForbidden},
+		{http.MethodPost, "/api/dictionary/reload", "", http.StatusForbidden},
+		{http.MethodGet, "/api/dictionary?scope=key&api_key=team-a", "", http.StatusBadRequest},
+		{http.MethodGet, "/api/dictionary", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if w := serve(router, tt.method, tt.target, tt.body); w.Code != tt.want {
+			t.Errorf("%s %s = %d %s, want %d", tt.method, tt.target, w.Code, w.Body.String(), tt.want)
+		}
+	}
+}
+
+// TestDictionary_KeyScope 测试按密钥的词条只属于通过验证的密钥，合成时按该密钥选择词典
+func TestDictionary_KeyScope(t *testing.T) {
+	router, service := newTestServer(t, func(cfg *config.Config) {
+		cfg.TTS.ApiKey = "Team-A"
+	})
+	put := `{"scope": "key", "entries": [{"term": "重庆", "sub": "崇庆"}]}`
+	if w := serve(router, http.MethodPost, "/api/dictionary?api_key=Team-A", put); w.Code != http.StatusOK {
+		t.Fatalf("POST = %d %s", w.Code, w.Body.String())
+	}
+	if w := serve(router, http.MethodPost, "/api/dictionary?api_key=team-b", put); w.Code != http.StatusUnauthorized {
+		t.Errorf("未通过验证的密钥 = %d, want 401", w.Code)
+	}
+	if w := serve(router, http.MethodGet, "/api/dictionary?scope=key&api_key=Team-A", ""); !strings.Contains(w.Body.String(), `"count":1`) {
+		t.Errorf("GET scope=key = %s", w.Body.String())
+	}
+	if w := serve(router, http.MethodGet, "/api/dictionary?scope=global&api_key=Team-A", ""); !strings.Contains(w.Body.String(), `"count":0`) {
+		t.Errorf("GET scope=global = %s", w.Body.String())
+	}
+	if w := serve(router, http.MethodPost, "/api/dictionary/reload?api_key=Team-A", ""); w.Code != http.StatusOK {
+		t.Errorf("POST reload = %d %s", w.Code, w.Body.String())
+	}
+
+	if w := serve(router, http.MethodPost, "/api/tts?api_key=Team-A", `{"text": "重庆"}`); w.Code != http.StatusOK {
+		t.Fatalf("POST /api/tts = %d %s", w.Code, w.Body.String())
+	}
+	if documents := service.documents(); len(documents) != 1 || !strings.Contains(documents[0], `<sub alias="崇庆">`) {
+		t.Errorf("应使用密钥的词条: %q", documents)
+	}
+}
+
+// TestDictionary_UnauthenticatedKey 测试未配置验证时客户端声明的密钥不能选择按密钥的词条
+func TestDictionary_UnauthenticatedKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dictionary.yaml")
+	content := "global: []\nkeys:\n  team-a:\n    - term: 重庆\n      sub: 崇庆\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	router, service := newTestServer(t, func(cfg *config.Config) {
+		cfg.TTS.ApiKey = ""
+		cfg.TTS.Dictionary.Path = path
+	})
+	if w := serve(router, http.MethodPost, "/api/tts?api_key=team-a", `{"text": "重庆"}`); w.Code != http.StatusOK {
+		t.Fatalf("POST /api/tts = %d %s", w.Code, w.Body.String())
+	}
+	if documents := service.documents(); len(documents) != 1 || strings.Contains(documents[0], "<sub") {
+		t.Errorf("未经验证的密钥不应选择词条: %q", documents)
+	}
+}
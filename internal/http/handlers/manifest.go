@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	custom_errors "tts/internal/errors"
+	"tts/internal/models"
+)
+
+const (
+	// manifestTTL 时间清单在内存中保留的时长
+	manifestTTL = 10 * time.Minute
+	// maxManifests 内存中最多保留的时间清单数，超出时丢弃最早的
+	maxManifests = 1000
+	// maxManifestHeader X-TTS-Manifest 响应头的长度上限（字节），超出时只能通过清单接口获取
+	maxManifestHeader = 4096
+)
+
+// storedManifest 保存的时间清单
+type storedManifest struct {
+	lines   []models.LineTiming
+	expires time.Time
+}
+
+// ManifestStore 在内存中短期保存剧本和对白合成的时间清单，供 /api/manifest/:id 查询
+type ManifestStore struct {
+	mu      sync.Mutex
+	entries map[string]storedManifest
+	order   []string // 按保存顺序排列的 ID
+}
+
+// NewManifestStore 创建时间清单存储
+func NewManifestStore() *ManifestStore {
+	return &ManifestStore{entries: make(map[string]storedManifest)}
+}
+
+// Put 保存时间清单，返回清单 ID
+func (s *ManifestStore) Put(lines []models.LineTiming) string {
+	id := uuid.NewString()
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 清理过期和超出数量上限的清单（order 按保存时间排列）
+	for len(s.order) > 0 {
+		oldest := s.order[0]
+		if len(s.order) < maxManifests && now.Before(s.entries[oldest].expires) {
+			break
+		}
+		delete(s.entries, oldest)
+		s.order = s.order[1:]
+	}
+	s.entries[id] = storedManifest{lines: lines, expires: now.Add(manifestTTL)}
+	s.order = append(s.order, id)
+	return id
+}
+
+// Get 返回未过期的时间清单
+func (s *ManifestStore) Get(id string) ([]models.LineTiming, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.entries[id]
+	if !ok || time.Now().After(m.expires) {
+		return nil, false
+	}
+	return m.lines, true
+}
+
+// HandleManifest 返回剧本和对白合成的完整时间清单（包含每行的文本）
+func (h *TTSHandler) HandleManifest(c *gin.Context) {
+	lines, ok := h.manifests.Get(c.Param("id"))
+	if !ok {
+		_ = c.Error(fmt.Errorf("%w: 时间清单不存在或已过期", custom_errors.ErrNotFound))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"lines": lines})
+}
+
+// setManifestHeaders 保存时间清单并设置响应头：X-TTS-Manifest-ID 用于查询完整清单，
+// 不含文本的清单不超过长度上限时以 base64 编码的 JSON 放在 X-TTS-Manifest 中
+func (h *TTSHandler) setManifestHeaders(c *gin.Context, lines []models.LineTiming) error {
+	c.Header("X-TTS-Manifest-ID", h.manifests.Put(lines))
+
+	compact := make([]models.LineTiming, len(lines))
+	for i, line := range lines {
+		line.Text = ""
+		compact[i] = line
+	}
+	manifest, err := json.Marshal(compact)
+	if err != nil {
+		return err
+	}
+	if encoded := base64.StdEncoding.EncodeToString(manifest); len(encoded) <= maxManifestHeader {
+		c.Header("X-TTS-Manifest", encoded)
+	}
+	return nil
+}
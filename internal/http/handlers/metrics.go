@@ -42,11 +42,21 @@ func (h *MetricsHandler) GetMetrics(c *gin.Context) {
 			"misses":     snapshot.CacheMisses,
 			"hit_rate":   snapshot.CacheHitRate,
 			"total_size": snapshot.CacheTotalSize,
+			"segments": gin.H{
+				"hits":     snapshot.SegmentHits,
+				"misses":   snapshot.SegmentMisses,
+				"hit_rate": snapshot.SegmentHitRate,
+			},
 		},
 		"worker_pool": gin.H{
 			"total_jobs": snapshot.WorkerPoolJobs,
 			"errors":     snapshot.WorkerPoolErrors,
 		},
+		"upstream": gin.H{
+			"in_flight":      snapshot.UpstreamInFlight,
+			"queued":         snapshot.UpstreamQueued,
+			"queue_timeouts": snapshot.UpstreamTimeouts,
+		},
 		"system": gin.H{
 			"memory": gin.H{
 				"alloc_mb":       memStats.Alloc / 1024 / 1024,
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"tts/internal/config"
+	custom_errors "tts/internal/errors"
+	"tts/internal/http/middleware"
+	"tts/internal/lang"
+	"tts/internal/models"
+	"tts/internal/ssml"
+	"tts/internal/textproc"
+)
+
+// normalizeRequest 规范化调试请求
+type normalizeRequest struct {
+	Text   string `json:"text" form:"text"`
+	Voice  string `json:"voice" form:"voice"`   // 按语音确定语言
+	Locale string `json:"locale" form:"locale"` // 直接指定语言，优先于 voice
+	Mode   string `json:"mode" form:"mode"`     // off、rewrite 或 say_as，为空时与合成相同使用配置的模式
+}
+
+// replaceRequest 替换规则测试请求，指定 rules 时测试这组规则，否则测试 rule_set 选中的规则集
+type replaceRequest struct {
+	Text    string                 `json:"text"`
+	Voice   string                 `json:"voice"`
+	Locale  string                 `json:"locale"`
+	RuleSet string                 `json:"rule_set"`
+	Rules   []textproc.ReplaceRule `json:"rules"`
+}
+
+// convertRequest 简繁转换测试请求
+type convertRequest struct {
+	Text    string `json:"text"`
+	Voice   string `json:"voice"`
+	Locale  string `json:"locale"`
+	Convert string `json:"convert"` // 转换方式，为空时按语言自动选择
+}
+
+// TextHandler 处理文本预处理相关的调试请求
+type TextHandler struct {
+	normalizer *textproc.Normalizer
+	replacer   *textproc.Replacer
+	chinese    *textproc.ChineseConverter
+	config     *config.Config
+}
+
+// NewTextHandler 创建一个新的文本处理器
+func NewTextHandler(normalizer *textproc.Normalizer, replacer *textproc.Replacer, chinese *textproc.ChineseConverter, cfg *config.Config) *TextHandler {
+	return &TextHandler{
+		normalizer: normalizer,
+		replacer:   replacer,
+		chinese:    chinese,
+		config:     cfg,
+	}
+}
+
+// defaultNormalizeMode 请求未指定规范化模式时使用的模式，未启用规范化时为 off。合成和调试接口共用
+func defaultNormalizeMode(cfg *config.Config, normalizer *textproc.Normalizer) textproc.Mode {
+	if !cfg.TTS.Normalize.Enabled {
+		return textproc.ModeOff
+	}
+	return normalizer.Mode()
+}
+
+// checkText 检查调试请求的文本非空且不超过 tts.max_text_length
+func (h *TextHandler) checkText(c *gin.Context, text string) bool {
+	if text == "" {
+		_ = c.Error(fmt.Errorf("%w: 必须提供 text 参数", custom_errors.ErrInvalidInput))
+		return false
+	}
+	if utf8.RuneCountInString(text) > h.config.TTS.MaxTextLength {
+		_ = c.Error(fmt.Errorf("%w: 文本长度超过 %d 字符的限制", custom_errors.ErrInvalidInput, h.config.TTS.MaxTextLength))
+		return false
+	}
+	return true
+}
+
+// HandleNormalize 返回规范化后的文本和每处改写，便于调试读法
+func (h *TextHandler) HandleNormalize(c *gin.Context) {
+	var req normalizeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		_ = c.Error(fmt.Errorf("%w: 无效的请求: %v", custom_errors.ErrInvalidInput, err))
+		return
+	}
+	if !h.checkText(c, req.Text) {
+		return
+	}
+
+	if req.Voice == "" {
+		req.Voice = h.config.TTS.DefaultVoice
+	}
+	locale := req.Locale
+	if locale == "" {
+		locale = lang.LocaleOf(req.Voice)
+	}
+	mode, err := textproc.ParseMode(req.Mode, defaultNormalizeMode(h.config, h.normalizer))
+	if err != nil {
+		_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+		return
+	}
+
+	result := h.normalizer.Normalize(req.Text, locale, mode)
+	resp := gin.H{
+		"locale":  locale,
+		"mode":    mode,
+		"rules":   h.normalizer.Rules(),
+		"text":    result.Text,
+		"changes": result.Changes,
+	}
+	// say_as 模式下返回实际发送给上游的 SSML
+	if result.Spans != nil {
+		compiled, err := ssml.Compile(models.TTSRequest{
+			Voice: req.Voice,
+			Rate:  h.config.TTS.DefaultRate,
+			Pitch: h.config.TTS.DefaultPitch,
+			Spans: result.Spans,
+		})
+		if err != nil {
+			_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+			return
+		}
+		resp["ssml"] = compiled
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// HandleReplace 对示例文本执行替换规则，返回替换结果和每条规则的替换次数
+func (h *TextHandler) HandleReplace(c *gin.Context) {
+	var req replaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(fmt.Errorf("%w: 无效的JSON请求: %v", custom_errors.ErrInvalidInput, err))
+		return
+	}
+	if !h.checkText(c, req.Text) {
+		return
+	}
+
+	var set *textproc.RuleSet
+	var err error
+	if len(req.Rules) > 0 {
+		set, err = textproc.NewRuleSet("request", req.Rules)
+	} else {
+		set, err = h.replacer.Select(req.RuleSet, middleware.APIKey(c))
+	}
+	if err != nil {
+		_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+		return
+	}
+
+	locale := req.Locale
+	if locale == "" {
+		if req.Voice == "" {
+			req.Voice = h.config.TTS.DefaultVoice
+		}
+		locale = lang.LocaleOf(req.Voice)
+	}
+	resp := gin.H{"locale": locale, "text": req.Text, "hits": []textproc.RuleHit{}}
+	if set != nil {
+		text, hits := set.Apply(req.Text, locale)
+		resp["rule_set"] = set.Name()
+		resp["text"] = text
+		if hits != nil {
+			resp["hits"] = hits
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// HandleRuleSets 返回已配置的规则集和当前请求通过验证的 API 密钥默认使用的规则集
+func (h *TextHandler) HandleRuleSets(c *gin.Context) {
+	selected := ""
+	if set, _ := h.replacer.Select("", middleware.APIKey(c)); set != nil {
+		selected = set.Name()
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"rule_sets": h.replacer.Names(),
+		"selected":  selected,
+	})
+}
+
+// HandleConvert 对示例文本执行简繁转换，返回选中的转换方式和转换结果
+func (h *TextHandler) HandleConvert(c *gin.Context) {
+	var req convertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(fmt.Errorf("%w: 无效的JSON请求: %v", custom_errors.ErrInvalidInput, err))
+		return
+	}
+	if !h.checkText(c, req.Text) {
+		return
+	}
+
+	locale := req.Locale
+	if locale == "" {
+		if req.Voice == "" {
+			req.Voice = h.config.TTS.DefaultVoice
+		}
+		locale = lang.LocaleOf(req.Voice)
+	}
+	requested := req.Convert
+	if requested == "" {
+		requested = textproc.ConversionAuto
+	}
+	conv, err := h.chinese.Select(requested, locale)
+	if err != nil {
+		_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+		return
+	}
+
+	resp := gin.H{"locale": locale, "conversion": textproc.ConversionNone, "text": req.Text, "conversions": h.chinese.Names()}
+	if conv != nil {
+		resp["conversion"] = conv.Name()
+		resp["text"] = conv.Convert(req.Text)
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,83 @@
+package handlers_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"tts/internal/config"
+)
+
+// TestReplace_AuthenticatedKey 测试按密钥的规则集只按通过验证的密钥选择
+func TestReplace_AuthenticatedKey(t *testing.T) {
+	configure := func(apiKey string) func(cfg *config.Config) {
+		return func(cfg *config.Config) {
+			cfg.TTS.ApiKey = apiKey
+			cfg.TTS.Replace = config.ReplaceConfig{
+				Keys:     map[string]string{"team-a": "team"},
+				RuleSets: map[string][]config.ReplaceRuleConfig{"team": {{Pattern: "foo", Replacement: "bar"}}},
+			}
+		}
+	}
+
+	router, service := newTestServer(t, configure("team-a"))
+	if w := serve(router, http.MethodPost, "/api/tts?api_key=team-a", `{"text": "foo"}`); w.Code != http.StatusOK {
+		t.Fatalf("POST = %d %s", w.Code, w.Body.String())
+	}
+	if documents := service.documents(); len(documents) != 1 || !strings.Contains(documents[0], "bar") {
+		t.Errorf("通过验证的密钥应使用对应的规则集: %q", documents)
+	}
+	if w := serve(router, http.MethodGet, "/api/text/rule-sets?api_key=team-a", ""); !strings.Contains(w.Body.String(), `"selected":"team"`) {
+		t.Errorf("GET rule-sets = %s", w.Body.String())
+	}
+
+	router, service = newTestServer(t, configure(""))
+	if w := serve(router, http.MethodPost, "/api/tts?api_key=team-a", `{"text": "foo"}`); w.Code != http.StatusOK {
+		t.Fatalf("POST = %d %s", w.Code, w.Body.String())
+	}
+	if documents := service.documents(); len(documents) != 1 || !strings.Contains(documents[0], "foo") {
+		t.Errorf("未经验证的密钥不应选择规则集: %q", documents)
+	}
+	if w := serve(router, http.MethodPost, "/api/text/replace?api_key=team-a", `{"text": "foo"}`); !strings.Contains(w.Body.String(), `"text":"foo"`) {
+		t.Errorf("POST /api/text/replace = %s", w.Body.String())
+	}
+}
+
+// TestNormalize_DefaultMode 测试调试接口与合成使用相同的默认模式，未启用规范化时不改写
+func TestNormalize_DefaultMode(t *testing.T) {
+	tests := []struct {
+		enabled bool
+		want    string
+	}{
+		{false, `"mode":"off"`},
+		{true, `"mode":"rewrite"`},
+	}
+	for _, tt := range tests {
+		router, _ := newTestServer(t, func(cfg *config.Config) {
+			cfg.TTS.Normalize.Enabled = tt.enabled
+			cfg.TTS.Normalize.Mode = "rewrite"
+		})
+		w := serve(router, http.MethodPost, "/api/text/normalize", `{"text": "2024-01-02"}`)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("enabled=%v: POST = %d %s, want %s", tt.enabled, w.Code, w.Body.String(), tt.want)
+		}
+		if !tt.enabled && !strings.Contains(w.Body.String(), `"text":"2024-01-02"`) {
+			t.Errorf("未启用规范化时不应改写: %s", w.Body.String())
+		}
+	}
+}
+
+// TestText_MaxLength 测试文本调试接口检查 tts.max_text_length
+func TestText_MaxLength(t *testing.T) {
+	router, _ := newTestServer(t, func(cfg *config.Config) {
+		cfg.TTS.MaxTextLength = 5
+	})
+	for _, target := range []string{"/api/text/normalize", "/api/text/replace", "/api/text/convert"} {
+		if w := serve(router, http.MethodPost, target, `{"text": "你好世界你好世界"}`); w.Code != http.StatusBadRequest {
+			t.Errorf("POST %s = %d %s, want 400", target, w.Code, w.Body.String())
+		}
+		if w := serve(router, http.MethodPost, target, `{"text": "你好"}`); w.Code != http.StatusOK {
+			t.Errorf("POST %s = %d %s, want 200", target, w.Code, w.Body.String())
+		}
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -15,10 +18,19 @@ import (
 	
 	"tts/internal/config"
 	custom_errors "tts/internal/errors"
+	"tts/internal/http/middleware"
+	"tts/internal/lang"
 	"tts/internal/metrics"
 	"tts/internal/models"
+	"tts/internal/prosody"
+	"tts/internal/scripting"
+	"tts/internal/ssml"
+	"tts/internal/textproc"
 	"tts/internal/tts"
+	"tts/internal/tts/audio"
+	"tts/internal/tts/limiter"
 	"tts/internal/utils"
+	"tts/internal/voices"
 )
 
 // UpstreamErrorType 上游错误类型
@@ -112,15 +124,31 @@ func formatFileSize(size int) string {
 type TTSHandler struct {
 	ttsService     tts.Service
 	longTextService *tts.LongTextTTSService
+	normalizer     *textproc.Normalizer
+	replacer       *textproc.Replacer
+	dictionary     *textproc.DictionaryStore
+	chinese        *textproc.ChineseConverter
+	scripts        *scripting.Store
+	voiceChecker   *voices.Checker
+	ssmlPolicy     *ssml.Policy
+	manifests      *ManifestStore
 	config         *config.Config
 	logger         zerolog.Logger
 }
 
 // NewTTSHandler 创建一个新的TTS处理器
-func NewTTSHandler(service tts.Service, longTextService *tts.LongTextTTSService, cfg *config.Config, logger zerolog.Logger) *TTSHandler {
+func NewTTSHandler(service tts.Service, longTextService *tts.LongTextTTSService, normalizer *textproc.Normalizer, replacer *textproc.Replacer, dictionary *textproc.DictionaryStore, chinese *textproc.ChineseConverter, scripts *scripting.Store, voiceChecker *voices.Checker, ssmlPolicy *ssml.Policy, cfg *config.Config, logger zerolog.Logger) *TTSHandler {
 	return &TTSHandler{
 		ttsService:      service,
 		longTextService: longTextService,
+		normalizer:      normalizer,
+		replacer:        replacer,
+		dictionary:      dictionary,
+		chinese:         chinese,
+		scripts:         scripts,
+		voiceChecker:    voiceChecker,
+		ssmlPolicy:      ssmlPolicy,
+		manifests:       NewManifestStore(),
 		config:          cfg,
 		logger:          logger,
 	}
@@ -130,25 +158,148 @@ func NewTTSHandler(service tts.Service, longTextService *tts.LongTextTTSService,
 func (h *TTSHandler) processTTSRequest(c *gin.Context, req models.TTSRequest, startTime time.Time, parseTime time.Duration, requestType string) {
 	// 验证必要参数
 	logger := h.getLoggerWithTraceID(c)
-	if req.Text == "" && req.SSML == "" {
-		logger.Error().Msg("错误: 未提供 text 或 ssml 参数")
-		_ = c.Error(fmt.Errorf("%w: 必须提供 text 或 ssml 参数", custom_errors.ErrInvalidInput))
+	hasSpans := len(req.Spans) > 0
+	inputs := 0
+	for _, provided := range []bool{req.Text != "", req.SSML != "", hasSpans, req.Script != ""} {
+		if provided {
+			inputs++
+		}
+	}
+	if inputs == 0 {
+		logger.Error().Msg("错误: 未提供 text、ssml、spans 或 script 参数")
+		_ = c.Error(fmt.Errorf("%w: 必须提供 text、ssml、spans 或 script 参数", custom_errors.ErrInvalidInput))
 		return
 	}
 
-	if req.Text != "" && req.SSML != "" {
-		logger.Error().Msg("错误: 不能同时提供 text 和 ssml 参数")
-		_ = c.Error(fmt.Errorf("%w: 不能同时提供 text 和 ssml 参数", custom_errors.ErrInvalidInput))
+	if inputs > 1 {
+		logger.Error().Msg("错误: 不能同时提供 text、ssml、spans 和 script 参数")
+		_ = c.Error(fmt.Errorf("%w: text、ssml、spans 和 script 只能提供其中一个", custom_errors.ErrInvalidInput))
+		return
+	}
+
+	dialogueMode := req.DialogueVoice != "" || req.DialogueMaleVoice != "" || req.DialogueFemaleVoice != ""
+	if dialogueMode && req.Text == "" {
+		_ = c.Error(fmt.Errorf("%w: 对白语音仅适用于 text 输入", custom_errors.ErrInvalidInput))
 		return
 	}
 
 	// 使用默认值填充空白参数
 	h.fillDefaultValues(&req)
 
+	// 请求脚本可以修改文本和语音参数，或拒绝请求
+	if err := h.runScripts(c, &req, requestType); err != nil {
+		return
+	}
+
+	// 语速、语调、音量统一为 SSML 写法，超出范围的值截断并在响应头中说明
+	clamped, err := prosody.Normalize(&req)
+	if err != nil {
+		logger.Warn().Err(err).Msg("韵律参数无效")
+		_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+		return
+	}
+	if len(clamped) > 0 {
+		logger.Info().Strs("params", clamped).Msg("韵律参数超出范围，已截断")
+		c.Header("X-TTS-Prosody-Clamped", strings.Join(clamped, ","))
+	}
+
+	// 替换规则清理文本（广告、网址、分隔符等），在规范化和生成 SSML 之前执行
+	if err := h.applyReplaceRules(c, &req); err != nil {
+		logger.Warn().Err(err).Msg("替换规则执行失败")
+		_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+		return
+	}
+
+	// 对白、自动语言检测和按策略分段的请求需要保留纯文本，不能转为结构化片段
+	plainText := dialogueMode || req.AutoLanguage || h.config.TTS.Language.AutoDetect || req.SegmentStrategy != ""
+
+	// 开启行内指令时，[pause:800ms]、[voice:...]...[/voice] 等解析为结构化片段
+	structured, err := h.parseDirectives(&req, plainText)
+	if err != nil {
+		logger.Warn().Err(err).Msg("行内指令解析失败")
+		_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+		return
+	}
+
+	// Markdown：plain 模式删除符号，markdown 模式渲染为带停顿的结构化片段
+	rendered, err := h.renderMarkdown(&req, plainText, structured)
+	if err != nil {
+		_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+		return
+	}
+
+	// 文本规范化：数字、日期、时间等改写为读法，say_as 模式下可能转为结构化请求
+	if req.Text != "" || rendered {
+		if err := h.normalizeText(&req, plainText); err != nil {
+			_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+			return
+		}
+	}
+
+	// 简繁转换：按请求指定的方式或语音的语言转换，发音词典按转换后的文本匹配
+	if err := h.convertChinese(&req); err != nil {
+		_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+		return
+	}
+
+	// 发音词典按通过验证的 API 密钥选择：结构化片段直接标注，文本在生成 SSML 时标注
+	if dict := h.dictionary.For(middleware.APIKey(c)); dict.Len() > 0 {
+		req.Lexicon = dict
+		if len(req.Spans) > 0 {
+			req.Spans, _ = dict.Apply(req.Spans)
+		}
+	}
+
+	// 先检查 SSML 长度，超长的 SSML 不做策略检查
+	if utf8.RuneCountInString(req.SSML) > h.config.TTS.MaxTextLength {
+		_ = c.Error(fmt.Errorf("%w: 文本长度超过 %d 字符的限制", custom_errors.ErrInvalidInput, h.config.TTS.MaxTextLength))
+		return
+	}
+
+	// 客户端提交的 SSML 按安全策略检查，API 密钥只能使用允许的语音
+	if err := h.checkSSMLPolicy(c, &req); err != nil {
+		return
+	}
+
+	// 按语音列表检查语音、风格、角色和格式，避免上游返回难以理解的错误
+	if err := h.checkVoices(c, &req); err != nil {
+		return
+	}
+
+	// 结构化请求在服务端编译为 SSML，之后按 SSML 请求处理
+	if len(req.Spans) > 0 {
+		compiled, err := ssml.Compile(req)
+		if err != nil {
+			logger.Warn().Err(err).Msg("结构化请求编译失败")
+			_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+			return
+		}
+		req.SSML = compiled
+		req.Spans = nil
+		// 片段、行内指令和 Markdown 生成的停顿和语音切换同样受策略限制
+		if err := h.checkSSMLPolicy(c, &req); err != nil {
+			return
+		}
+	}
+
+	// 剧本和引号对白按 MP3 帧计算每行时长并生成行间静音，只支持 MP3 格式
+	if req.Script != "" || dialogueMode {
+		format := req.Format
+		if format == "" {
+			format = h.config.TTS.DefaultFormat
+		}
+		if !audio.IsMP3Format(format) {
+			_ = c.Error(fmt.Errorf("%w: 剧本和对白请求只支持 MP3 格式，不支持 %s", custom_errors.ErrInvalidInput, format))
+			return
+		}
+	}
+
 	var inputText string
 	isSSML := req.SSML != ""
 	if isSSML {
 		inputText = req.SSML
+	} else if req.Script != "" {
+		inputText = req.Script
 	} else {
 		inputText = req.Text
 	}
@@ -160,17 +311,27 @@ func (h *TTSHandler) processTTSRequest(c *gin.Context, req models.TTSRequest, st
 		return
 	}
 
-	// 检查是否需要分段处理 (SSML不支持分段)
+	// 检查是否需要分段处理（SSML 按元素和句子边界分段），显式指定分段策略的请求始终分段
+	// 多角色剧本逐行合成、引号对白分角色合成，同样交给长文本服务
 	segmentThreshold := h.config.TTS.SegmentThreshold
-	if !isSSML && reqTextLength > segmentThreshold && reqTextLength <= h.config.TTS.MaxTextLength {
+	if (reqTextLength > segmentThreshold || req.SegmentStrategy != "" || req.Script != "" || dialogueMode) && reqTextLength <= h.config.TTS.MaxTextLength {
 		logger.Info().
 			Int("text_length", reqTextLength).
 			Int("threshold", segmentThreshold).
-			Msg("文本长度超过阈值，使用优化的分段处理")
+			Str("strategy", req.SegmentStrategy).
+			Msg("文本长度超过阈值或指定了分段策略，使用优化的分段处理")
 		h.handleOptimizedSegmentedTTS(c, req, startTime)
 		return
 	}
 
+	// 自动语言检测：各语言片段编译为对应语言的 <voice> 或 <lang> 元素
+	if req.Text != "" && (h.config.TTS.Language.AutoDetect || req.AutoLanguage) {
+		h.compileLanguages(&req)
+	}
+	if req.Text != "" && req.Lexicon != nil {
+		h.annotateText(&req)
+	}
+
 	synthStart := time.Now()
 	resp, err := h.ttsService.SynthesizeSpeech(c.Request.Context(), req)
 	synthTime := time.Since(synthStart)
@@ -190,6 +351,13 @@ func (h *TTSHandler) processTTSRequest(c *gin.Context, req models.TTSRequest, st
 		Msg("TTS合成耗时")
 
 	if err != nil {
+		// 全局上游并发已满且排队超时，提示客户端稍后重试
+		if errors.Is(err, limiter.ErrQueueTimeout) {
+			logger.Warn().Err(err).Msg("上游并发已满，排队超时")
+			_ = c.Error(fmt.Errorf("%w: 服务繁忙，请稍后重试", custom_errors.ErrRateLimited))
+			return
+		}
+
 		// 分类错误并提供详细信息
 		var statusCode int
 		var upstreamErr *custom_errors.UpstreamError
@@ -245,6 +413,335 @@ func (h *TTSHandler) fillDefaultValues(req *models.TTSRequest) {
 	// 这里保留注释以说明为什么不为 Format 设置默认值
 }
 
+// runScripts 执行请求脚本，失败时已记录错误
+func (h *TTSHandler) runScripts(c *gin.Context, req *models.TTSRequest, requestType string) error {
+	logger := h.getLoggerWithTraceID(c)
+	err := h.scripts.Run(c.Request.Context(), req, scripting.Info{APIKey: middleware.APIKey(c), Endpoint: requestType})
+	switch {
+	case err == nil:
+	case errors.Is(err, scripting.ErrRejected):
+		logger.Warn().Err(err).Msg("请求被脚本拒绝")
+		_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+		return err
+	default:
+		logger.Error().Err(err).Msg("请求脚本执行失败")
+		_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInternalServer, err))
+		return err
+	}
+
+	// 脚本修改输入后仍然只能有一种输入
+	inputs := 0
+	for _, provided := range []bool{req.Text != "", req.SSML != "", len(req.Spans) > 0, req.Script != ""} {
+		if provided {
+			inputs++
+		}
+	}
+	switch inputs {
+	case 0:
+		err = errors.New("执行请求脚本后文本为空")
+	case 1:
+		return nil
+	default:
+		err = errors.New("执行请求脚本后 text、ssml、spans 和 script 只能有一个不为空")
+	}
+	_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+	return err
+}
+
+// applyReplaceRules 对文本或剧本执行选中的替换规则集
+func (h *TTSHandler) applyReplaceRules(c *gin.Context, req *models.TTSRequest) error {
+	set, err := h.replacer.Select(req.RuleSet, middleware.APIKey(c))
+	if err != nil || set == nil {
+		return err
+	}
+
+	locale := lang.LocaleOf(req.Voice)
+	switch {
+	case req.Text != "":
+		req.Text, _ = set.Apply(req.Text, locale)
+		if strings.TrimSpace(req.Text) == "" {
+			return errors.New("应用替换规则后文本为空")
+		}
+	case req.Script != "":
+		req.Script, _ = set.Apply(req.Script, locale)
+		if strings.TrimSpace(req.Script) == "" {
+			return errors.New("应用替换规则后剧本为空")
+		}
+	}
+	return nil
+}
+
+// parseDirectives 请求或配置开启行内指令时解析文本中的指令，返回文本是否转为结构化片段。
+// 需要保留纯文本的请求不支持指令，文本中有指令时返回错误
+func (h *TTSHandler) parseDirectives(req *models.TTSRequest, plainText bool) (bool, error) {
+	if req.Text == "" || !(req.Directives || h.config.TTS.Directives.Enabled) {
+		return false, nil
+	}
+	result, err := textproc.ParseDirectives(req.Text)
+	if err != nil {
+		return false, err
+	}
+	if result.Spans != nil {
+		if plainText {
+			return false, errors.New("对白模式、自动语言检测和指定分段策略的请求不支持行内指令")
+		}
+		req.Spans = result.Spans
+		req.Text = ""
+		return true, nil
+	}
+	req.Text = result.Text
+	if req.Text == "" {
+		return false, errors.New("去掉行内指令后文本为空")
+	}
+	return false, nil
+}
+
+// renderMarkdown 按 Markdown 处理方式预处理文本或剧本，返回文本是否渲染为结构化片段。
+// structured 表示文本已由行内指令转为片段，此时只去掉片段中的行内标记
+func (h *TTSHandler) renderMarkdown(req *models.TTSRequest, plainText, structured bool) (bool, error) {
+	mode, err := textproc.ParseMarkdownMode(req.Markdown, textproc.MarkdownMode(h.config.TTS.Markdown.Mode))
+	if err != nil {
+		return false, err
+	}
+
+	if structured {
+		spans := req.Spans[:0]
+		for _, span := range req.Spans {
+			switch mode {
+			case textproc.MarkdownPlain:
+				span.Text = textproc.StripMarkdown(span.Text)
+			case textproc.MarkdownRender:
+				span.Text = textproc.MarkdownInline(span.Text)
+			}
+			// 只有标记的片段去掉后只保留停顿
+			if span.Text == "" {
+				if span.Break == "" {
+					continue
+				}
+				span = models.Span{Voice: span.Voice, Break: span.Break}
+			}
+			spans = append(spans, span)
+		}
+		if len(spans) == 0 {
+			return false, errors.New("去掉 Markdown 标记后文本为空")
+		}
+		req.Spans = spans
+		return true, nil
+	}
+
+	switch mode {
+	case textproc.MarkdownPlain:
+		req.Text = textproc.StripMarkdown(req.Text)
+		req.Script = textproc.StripMarkdown(req.Script)
+	case textproc.MarkdownRender:
+		// 剧本按行解析，只去掉行内标记
+		req.Script = textproc.MarkdownInline(req.Script)
+		if req.Text == "" {
+			return false, nil
+		}
+		result := textproc.RenderMarkdown(req.Text, lang.LocaleOf(req.Voice), h.markdownOptions())
+		if result.Spans != nil && !plainText {
+			req.Spans = result.Spans
+			req.Text = ""
+			return true, nil
+		}
+		req.Text = result.Text
+	}
+	return false, nil
+}
+
+// markdownOptions 返回配置的 Markdown 渲染参数
+func (h *TTSHandler) markdownOptions() textproc.MarkdownOptions {
+	cfg := h.config.TTS.Markdown
+	return textproc.MarkdownOptions{
+		HeadingPause:   time.Duration(*cfg.HeadingPauseMs) * time.Millisecond,
+		ParagraphPause: time.Duration(*cfg.ParagraphPauseMs) * time.Millisecond,
+		ListPause:      time.Duration(*cfg.ListPauseMs) * time.Millisecond,
+		Emphasis:       cfg.Emphasis,
+		Code:           cfg.Code,
+	}
+}
+
+// normalizeText 按语音的语言规范化文本或 Markdown 渲染出的片段。say_as 模式的标注结果作为结构化片段编译，
+// 需要保留纯文本的请求只使用改写结果
+func (h *TTSHandler) normalizeText(req *models.TTSRequest, plainText bool) error {
+	mode, err := textproc.ParseMode(req.Normalize, defaultNormalizeMode(h.config, h.normalizer))
+	if err != nil {
+		return err
+	}
+	if mode == textproc.ModeOff {
+		return nil
+	}
+
+	locale := lang.LocaleOf(req.Voice)
+	if req.Text == "" {
+		req.Spans = h.normalizer.NormalizeSpans(req.Spans, locale, mode)
+		return nil
+	}
+	result := h.normalizer.Normalize(req.Text, locale, mode)
+	if result.Spans != nil && !plainText {
+		req.Spans = result.Spans
+		req.Text = ""
+		return nil
+	}
+	req.Text = result.Text
+	return nil
+}
+
+// convertChinese 对文本、结构化片段或剧本执行简繁转换，片段按各自的语音或语言选择转换方式，
+// 剧本按每行角色的语音选择（角色名不转换，仍与角色表匹配）。SSML 由调用方负责，不转换
+func (h *TTSHandler) convertChinese(req *models.TTSRequest) error {
+	if req.Script != "" {
+		if _, err := h.chinese.Select(req.Convert, lang.LocaleOf(req.Voice)); err != nil {
+			return err
+		}
+		script, err := h.longTextService.RewriteScript(*req, func(text, voice string) string {
+			conv, _ := h.chinese.Select(req.Convert, lang.LocaleOf(voice))
+			if conv == nil {
+				return text
+			}
+			return conv.Convert(text)
+		})
+		if err != nil {
+			return err
+		}
+		req.Script = script
+		return nil
+	}
+
+	if req.Text != "" {
+		conv, err := h.chinese.Select(req.Convert, lang.LocaleOf(req.Voice))
+		if err != nil || conv == nil {
+			return err
+		}
+		req.Text = conv.Convert(req.Text)
+		return nil
+	}
+
+	for i := range req.Spans {
+		span := &req.Spans[i]
+		if span.Text == "" {
+			continue
+		}
+		locale := span.Lang
+		if locale == "" {
+			voice := span.Voice
+			if voice == "" {
+				voice = req.Voice
+			}
+			locale = lang.LocaleOf(voice)
+		}
+		conv, err := h.chinese.Select(req.Convert, locale)
+		if err != nil {
+			return err
+		}
+		if conv != nil {
+			span.Text = conv.Convert(span.Text)
+		}
+	}
+	return nil
+}
+
+// checkSSMLPolicy 按 SSML 安全策略检查客户端提交的 SSML（编译前的 SSML 只来自客户端或请求脚本）
+// 和结构化请求编译后的 SSML，
+// 其他请求检查使用的语音是否允许当前 API 密钥使用，失败时已记录错误
+func (h *TTSHandler) checkSSMLPolicy(c *gin.Context, req *models.TTSRequest) error {
+	logger := h.getLoggerWithTraceID(c)
+	apiKey := middleware.APIKey(c)
+	if req.SSML != "" {
+		if err := h.ssmlPolicy.Check(req.SSML, apiKey); err != nil {
+			logger.Warn().Err(err).Msg("SSML 不符合安全策略")
+			_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+			return err
+		}
+		return nil
+	}
+
+	names := []string{req.Voice, req.DialogueVoice, req.DialogueMaleVoice, req.DialogueFemaleVoice}
+	for _, span := range req.Spans {
+		names = append(names, span.Voice)
+	}
+	for _, member := range req.Cast {
+		names = append(names, member.Voice)
+	}
+	for _, name := range names {
+		if name != "" && !h.ssmlPolicy.AllowVoice(apiKey, name) {
+			allowed := "none"
+			if patterns := h.ssmlPolicy.AllowedVoices(apiKey); len(patterns) > 0 {
+				allowed = strings.Join(patterns, ", ")
+			}
+			err := fmt.Errorf("%w: voice %q is not allowed for this API key, allowed: %s",
+				ssml.ErrPolicyViolation, name, allowed)
+			logger.Warn().Err(err).Msg("API 密钥不能使用该语音")
+			_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+			return err
+		}
+	}
+	return nil
+}
+
+// checkVoices 按语音列表检查请求，不支持的风格和角色改用默认值时在响应头中说明，失败时已记录错误。
+// 无法获取语音列表时跳过检查。
+func (h *TTSHandler) checkVoices(c *gin.Context, req *models.TTSRequest) error {
+	logger := h.getLoggerWithTraceID(c)
+	warnings, err := h.voiceChecker.Check(c.Request.Context(), req)
+	switch {
+	case err == nil:
+	case errors.Is(err, voices.ErrCatalogUnavailable):
+		logger.Warn().Err(err).Msg("无法获取语音列表，跳过语音检查")
+		return nil
+	default:
+		logger.Warn().Err(err).Msg("语音检查失败")
+		_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+		return err
+	}
+	if len(warnings) > 0 {
+		logger.Info().Strs("warnings", warnings).Msg("语音不支持的风格或角色已改用默认值")
+		c.Header("X-TTS-Warning", strings.Join(warnings, "; "))
+	}
+	return nil
+}
+
+// annotateText 文本包含发音词典词条时编译为带 <phoneme>/<sub> 的 SSML，编译失败时保留原文本
+func (h *TTSHandler) annotateText(req *models.TTSRequest) {
+	spans, matches := req.Lexicon.Apply([]models.Span{{Text: req.Text}})
+	if matches == 0 {
+		return
+	}
+
+	annotated := *req
+	annotated.Spans = spans
+	compiled, err := ssml.Compile(annotated)
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("发音词典标注失败，按原文本合成")
+		return
+	}
+	req.SSML = compiled
+	req.Text = ""
+}
+
+// compileLanguages 文本包含多种语言时编译为 SSML，编译失败时保留原文本
+func (h *TTSHandler) compileLanguages(req *models.TTSRequest) {
+	primary := lang.LocaleOf(req.Voice)
+	runs := lang.Detect(req.Text, primary, h.config.TTS.Language.MinRunLength)
+	if len(runs) < 2 {
+		return
+	}
+
+	langReq := *req
+	langReq.Spans = ssml.LanguageSpans(runs, primary, h.config.TTS.Language.LocaleVoices)
+	if req.Lexicon != nil {
+		langReq.Spans, _ = req.Lexicon.Apply(langReq.Spans)
+	}
+	compiled, err := ssml.Compile(langReq)
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("多语言文本编译失败，按原文本合成")
+		return
+	}
+	req.SSML = compiled
+	req.Text = ""
+}
+
 // HandleTTS 处理TTS请求
 func (h *TTSHandler) HandleTTS(c *gin.Context) {
 	switch c.Request.Method {
@@ -270,6 +767,51 @@ func (h *TTSHandler) HandleTTSGet(c *gin.Context) {
 		Pitch: c.Query("p"),
 		Style: c.Query("s"),
 		Format: c.Query("f"),
+		BestEffort: c.Query("best_effort") == "true",
+
+		Volume: c.Query("volume"),
+		Role:   c.Query("role"),
+
+		SegmentStrategy:  c.Query("segment_strategy"),
+		SegmentDelimiter: c.Query("segment_delimiter"),
+		SegmentBudget:    c.Query("segment_budget"),
+
+		Script: c.Query("script"),
+
+		DialogueVoice:       c.Query("dialogue_voice"),
+		DialogueMaleVoice:   c.Query("dialogue_male_voice"),
+		DialogueFemaleVoice: c.Query("dialogue_female_voice"),
+
+		AutoLanguage: c.Query("auto_language") == "true",
+		Directives:   c.Query("directives") == "true",
+		Normalize:    c.Query("normalize"),
+		RuleSet:      c.Query("rule_set"),
+		Markdown:     c.Query("markdown"),
+		Convert:      c.Query("convert"),
+	}
+	if degree := c.Query("styledegree"); degree != "" {
+		n, err := strconv.ParseFloat(degree, 64)
+		if err != nil {
+			_ = c.Error(fmt.Errorf("%w: styledegree 必须是数字", custom_errors.ErrInvalidInput))
+			return
+		}
+		req.StyleDegree = n
+	}
+	if length := c.Query("segment_length"); length != "" {
+		n, err := strconv.Atoi(length)
+		if err != nil {
+			_ = c.Error(fmt.Errorf("%w: segment_length 必须是整数", custom_errors.ErrInvalidInput))
+			return
+		}
+		req.SegmentLength = n
+	}
+	if gap := c.Query("line_gap_ms"); gap != "" {
+		n, err := strconv.Atoi(gap)
+		if err != nil {
+			_ = c.Error(fmt.Errorf("%w: line_gap_ms 必须是整数", custom_errors.ErrInvalidInput))
+			return
+		}
+		req.LineGapMs = &n
 	}
 
 	parseTime := time.Since(startTime)
@@ -352,15 +894,10 @@ func (h *TTSHandler) convertOpenAIRequest(openaiReq models.OpenAIRequest) models
 		msVoice = h.config.TTS.VoiceMapping[openaiReq.Voice]
 	}
 
-	// 转换速度参数到微软格式
+	// 速度倍数转换为语速，超出范围时截断
 	msRate := h.config.TTS.DefaultRate
 	if openaiReq.Speed != 0 {
-		speedPercentage := (openaiReq.Speed - 1.0) * 100
-		if speedPercentage >= 0 {
-			msRate = fmt.Sprintf("+%.0f", speedPercentage)
-		} else {
-			msRate = fmt.Sprintf("%.0f", speedPercentage)
-		}
+		msRate = prosody.FromMultiplier(openaiReq.Speed)
 	}
 
 	return models.TTSRequest{
@@ -383,6 +920,10 @@ func (h *TTSHandler) HandleReader(context *gin.Context) {
 		Style: context.Query("s"),
 		Format: context.Query("f"),
 	}
+	if err := importProsody(context, &req); err != nil {
+		_ = context.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+		return
+	}
 	displayName := context.Query("n")
 
 	baseUrl := utils.GetBaseURL(context)
@@ -401,7 +942,19 @@ func (h *TTSHandler) HandleReader(context *gin.Context) {
 	}
 
 	if req.Pitch != "" {
-		urlParams = append(urlParams, fmt.Sprintf("p=%s", req.Pitch))
+		urlParams = append(urlParams, fmt.Sprintf("p=%s", url.QueryEscape(req.Pitch)))
+	}
+
+	if req.Volume != "" {
+		urlParams = append(urlParams, fmt.Sprintf("volume=%s", url.QueryEscape(req.Volume)))
+	}
+
+	if req.StyleDegree != 0 {
+		urlParams = append(urlParams, fmt.Sprintf("styledegree=%s", strconv.FormatFloat(req.StyleDegree, 'f', -1, 64)))
+	}
+
+	if req.Role != "" {
+		urlParams = append(urlParams, fmt.Sprintf("role=%s", req.Role))
 	}
 
 	if req.Style != "" {
@@ -431,6 +984,21 @@ func (h *TTSHandler) HandleReader(context *gin.Context) {
 	}
 }
 
+// importProsody 读取导入配置的音量、风格强度和角色参数，并把韵律参数统一为 /tts 接受的写法
+func importProsody(c *gin.Context, req *models.TTSRequest) error {
+	req.Volume = c.Query("volume")
+	req.Role = c.Query("role")
+	if degree := c.Query("styledegree"); degree != "" {
+		n, err := strconv.ParseFloat(degree, 64)
+		if err != nil {
+			return errors.New("styledegree 必须是数字")
+		}
+		req.StyleDegree = n
+	}
+	_, err := prosody.Normalize(req)
+	return err
+}
+
 // HandleIFreeTime 处理IFreeTime应用请求
 func (h *TTSHandler) HandleIFreeTime(context *gin.Context) {
 	// 从URL参数获取
@@ -441,6 +1009,10 @@ func (h *TTSHandler) HandleIFreeTime(context *gin.Context) {
 		Style: context.Query("s"),
 		Format: context.Query("f"),
 	}
+	if err := importProsody(context, &req); err != nil {
+		_ = context.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+		return
+	}
 	displayName := context.Query("n")
 
 	// 获取基础URL
@@ -469,6 +1041,15 @@ func (h *TTSHandler) HandleIFreeTime(context *gin.Context) {
 		"s": req.Style,
 		"f": req.Format,
 	}
+	if req.Volume != "" {
+		params["volume"] = req.Volume
+	}
+	if req.StyleDegree != 0 {
+		params["styledegree"] = strconv.FormatFloat(req.StyleDegree, 'f', -1, 64)
+	}
+	if req.Role != "" {
+		params["role"] = req.Role
+	}
 
 	// 如果需要API密钥认证，添加到请求参数
 	if h.config.TTS.ApiKey != "" {
@@ -524,6 +1105,27 @@ func (h *TTSHandler) handleOptimizedSegmentedTTS(c *gin.Context, req models.TTSR
 	synthTime := time.Since(synthStart)
 	
 	if err != nil {
+		// 全局上游并发已满且排队超时，提示客户端稍后重试
+		if errors.Is(err, limiter.ErrQueueTimeout) {
+			logger.Warn().Err(err).Msg("上游并发已满，排队超时")
+			_ = c.Error(fmt.Errorf("%w: 服务繁忙，请稍后重试", custom_errors.ErrRateLimited))
+			return
+		}
+
+		// 分段参数无效（未知策略、缺少分隔符、长度越界等）、SSML 或剧本无法解析
+		if errors.Is(err, tts.ErrInvalidSegmentOptions) || errors.Is(err, tts.ErrInvalidSSML) || errors.Is(err, tts.ErrInvalidScript) {
+			logger.Warn().Err(err).Msg("分段参数无效")
+			_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+			return
+		}
+
+		// 分段音频超过存储上限，说明文本过长
+		if errors.Is(err, audio.ErrStoreFull) {
+			logger.Warn().Err(err).Msg("分段音频超过存储上限")
+			_ = c.Error(fmt.Errorf("%w: 文本过长，合成音频超出大小限制", custom_errors.ErrInvalidInput))
+			return
+		}
+
 		// 分类错误并提供详细信息
 		var statusCode int
 		var upstreamErr *custom_errors.UpstreamError
@@ -543,6 +1145,11 @@ func (h *TTSHandler) handleOptimizedSegmentedTTS(c *gin.Context, req models.TTSR
 		return
 	}
 	
+	// 流式结果读取完毕后关闭，以清理临时文件
+	if resp.AudioStream != nil {
+		defer resp.AudioStream.Close()
+	}
+	
 	// 获取工作池统计信息
 	stats := h.longTextService.GetStats()
 	logger.Info().
@@ -551,12 +1158,39 @@ func (h *TTSHandler) handleOptimizedSegmentedTTS(c *gin.Context, req models.TTSR
 		Int64("failed_jobs", stats.FailedJobs).
 		Str("success_rate", fmt.Sprintf("%.2f%%", stats.SuccessRate)).
 		Int("active_workers", stats.ActiveWorkers).
+		Int("concurrency_limit", stats.ConcurrencyLimit).
+		Int("active_batches", stats.ActiveBatches).
 		Msg("工作池统计")
 	
+	// 尽力模式下有分段以静音替代，通过响应头告知客户端
+	if len(resp.SubstitutedSegments) > 0 {
+		logger.Warn().
+			Ints("substituted_segments", resp.SubstitutedSegments).
+			Msg("部分分段合成失败，已以静音替代")
+		c.Header("X-TTS-Substituted-Segments", joinInts(resp.SubstitutedSegments))
+	}
+
+	// 剧本合成返回每行的时间清单：完整清单通过 /api/manifest/:id 查询，较短的清单（不含文本）同时放在响应头中
+	if len(resp.Lines) > 0 {
+		if err := h.setManifestHeaders(c, resp.Lines); err != nil {
+			logger.Warn().Err(err).Msg("时间清单序列化失败")
+		}
+	}
+	
 	// 设置响应
 	c.Header("Content-Type", "audio/mpeg")
 	writeStart := time.Now()
-	if _, err := c.Writer.Write(resp.AudioContent); err != nil {
+	audioSize := len(resp.AudioContent)
+	if resp.AudioStream != nil {
+		// 合并结果保存在磁盘上，边读边写，不在内存中保留完整音频
+		c.Header("Content-Length", strconv.FormatInt(resp.AudioSize, 10))
+		written, err := io.Copy(c.Writer, resp.AudioStream)
+		if err != nil {
+			logger.Error().Err(err).Int64("written", written).Msg("写入响应失败")
+			return
+		}
+		audioSize = int(written)
+	} else if _, err := c.Writer.Write(resp.AudioContent); err != nil {
 		logger.Error().Err(err).Msg("写入响应失败")
 		return
 	}
@@ -568,6 +1202,15 @@ func (h *TTSHandler) handleOptimizedSegmentedTTS(c *gin.Context, req models.TTSR
 		Dur("total_time", totalTime).
 		Dur("synth_time", synthTime).
 		Dur("write_time", writeTime).
-		Str("audio_size", formatFileSize(len(resp.AudioContent))).
+		Str("audio_size", formatFileSize(audioSize)).
 		Msg("优化的分段TTS请求总耗时")
 }
+
+// joinInts 将整数列表格式化为逗号分隔的字符串
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
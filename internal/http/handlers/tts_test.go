@@ -0,0 +1,280 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	"tts/internal/config"
+	"tts/internal/http/routes"
+	"tts/internal/models"
+	"tts/internal/tts/microsoft"
+)
+
+var testVoices = []models.Voice{
+	{ShortName: "zh-CN-XiaoxiaoNeural", Locale: "zh-CN", StyleList: []string{"cheerful"}},
+	{ShortName: "zh-CN-YunxiNeural", Locale: "zh-CN"},
+	{ShortName: "en-US-JennyNeural", Locale: "en-US"},
+}
+
+// fakeService 记录发往上游的 SSML（按 microsoft.Client 的方式生成），不请求网络
+type fakeService struct {
+	client   *microsoft.Client
+	voiceErr error
+
+	mu   sync.Mutex
+	sent []string
+}
+
+func (f *fakeService) ListVoices(ctx context.Context, locale string) ([]models.Voice, error) {
+	if f.voiceErr != nil {
+		return nil, f.voiceErr
+	}
+	return testVoices, nil
+}
+
+func (f *fakeService) SynthesizeSpeech(ctx context.Context, req models.TTSRequest) (*models.TTSResponse, error) {
+	document, err := f.client.BuildSSML(req)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.sent = append(f.sent, document)
+	f.mu.Unlock()
+	return &models.TTSResponse{AudioContent: []byte("audio"), ContentType: "audio/mpeg"}, nil
+}
+
+func (f *fakeService) documents() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.sent...)
+}
+
+// newTestServer 使用嵌入的默认配置创建路由，configure 修改配置的副本
+func newTestServer(t *testing.T, configure func(cfg *config.Config)) (*gin.Engine, *fakeService) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	base, err := config.Load("")
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	cfg := *base
+	if configure != nil {
+		configure(&cfg)
+	}
+	service := &fakeService{client: microsoft.NewClient(&cfg, zerolog.Nop())}
+	router, err := routes.SetupRoutes(&cfg, service, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("SetupRoutes() error = %v", err)
+	}
+	return router, service
+}
+
+func serve(router *gin.Engine, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestTTS_TextMarkup 测试文本中的标记按文字朗读，不能绕过 SSML 策略插入元素
+func TestTTS_TextMarkup(t *testing.T) {
+	router, service := newTestServer(t, nil)
+	text := `hello <break time=\"600s\"/> <audio src=\"http://evil/x.mp3\">a</audio>`
+	for _, body := range []string{
+		`{"text": "` + text + `"}`,
+		`{"text": "` + text + `", "segment_strategy": "line"}`, // 长文本分段
+	} {
+		if w := serve(router, http.MethodPost, "/api/tts", body); w.Code != http.StatusOK {
+			t.Fatalf("POST %s = %d %s", body, w.Code, w.Body.String())
+		}
+	}
+	documents := service.documents()
+	if len(documents) != 2 {
+		t.Fatalf("发往上游 %d 个请求, want 2", len(documents))
+	}
+	for _, document := range documents {
+		if strings.Contains(document, "<break") || strings.Contains(document, "<audio") {
+			t.Errorf("文本中的标记不应发往上游:\n%s", document)
+		}
+		if !strings.Contains(document, `&lt;break time="600s"/&gt;`) {
+			t.Errorf("文本中的标记应转义后朗读:\n%s", document)
+		}
+	}
+
+	// 同样的标记作为 SSML 提交时按策略拒绝
+	ssml := `<speak version=\"1.0\" xml:lang=\"en-US\"><voice name=\"en-US-JennyNeural\">hello <break time=\"600s\"/></voice></speak>`
+	if w := serve(router, http.MethodPost, "/api/tts", `{"ssml": "`+ssml+`"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("超长停顿的 SSML = %d, want 400", w.Code)
+	}
+}
+
+// TestTTS_VoiceAttribute 测试语音名称按属性值转义，无法获取语音目录时也不能注入标记
+func TestTTS_VoiceAttribute(t *testing.T) {
+	router, service := newTestServer(t, nil)
+	service.voiceErr = context.DeadlineExceeded
+	body := `{"text": "你好", "voice": "x'><break time='600s'/><voice name='y"}`
+	if w := serve(router, http.MethodPost, "/api/tts", body); w.Code != http.StatusOK {
+		t.Fatalf("POST = %d %s", w.Code, w.Body.String())
+	}
+	documents := service.documents()
+	if len(documents) != 1 || strings.Contains(documents[0], "<break") || strings.Count(documents[0], "<voice") != 1 {
+		t.Errorf("语音名称不应注入元素: %q", documents)
+	}
+}
+
+// TestTTS_Policy 测试 text、ssml、spans 和 script 请求都按 SSML 策略检查，语音限制按通过验证的密钥选择
+func TestTTS_Policy(t *testing.T) {
+	router, service := newTestServer(t, func(cfg *config.Config) {
+		cfg.TTS.ApiKey = "team-a"
+		cfg.SSML.Voices = map[string][]string{"team-a": {"zh-CN-*"}}
+	})
+	tests := []struct {
+		name, body string
+		want       int
+	}{
+		{"文本", `{"text": "你好", "voice": "zh-CN-YunxiNeural"}`, http.StatusOK},
+		{"文本使用不允许的语音", `{"text": "hi", "voice": "en-US-JennyNeural"}`, http.StatusBadRequest},
+		{"SSML 使用不允许的语音", `{"ssml": "<speak version=\"1.0\" xml:lang=\"en-US\"><voice name=\"en-US-JennyNeural\">hi</voice></speak>"}`, http.StatusBadRequest},
+		{"SSML 停顿过长", `{"ssml": "<speak version=\"1.0\" xml:lang=\"zh-CN\"><voice name=\"zh-CN-YunxiNeural\">你好<break time=\"600s\"/></voice></speak>"}`, http.StatusBadRequest},
+		{"片段使用不允许的语音", `{"spans": [{"text": "hi", "voice": "en-US-JennyNeural"}]}`, http.StatusBadRequest},
+		{"片段停顿过长", `{"spans": [{"text": "你好", "break": "10s"}, {"text": "世界"}]}`, http.StatusBadRequest},
+		{"剧本角色使用不允许的语音", `{"script": "[A] hi", "cast": {"A": {"voice": "en-US-JennyNeural"}}}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := serve(router, http.MethodPost, "/api/tts?api_key=team-a", tt.body); w.Code != tt.want {
+				t.Errorf("POST = %d %s, want %d", w.Code, w.Body.String(), tt.want)
+			}
+		})
+	}
+	if n := len(service.documents()); n != 1 {
+		t.Errorf("发往上游 %d 个请求, want 1", n)
+	}
+}
+
+// TestTTS_PolicyUnauthenticatedKey 测试未经验证的密钥不能选择其他密钥的语音限制，未列出的密钥使用 * 条目
+func TestTTS_PolicyUnauthenticatedKey(t *testing.T) {
+	router, _ := newTestServer(t, func(cfg *config.Config) {
+		cfg.TTS.ApiKey = ""
+		cfg.SSML.Voices = map[string][]string{"team-a": {"zh-CN-*"}, "*": {"zh-CN-XiaoxiaoNeural"}}
+	})
+	for _, target := range []string{"/api/tts", "/api/tts?api_key=team-a"} {
+		if w := serve(router, http.MethodPost, target, `{"text": "你好", "voice": "zh-CN-XiaoxiaoNeural"}`); w.Code != http.StatusOK {
+			t.Errorf("POST %s = %d %s, want 200", target, w.Code, w.Body.String())
+		}
+		if w := serve(router, http.MethodPost, target, `{"text": "你好", "voice": "zh-CN-YunxiNeural"}`); w.Code != http.StatusBadRequest {
+			t.Errorf("POST %s 使用 team-a 的语音 = %d, want 400", target, w.Code)
+		}
+	}
+
+	router, _ = newTestServer(t, func(cfg *config.Config) {
+		cfg.TTS.ApiKey = ""
+		cfg.SSML.Voices = map[string][]string{"team-a": {"zh-CN-*"}}
+	})
+	if w := serve(router, http.MethodPost, "/api/tts?api_key=team-a", `{"text": "你好", "voice": "zh-CN-XiaoxiaoNeural"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("没有 * 条目时未经验证的请求 = %d, want 400", w.Code)
+	}
+}
+
+// TestTTS_ScriptFormat 测试剧本和对白请求只接受 MP3 格式
+func TestTTS_ScriptFormat(t *testing.T) {
+	router, service := newTestServer(t, nil)
+	tests := []struct {
+		name, body string
+		want       int
+	}{
+		{"剧本使用 PCM", `{"script": "[A] 你好", "cast": {"A": {"voice": "zh-CN-YunxiNeural"}}, "format": "riff-24khz-16bit-mono-pcm"}`, http.StatusBadRequest},
+		{"对白使用 PCM", `{"text": "他说：“你好”", "dialogue_voice": "zh-CN-YunxiNeural", "format": "riff-24khz-16bit-mono-pcm"}`, http.StatusBadRequest},
+		{"文本使用 PCM", `{"text": "你好", "format": "riff-24khz-16bit-mono-pcm"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := serve(router, http.MethodPost, "/api/tts", tt.body); w.Code != tt.want {
+				t.Errorf("POST = %d %s, want %d", w.Code, w.Body.String(), tt.want)
+			}
+		})
+	}
+	if n := len(service.documents()); n != 1 {
+		t.Errorf("发往上游 %d 个请求, want 1", n)
+	}
+}
+
+// TestTTS_TextMarkupPaths 测试 Markdown、行内指令、语言检测和词典路径与纯文本一样转义文本中的标记
+func TestTTS_TextMarkupPaths(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dictionary.yaml")
+	if err := os.WriteFile(path, []byte("global:\n  - term: 重庆\n    sub: 崇庆\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	router, service := newTestServer(t, func(cfg *config.Config) {
+		cfg.TTS.Dictionary.Path = path
+	})
+	markup := `<break time=\"600s\"/>`
+	tests := []struct{ name, body string }{
+		{"纯文本", `{"text": "你好 ` + markup + `", "markdown": "off"}`},
+		{"Markdown", `{"text": "# 标题 ` + markup + `\n\n正文 **加粗 ` + markup + `**", "markdown": "markdown"}`},
+		{"行内指令", `{"text": "你好 ` + markup + ` [pause:500ms] 世界", "directives": true}`},
+		{"语言检测", `{"text": "你好 ` + markup + ` hello world", "auto_language": true}`},
+		{"词典", `{"text": "重庆 ` + markup + `"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(service.documents())
+			if w := serve(router, http.MethodPost, "/api/tts", tt.body); w.Code != http.StatusOK {
+				t.Fatalf("POST = %d %s", w.Code, w.Body.String())
+			}
+			for _, document := range service.documents()[before:] {
+				// 语言检测按文字切换语音，转义后的标记可能分属不同的片段
+				if strings.Contains(document, `<break time="600s"`) || !strings.Contains(document, "&lt;") || !strings.Contains(document, "&gt;") {
+					t.Errorf("文本中的标记应转义后朗读:\n%s", document)
+				}
+			}
+		})
+	}
+}
+
+// TestTTS_VoiceCheckCatalogUnavailable 测试无法获取语音列表时跳过语音检查，请求按原样发往上游
+func TestTTS_VoiceCheckCatalogUnavailable(t *testing.T) {
+	body := `{"text": "你好", "voice": "zh-CN-YunxiNeural", "style": "whispering"}`
+	configure := func(mode string) func(cfg *config.Config) {
+		return func(cfg *config.Config) { cfg.TTS.VoiceCheck.Mode = mode }
+	}
+
+	// 语音列表可用时：fallback 改用默认风格并返回警告，strict 拒绝请求
+	router, _ := newTestServer(t, configure("fallback"))
+	if w := serve(router, http.MethodPost, "/api/tts", body); w.Code != http.StatusOK || w.Header().Get("X-TTS-Warning") == "" {
+		t.Errorf("fallback = %d, warning %q", w.Code, w.Header().Get("X-TTS-Warning"))
+	}
+	router, _ = newTestServer(t, configure("strict"))
+	if w := serve(router, http.MethodPost, "/api/tts", body); w.Code != http.StatusBadRequest {
+		t.Errorf("strict = %d %s, want 400", w.Code, w.Body.String())
+	}
+
+	for _, mode := range []string{"fallback", "strict"} {
+		t.Run(mode, func(t *testing.T) {
+			router, service := newTestServer(t, configure(mode))
+			service.voiceErr = context.DeadlineExceeded
+			w := serve(router, http.MethodPost, "/api/tts", body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("语音列表不可用时 = %d %s, want 200", w.Code, w.Body.String())
+			}
+			if warning := w.Header().Get("X-TTS-Warning"); warning != "" {
+				t.Errorf("跳过检查时不应返回警告: %s", warning)
+			}
+			if documents := service.documents(); len(documents) != 1 || !strings.Contains(documents[0], `style="whispering"`) {
+				t.Errorf("跳过检查时应保留请求的风格: %q", documents)
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
-	"sort"
+	"strconv"
 	"strings"
+	custom_errors "tts/internal/errors"
 	"tts/internal/tts"
+	"tts/internal/voices"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,51 +15,90 @@ import (
 // VoicesHandler 处理语音列表请求
 type VoicesHandler struct {
 	ttsService tts.Service
+	sorter     *voices.Sorter
 }
 
 // NewVoicesHandler 创建一个新的语音列表处理器
-func NewVoicesHandler(service tts.Service) *VoicesHandler {
+func NewVoicesHandler(service tts.Service, sorter *voices.Sorter) *VoicesHandler {
 	return &VoicesHandler{
 		ttsService: service,
+		sorter:     sorter,
 	}
 }
 
-// HandleVoices 处理语音列表请求
+// HandleVoices 处理语音列表请求，支持筛选、搜索、排序、分页和按语言区域分组
 func (h *VoicesHandler) HandleVoices(c *gin.Context) {
-	// 从查询参数中获取语言筛选
-	locale := c.Query("locale")
+	// 从查询参数中获取筛选条件
+	query := voices.Query{
+		Locale:    c.Query("locale"),
+		Language:  c.Query("language"),
+		Gender:    c.Query("gender"),
+		Style:     c.Query("style"),
+		Role:      c.Query("role"),
+		Status:    c.Query("status"),
+		VoiceType: c.Query("type"),
+		Search:    c.Query("q"),
+	}
+
+	sorter := h.sorter
+	if keys := c.Query("sort"); keys != "" {
+		var err error
+		if sorter, err = h.sorter.WithKeys(strings.Split(keys, ",")); err != nil {
+			_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+			return
+		}
+	}
+
+	page, pageSize := 1, 0
+	for _, p := range []struct {
+		name  string
+		value *int
+	}{{"page", &page}, {"page_size", &pageSize}} {
+		if raw := c.Query(p.name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				_ = c.Error(fmt.Errorf("%w: %s 必须是整数", custom_errors.ErrInvalidInput, p.name))
+				return
+			}
+			*p.value = n
+		}
+	}
+
+	group := c.Query("group")
+	if group != "" && group != "locale" {
+		_ = c.Error(fmt.Errorf("%w: group 只支持 locale", custom_errors.ErrInvalidInput))
+		return
+	}
 
 	// 获取语音列表
-	voices, err := h.ttsService.ListVoices(c.Request.Context(), locale)
+	list, err := h.ttsService.ListVoices(c.Request.Context(), "")
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "获取语音列表失败: " + err.Error()})
 		return
 	}
 
-	// 排序：晓晓优先，然后是中文语音，最后按本地名称排序
-	sort.Slice(voices, func(i, j int) bool {
-		// 检查是否是晓晓（Xiaoxiao）
-		isXiaoxiaoI := strings.Contains(voices[i].ShortName, "Xiaoxiao") || strings.Contains(voices[i].LocalName, "晓晓")
-		isXiaoxiaoJ := strings.Contains(voices[j].ShortName, "Xiaoxiao") || strings.Contains(voices[j].LocalName, "晓晓")
-
-		if isXiaoxiaoI != isXiaoxiaoJ {
-			return isXiaoxiaoI // 晓晓排在最前面
-		}
-
-		// 检查是否是中文语音
-		isChineseI := strings.HasPrefix(voices[i].Locale, "zh")
-		isChineseJ := strings.HasPrefix(voices[j].Locale, "zh")
-		if isChineseI != isChineseJ {
-			return isChineseI // 中文的排前面
-		}
-
-		// 同语言按本地名称排序
-		return voices[i].LocalName < voices[j].LocalName
-	})
+	// 筛选结果是新的切片，排序不会修改缓存的语音列表
+	matched := voices.Filter(list, query)
+	sorter.Sort(matched)
+	paged, err := voices.Paginate(matched, page, pageSize)
+	if err != nil {
+		_ = c.Error(fmt.Errorf("%w: %v", custom_errors.ErrInvalidInput, err))
+		return
+	}
 
 	// 返回JSON响应 - 包装在对象中以匹配前端期望的格式
-	c.JSON(http.StatusOK, gin.H{
-		"voices": voices,
-		"count":  len(voices),
-	})
+	resp := gin.H{
+		"count": len(paged),
+		"total": len(matched),
+	}
+	if pageSize > 0 {
+		resp["page"] = page
+		resp["page_size"] = pageSize
+	}
+	if group == "locale" {
+		resp["groups"] = voices.GroupByLocale(paged)
+	} else {
+		resp["voices"] = paged
+	}
+	c.JSON(http.StatusOK, resp)
 }
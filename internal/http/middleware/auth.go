@@ -6,6 +6,16 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// authenticatedKeyName 通过验证的 API 密钥在 gin 上下文中的键
+const authenticatedKeyName = "authenticated_api_key"
+
+// APIKey 返回请求通过验证的 API 密钥，未配置密钥（不验证）时返回空字符串。
+// 按密钥区分的设置（语音限制、替换规则集、发音词典、请求脚本）只使用这个密钥，
+// 不使用客户端在参数或请求头中声明、未经验证的密钥
+func APIKey(c *gin.Context) string {
+	return c.GetString(authenticatedKeyName)
+}
+
 // OpenAIAuth 中间件验证 OpenAI API 请求的令牌
 func OpenAIAuth(apiToken string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -36,6 +46,7 @@ func OpenAIAuth(apiToken string) gin.HandlerFunc {
 		}
 
 		// 令牌验证通过，继续处理请求
+		c.Set(authenticatedKeyName, apiToken)
 		c.Next()
 	}
 }
@@ -51,8 +62,23 @@ func TTSAuth(apiKey string) gin.HandlerFunc {
 			c.AbortWithStatusJSON(401, gin.H{"error": "未授权访问: 无效的 API 密钥"})
 			return
 		}
+		if apiKey != "" {
+			c.Set(authenticatedKeyName, apiKey)
+		}
 
 		// 验证通过，继续处理请求
 		c.Next()
 	}
 }
+
+// RequireAPIKey 用于会修改服务器状态的接口：未配置 API 密钥时拒绝请求，
+// 避免在不需要验证的部署中任何人都能写入持久化的文件
+func RequireAPIKey(apiKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if apiKey == "" {
+			c.AbortWithStatusJSON(403, gin.H{"error": "未配置 API 密钥，不允许修改"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,13 +1,20 @@
 package routes
 
 import (
+	"context"
 	"io/fs"
 	"net/http"
+	"time"
 	"tts/internal/config"
 	"tts/internal/http/handlers"
 	"tts/internal/http/middleware"
+	"tts/internal/models"
+	"tts/internal/scripting"
+	"tts/internal/ssml"
+	"tts/internal/textproc"
 	"tts/internal/tts"
 	"tts/internal/tts/microsoft"
+	"tts/internal/voices"
 	"tts/web"
 
 	"github.com/gin-gonic/gin"
@@ -19,38 +26,111 @@ func SetupRoutes(cfg *config.Config, ttsService tts.Service, logger zerolog.Logg
 	// 创建Gin路由
 	router := gin.New()
 
-	// 获取底层的 microsoft.Client 用于长文本服务
-	var msClient *microsoft.Client
-	
-	// 如果是缓存服务,需要获取底层服务
-	type underlyingServiceGetter interface {
-		GetUnderlyingService() tts.Service
-	}
-	
-	if cacheSvc, ok := ttsService.(underlyingServiceGetter); ok {
-		// 从缓存层获取底层服务
-		msClient = cacheSvc.GetUnderlyingService().(*microsoft.Client)
-	} else {
-		// 直接使用原始服务
-		msClient = ttsService.(*microsoft.Client)
-	}
-
 	// 创建长文本 TTS 服务
+	// 直接使用（可能带缓存的）ttsService，使每个分段都经过缓存，
+	// 修改过的长文本只需重新合成发生变化的分段
 	longTextService := tts.NewLongTextTTSService(
-		msClient,
+		ttsService,
 		tts.LongTextConfig{
 			MaxSegmentLength: cfg.TTS.LongText.MaxSegmentLength,
 			WorkerCount:      cfg.TTS.LongText.WorkerCount,
 			MinTextForSplit:  cfg.TTS.LongText.MinTextForSplit,
 			FFmpegPath:       cfg.TTS.LongText.FFmpegPath,
 			UseSmartSegment:  cfg.TTS.LongText.UseSmartSegment,
+			SegmentStrategy:  cfg.TTS.LongText.SegmentStrategy,
+			SegmentBudget:    cfg.TTS.LongText.SegmentBudget,
+
+			AdaptiveConcurrency: cfg.TTS.LongText.AdaptiveConcurrency,
+			TargetLatency:       time.Duration(cfg.TTS.LongText.TargetLatencyMs) * time.Millisecond,
+
+			SegmentRetries: cfg.TTS.LongText.SegmentRetries,
+			RetryBackoff:   time.Duration(cfg.TTS.LongText.RetryBackoffMs) * time.Millisecond,
+			FallbackVoice:  cfg.TTS.LongText.FallbackVoice,
+			BestEffort:     cfg.TTS.LongText.BestEffort,
+
+			SpillDir:      cfg.TTS.LongText.SpillDir,
+			MaxSpillBytes: int64(cfg.TTS.LongText.MaxSpillMB) * 1024 * 1024,
+
+			Cast:    castMembers(cfg.TTS.Dialogue.Cast),
+			LineGap: time.Duration(*cfg.TTS.Dialogue.LineGapMs) * time.Millisecond,
+
+			AutoLanguage:   cfg.TTS.Language.AutoDetect,
+			MinLanguageRun: cfg.TTS.Language.MinRunLength,
+			LocaleVoices:   cfg.TTS.Language.LocaleVoices,
 		},
 		logger,
 	)
 
+	// 创建文本规范化器
+	normalizer, err := textproc.NewNormalizer(textproc.Mode(cfg.TTS.Normalize.Mode), cfg.TTS.Normalize.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建文本替换规则集
+	replacer, err := textproc.NewReplacer(replaceRuleSets(cfg.TTS.Replace.RuleSets), cfg.TTS.Replace.Default, cfg.TTS.Replace.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建发音词典，词典文件变化时自动重新加载
+	dictionary, err := textproc.NewDictionaryStore(cfg.TTS.Dictionary.Path)
+	if err != nil {
+		return nil, err
+	}
+	dictionary.Watch(time.Duration(cfg.TTS.Dictionary.ReloadInterval)*time.Second, logger)
+
+	// 创建简繁转换器
+	chinese, err := textproc.NewChineseConverter(cfg.TTS.Chinese.DictDir, cfg.TTS.Chinese.AutoConvert, cfg.TTS.Chinese.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建请求脚本，脚本目录变化时自动重新加载
+	scripts, err := scripting.NewStore(cfg.TTS.Scripts.Dir, scripting.Options{
+		Timeout:      time.Duration(cfg.TTS.Scripts.TimeoutMs) * time.Millisecond,
+		MaxCallDepth: cfg.TTS.Scripts.MaxCallDepth,
+		OnError:      cfg.TTS.Scripts.OnError,
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
+	scripts.Watch(time.Duration(cfg.TTS.Scripts.ReloadInterval) * time.Second)
+
+	// 创建语音能力检查器，语音列表来自（带缓存的）上游服务
+	formats := make([]string, 0, len(microsoft.FormatContentTypeMap))
+	for format := range microsoft.FormatContentTypeMap {
+		formats = append(formats, format)
+	}
+	voiceChecker, err := voices.NewChecker(cfg.TTS.VoiceCheck.Mode, func(ctx context.Context) ([]models.Voice, error) {
+		return ttsService.ListVoices(ctx, "")
+	}, formats)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建 SSML 安全策略
+	ssmlPolicy, err := ssml.NewPolicy(ssml.PolicyOptions{
+		Elements:         cfg.SSML.Elements,
+		MaxBreakMs:       cfg.SSML.MaxBreakMs,
+		MaxVoiceSwitches: cfg.SSML.MaxVoiceSwitches,
+		Voices:           cfg.SSML.Voices,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建语音列表的排序器
+	voiceSorter, err := voices.NewSorter(cfg.TTS.VoiceList.Sort, cfg.TTS.VoiceList.PreferredVoices, cfg.TTS.VoiceList.PreferredLocales)
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建处理器
-	ttsHandler := handlers.NewTTSHandler(ttsService, longTextService, cfg, logger)
-	voicesHandler := handlers.NewVoicesHandler(ttsService)
+	ttsHandler := handlers.NewTTSHandler(ttsService, longTextService, normalizer, replacer, dictionary, chinese, scripts, voiceChecker, ssmlPolicy, cfg, logger)
+	textHandler := handlers.NewTextHandler(normalizer, replacer, chinese, cfg)
+	dictionaryHandler := handlers.NewDictionaryHandler(dictionary)
+	voicesHandler := handlers.NewVoicesHandler(ttsService, voiceSorter)
 	metricsHandler := handlers.NewMetricsHandler()
 
 	// 创建页面处理器
@@ -92,6 +172,20 @@ func SetupRoutes(cfg *config.Config, ttsService tts.Service, logger zerolog.Logg
 	// 设置TTS API路由 - 添加认证中间件
 	apiGroup.POST("/tts", middleware.TTSAuth(cfg.TTS.ApiKey), ttsHandler.HandleTTS)
 	apiGroup.GET("/tts", middleware.TTSAuth(cfg.TTS.ApiKey), ttsHandler.HandleTTS)
+	apiGroup.GET("/manifest/:id", middleware.TTSAuth(cfg.TTS.ApiKey), ttsHandler.HandleManifest)
+
+	// 设置文本规范化调试路由
+	apiGroup.POST("/text/normalize", middleware.TTSAuth(cfg.TTS.ApiKey), textHandler.HandleNormalize)
+	apiGroup.GET("/text/normalize", middleware.TTSAuth(cfg.TTS.ApiKey), textHandler.HandleNormalize)
+	apiGroup.POST("/text/replace", middleware.TTSAuth(cfg.TTS.ApiKey), textHandler.HandleReplace)
+	apiGroup.GET("/text/rule-sets", middleware.TTSAuth(cfg.TTS.ApiKey), textHandler.HandleRuleSets)
+	apiGroup.POST("/text/convert", middleware.TTSAuth(cfg.TTS.ApiKey), textHandler.HandleConvert)
+
+	// 设置发音词典管理路由
+	apiGroup.GET("/dictionary", middleware.TTSAuth(cfg.TTS.ApiKey), dictionaryHandler.HandleList)
+	apiGroup.POST("/dictionary", middleware.RequireAPIKey(cfg.TTS.ApiKey), middleware.TTSAuth(cfg.TTS.ApiKey), dictionaryHandler.HandlePut)
+	apiGroup.DELETE("/dictionary", middleware.RequireAPIKey(cfg.TTS.ApiKey), middleware.TTSAuth(cfg.TTS.ApiKey), dictionaryHandler.HandleDelete)
+	apiGroup.POST("/dictionary/reload", middleware.RequireAPIKey(cfg.TTS.ApiKey), middleware.TTSAuth(cfg.TTS.ApiKey), dictionaryHandler.HandleReload)
 
 	// 设置语音列表API路由
 	apiGroup.GET("/voices", voicesHandler.HandleVoices)
@@ -123,3 +217,31 @@ func InitializeServices(cfg *config.Config, logger zerolog.Logger) (tts.Service,
 
 	return ttsClient, nil
 }
+
+// castMembers 将配置中的角色表转换为合成参数
+func castMembers(cast map[string]config.CastConfig) map[string]models.CastMember {
+	members := make(map[string]models.CastMember, len(cast))
+	for name, c := range cast {
+		members[name] = models.CastMember{Voice: c.Voice, Style: c.Style, Rate: c.Rate, Pitch: c.Pitch}
+	}
+	return members
+}
+
+// replaceRuleSets 将配置中的替换规则集转换为规则引擎使用的规则
+func replaceRuleSets(sets map[string][]config.ReplaceRuleConfig) map[string][]textproc.ReplaceRule {
+	result := make(map[string][]textproc.ReplaceRule, len(sets))
+	for name, rules := range sets {
+		result[name] = make([]textproc.ReplaceRule, 0, len(rules))
+		for _, r := range rules {
+			result[name] = append(result[name], textproc.ReplaceRule{
+				Name:        r.Name,
+				Pattern:     r.Pattern,
+				Replacement: r.Replacement,
+				Regex:       r.Regex,
+				Scope:       r.Scope,
+				Enabled:     r.Enabled,
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,189 @@
+// Package lang 按文字系统检测文本中各片段的语言
+package lang
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultLocale 无法从语音名称推断语言时使用的默认语言
+const DefaultLocale = "zh-CN"
+
+// DefaultMinRunLength 切换语言所需的最少字母数，更短的片段跟随相邻的语言
+const DefaultMinRunLength = 8
+
+// Run 文本中使用同一种语言的连续片段
+type Run struct {
+	Text   string
+	Locale string
+}
+
+// script 字符所属的文字系统
+type script int
+
+const (
+	scriptNone script = iota // 数字、标点、空白等，跟随相邻的片段
+	scriptCJK                // 汉字和假名
+	scriptHangul
+	scriptLatin
+	scriptCyrillic
+)
+
+func scriptOf(r rune) script {
+	switch {
+	case unicode.Is(unicode.Han, r), unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+		return scriptCJK
+	case unicode.Is(unicode.Hangul, r):
+		return scriptHangul
+	case unicode.Is(unicode.Latin, r):
+		return scriptLatin
+	case unicode.Is(unicode.Cyrillic, r):
+		return scriptCyrillic
+	}
+	return scriptNone
+}
+
+// LocaleOf 从语音名称（如 zh-CN-XiaoxiaoNeural）推断语言
+func LocaleOf(voice string) string {
+	parts := strings.Split(voice, "-")
+	if len(parts) >= 2 {
+		return parts[0] + "-" + parts[1]
+	}
+	return DefaultLocale
+}
+
+// VoiceFor 返回语言对应的语音，未配置时返回空字符串
+//
+// 语言代码忽略大小写（配置文件中的键会被转为小写），也可以只配置语言部分（如 en）。
+func VoiceFor(voices map[string]string, locale string) string {
+	language := strings.SplitN(locale, "-", 2)[0]
+	var fallback string
+	for key, voice := range voices {
+		if strings.EqualFold(key, locale) {
+			return voice
+		}
+		if strings.EqualFold(key, language) {
+			fallback = voice
+		}
+	}
+	return fallback
+}
+
+// Detect 将文本切分为不同语言的片段
+//
+// 按文字系统判断语言：汉字为中文（含假名时为日语），拉丁字母为英语，韩文为韩语，
+// 西里尔字母为俄语。与 primary 使用同一文字系统时沿用 primary（如 fr-FR 的拉丁字母）。
+// 数字、标点和空白跟随所在的片段；字母数少于 minLength 的片段并入前一个片段，
+// 避免 "iPhone" 这样的单词也切换语音。
+func Detect(text, primary string, minLength int) []Run {
+	if minLength <= 0 {
+		minLength = DefaultMinRunLength
+	}
+
+	type rawRun struct {
+		text    strings.Builder
+		script  script
+		letters int
+		kana    bool
+	}
+	var runs []*rawRun
+	var pending strings.Builder // 第一个字母之前的标点
+	sentenceEnd := false        // 汉字和假名按句判断中文还是日语
+	for _, r := range text {
+		sc := scriptOf(r)
+		if sc == scriptNone {
+			if len(runs) == 0 {
+				pending.WriteRune(r)
+			} else {
+				runs[len(runs)-1].text.WriteRune(r)
+			}
+			sentenceEnd = sentenceEnd || strings.ContainsRune("。！？!?\n", r)
+			continue
+		}
+		newSentence := sentenceEnd && sc == scriptCJK
+		sentenceEnd = false
+		if len(runs) == 0 || runs[len(runs)-1].script != sc || newSentence {
+			runs = append(runs, &rawRun{script: sc})
+			if len(runs) == 1 {
+				runs[0].text.WriteString(pending.String())
+			}
+		}
+		cur := runs[len(runs)-1]
+		cur.text.WriteRune(r)
+		cur.letters++
+		if unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) {
+			cur.kana = true
+		}
+	}
+	if len(runs) == 0 {
+		return []Run{{Text: text, Locale: primary}}
+	}
+
+	var result []Run
+	for i, raw := range runs {
+		locale := localeFor(raw.script, raw.kana, primary)
+		if i > 0 && locale != primary && raw.letters < minLength {
+			// 过短的片段跟随前一个片段
+			locale = result[len(result)-1].Locale
+		}
+		if n := len(result); n > 0 && result[n-1].Locale == locale {
+			result[n-1].Text += raw.text.String()
+			continue
+		}
+		result = append(result, Run{Text: raw.text.String(), Locale: locale})
+	}
+
+	// 开头的短片段并入后一个片段
+	if len(result) > 1 && result[0].Locale != primary && letterCount(result[0].Text) < minLength {
+		result[1].Text = result[0].Text + result[1].Text
+		result = result[1:]
+	}
+	return result
+}
+
+// localeFor 返回文字系统对应的语言，与 primary 属于同一文字系统时使用 primary
+func localeFor(sc script, kana bool, primary string) string {
+	if sc == scriptCJK && kana {
+		if strings.HasPrefix(primary, "ja-") {
+			return primary
+		}
+		return "ja-JP"
+	}
+
+	family := primaryScript(primary)
+	if sc == family {
+		return primary
+	}
+	switch sc {
+	case scriptCJK:
+		return "zh-CN"
+	case scriptHangul:
+		return "ko-KR"
+	case scriptCyrillic:
+		return "ru-RU"
+	}
+	return "en-US"
+}
+
+// primaryScript 返回语言使用的文字系统
+func primaryScript(locale string) script {
+	switch strings.SplitN(locale, "-", 2)[0] {
+	case "zh", "ja":
+		return scriptCJK
+	case "ko":
+		return scriptHangul
+	case "ru", "uk", "bg", "sr", "kk":
+		return scriptCyrillic
+	}
+	return scriptLatin
+}
+
+func letterCount(text string) int {
+	n := 0
+	for _, r := range text {
+		if scriptOf(r) != scriptNone {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,98 @@
+package lang
+
+import "testing"
+
+// TestDetect 测试按文字系统切分语言片段
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		primary string
+		want    []Run
+	}{
+		{
+			name:    "单一语言",
+			text:    "今天天气很好。",
+			primary: "zh-CN",
+			want:    []Run{{"今天天气很好。", "zh-CN"}},
+		},
+		{
+			name:    "短单词跟随上下文",
+			text:    "我买了一台 iPhone，很好用。",
+			primary: "zh-CN",
+			want:    []Run{{"我买了一台 iPhone，很好用。", "zh-CN"}},
+		},
+		{
+			name:    "开头的短单词并入后文",
+			text:    "OK，我们开始吧。",
+			primary: "zh-CN",
+			want:    []Run{{"OK，我们开始吧。", "zh-CN"}},
+		},
+		{
+			name:    "长段英文切换语言",
+			text:    "他说：The quick brown fox jumps over the lazy dog. 然后离开了。",
+			primary: "zh-CN",
+			want: []Run{
+				{"他说：", "zh-CN"},
+				{"The quick brown fox jumps over the lazy dog. ", "en-US"},
+				{"然后离开了。", "zh-CN"},
+			},
+		},
+		{
+			name:    "日语和韩语",
+			text:    "中文句子。これは日本語の文章です。안녕하세요 여러분",
+			primary: "zh-CN",
+			want: []Run{
+				{"中文句子。", "zh-CN"},
+				{"これは日本語の文章です。", "ja-JP"},
+				{"안녕하세요 여러분", "ko-KR"},
+			},
+		},
+		{
+			name:    "同一文字系统沿用默认语言",
+			text:    "Bonjour tout le monde, 你好世界，再见朋友们",
+			primary: "fr-FR",
+			want: []Run{
+				{"Bonjour tout le monde, ", "fr-FR"},
+				{"你好世界，再见朋友们", "zh-CN"},
+			},
+		},
+		{
+			name:    "没有文字",
+			text:    "123 456",
+			primary: "zh-CN",
+			want:    []Run{{"123 456", "zh-CN"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Detect(tt.text, tt.primary, 8)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Detect() = %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("片段 %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestVoiceFor 测试语言到语音的查找
+func TestVoiceFor(t *testing.T) {
+	voices := map[string]string{"en-us": "en-US-JennyNeural", "ja": "ja-JP-NanamiNeural"}
+	tests := []struct {
+		locale, want string
+	}{
+		{"en-US", "en-US-JennyNeural"},
+		{"ja-JP", "ja-JP-NanamiNeural"},
+		{"ko-KR", ""},
+	}
+	for _, tt := range tests {
+		if got := VoiceFor(voices, tt.locale); got != tt.want {
+			t.Errorf("VoiceFor(%q) = %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+}
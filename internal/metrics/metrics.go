@@ -25,6 +25,15 @@ type Metrics struct {
 	WorkerPoolJobs   int64         // 工作池处理的总任务数
 	WorkerPoolErrors int64         // 工作池错误数
 	
+	// 上游并发限流指标
+	UpstreamInFlight int64         // 当前占用的上游并发槽位
+	UpstreamQueued   int64         // 当前排队等待的上游请求数
+	UpstreamTimeouts int64         // 排队超时次数
+	
+	// 长文本分段缓存指标
+	SegmentHits      int64         // 分段缓存命中次数
+	SegmentMisses    int64         // 分段缓存未命中次数
+	
 	mu               sync.RWMutex  // 用于 min/max 更新
 }
 
@@ -76,6 +85,23 @@ func (m *Metrics) RecordWorkerPoolJob(err error) {
 	}
 }
 
+// SetUpstreamConcurrency 更新上游并发限流的实时状态
+func (m *Metrics) SetUpstreamConcurrency(inFlight, queued int64) {
+	atomic.StoreInt64(&m.UpstreamInFlight, inFlight)
+	atomic.StoreInt64(&m.UpstreamQueued, queued)
+}
+
+// RecordUpstreamQueueTimeout 记录一次上游排队超时
+func (m *Metrics) RecordUpstreamQueueTimeout() {
+	atomic.AddInt64(&m.UpstreamTimeouts, 1)
+}
+
+// RecordSegmentCache 记录一次长文本请求的分段缓存命中情况
+func (m *Metrics) RecordSegmentCache(hits, misses int64) {
+	atomic.AddInt64(&m.SegmentHits, hits)
+	atomic.AddInt64(&m.SegmentMisses, misses)
+}
+
 // GetSnapshot 获取指标快照
 func (m *Metrics) GetSnapshot() MetricsSnapshot {
 	requests := atomic.LoadInt64(&m.TTSRequests)
@@ -84,6 +110,8 @@ func (m *Metrics) GetSnapshot() MetricsSnapshot {
 	totalLatency := atomic.LoadInt64(&m.TTSTotalLatency)
 	cacheHits := atomic.LoadInt64(&m.CacheHits)
 	cacheMisses := atomic.LoadInt64(&m.CacheMisses)
+	segmentHits := atomic.LoadInt64(&m.SegmentHits)
+	segmentMisses := atomic.LoadInt64(&m.SegmentMisses)
 	
 	m.mu.RLock()
 	maxLatency := m.TTSMaxLatency
@@ -106,6 +134,11 @@ func (m *Metrics) GetSnapshot() MetricsSnapshot {
 		cacheHitRate = float64(cacheHits) / float64(totalCacheOps) * 100
 	}
 	
+	segmentHitRate := 0.0
+	if total := segmentHits + segmentMisses; total > 0 {
+		segmentHitRate = float64(segmentHits) / float64(total) * 100
+	}
+	
 	successRate := 0.0
 	if requests > 0 {
 		successRate = float64(success) / float64(requests) * 100
@@ -125,6 +158,12 @@ func (m *Metrics) GetSnapshot() MetricsSnapshot {
 		CacheTotalSize:   atomic.LoadInt64(&m.CacheTotalSize),
 		WorkerPoolJobs:   atomic.LoadInt64(&m.WorkerPoolJobs),
 		WorkerPoolErrors: atomic.LoadInt64(&m.WorkerPoolErrors),
+		UpstreamInFlight: atomic.LoadInt64(&m.UpstreamInFlight),
+		UpstreamQueued:   atomic.LoadInt64(&m.UpstreamQueued),
+		UpstreamTimeouts: atomic.LoadInt64(&m.UpstreamTimeouts),
+		SegmentHits:      segmentHits,
+		SegmentMisses:    segmentMisses,
+		SegmentHitRate:   segmentHitRate,
 		Timestamp:        time.Now(),
 	}
 }
@@ -144,6 +183,12 @@ type MetricsSnapshot struct {
 	CacheTotalSize   int64         `json:"cache_total_size"`
 	WorkerPoolJobs   int64         `json:"worker_pool_jobs"`
 	WorkerPoolErrors int64         `json:"worker_pool_errors"`
+	UpstreamInFlight int64         `json:"upstream_in_flight"`
+	UpstreamQueued   int64         `json:"upstream_queued"`
+	UpstreamTimeouts int64         `json:"upstream_queue_timeouts"`
+	SegmentHits      int64         `json:"segment_cache_hits"`
+	SegmentMisses    int64         `json:"segment_cache_misses"`
+	SegmentHitRate   float64       `json:"segment_cache_hit_rate"`
 	Timestamp        time.Time     `json:"timestamp"`
 }
 
@@ -158,6 +203,10 @@ func (m *Metrics) Reset() {
 	atomic.StoreInt64(&m.CacheTotalSize, 0)
 	atomic.StoreInt64(&m.WorkerPoolJobs, 0)
 	atomic.StoreInt64(&m.WorkerPoolErrors, 0)
+	atomic.StoreInt64(&m.UpstreamTimeouts, 0)
+	atomic.StoreInt64(&m.SegmentHits, 0)
+	atomic.StoreInt64(&m.SegmentMisses, 0)
+	// UpstreamInFlight 和 UpstreamQueued 是实时状态，不随重置清零
 
 	m.mu.Lock()
 	m.TTSMaxLatency = 0
@@ -1,14 +1,96 @@
 package models
 
+import "io"
+
 // TTSRequest 表示一个语音合成请求
 type TTSRequest struct {
 	Text   string `json:"text,omitempty"`  // 要转换的文本
 	SSML   string `json:"ssml,omitempty"`  // 要转换的SSML
 	Voice  string `json:"voice"`           // 语音ID
-	Rate   string `json:"rate"`            // 语速 (-100% 到 +100%)
-	Pitch  string `json:"pitch"`           // 语调 (-100% 到 +100%)
+	Rate   string `json:"rate"`            // 语速：+20、20%、1.2x 或 fast 等级别，范围 -50% 到 +200%
+	Pitch  string `json:"pitch"`           // 语调：+10、1.1x、+2st、-50Hz 或 high 等级别，范围 ±50%
 	Style  string `json:"style"`           // 说话风格
 	Format string `json:"format,omitempty"` // 音频格式（可选，不指定则使用默认格式）
+
+	Volume      string  `json:"volume,omitempty"`      // 音量：+20、0.8x 或 loud 等级别，范围 ±100%
+	StyleDegree float64 `json:"styledegree,omitempty"` // 风格强度 (0.01 到 2)，需要指定 style
+	Role        string  `json:"role,omitempty"`        // 角色扮演：Girl、Boy、YoungAdultFemale、OlderAdultMale 等
+
+	// Spans 结构化请求：按片段指定语音、风格和韵律，由服务端编译为 SSML（与 text、ssml 互斥）
+	Spans []Span `json:"spans,omitempty"`
+
+	// 多角色剧本：每行形如 "[角色] 台词" 或 "角色：台词"，按角色表选择语音（与 text、ssml、spans 互斥）
+	Script    string                `json:"script,omitempty"`
+	Cast      map[string]CastMember `json:"cast,omitempty"`        // 角色表，覆盖配置中的同名角色
+	LineGapMs *int                  `json:"line_gap_ms,omitempty"` // 行间静音（毫秒），未指定时使用配置值，0 表示不插入静音
+
+	// 引号对白模式（仅 text）：引号内的对白使用单独的语音，旁白使用 voice
+	DialogueVoice       string `json:"dialogue_voice,omitempty"`        // 对白语音
+	DialogueMaleVoice   string `json:"dialogue_male_voice,omitempty"`   // 男性对白语音，与女声同时指定时按上下文交替
+	DialogueFemaleVoice string `json:"dialogue_female_voice,omitempty"` // 女性对白语音
+
+	AutoLanguage bool `json:"auto_language,omitempty"` // 自动检测文本中的语言，各语言片段使用对应语言的语音
+	Directives   bool `json:"directives,omitempty"`    // 解析文本中的行内指令（[pause:800ms] 等），也可由服务配置默认开启
+
+	Normalize string `json:"normalize,omitempty"` // 文本规范化模式：off、rewrite、say_as，为空时使用服务配置
+	RuleSet   string `json:"rule_set,omitempty"`  // 文本替换规则集，none 表示不替换，为空时按 API 密钥或配置选择
+	Markdown  string `json:"markdown,omitempty"`  // Markdown 处理方式：markdown、plain、off，为空时使用服务配置
+	Convert   string `json:"convert,omitempty"`   // 简繁转换：s2t、s2tw、s2twp、s2hk、t2s、tw2sp，auto 按语音的语言选择，none 不转换
+
+	// Lexicon 发音词典，由服务端按 API 密钥选择，文本在生成 SSML 时标注为 <phoneme>/<sub>
+	Lexicon Lexicon `json:"-" form:"-"`
+
+	BestEffort bool `json:"best_effort,omitempty"` // 长文本尽力模式：最终失败的分段以静音替代
+
+	// 分段参数（可选，未指定时使用服务配置）
+	SegmentStrategy  string `json:"segment_strategy,omitempty"`  // 分段策略：smart、fixed、paragraph、line、delimiter
+	SegmentDelimiter string `json:"segment_delimiter,omitempty"` // delimiter 策略使用的分隔符
+	SegmentLength    int    `json:"segment_length,omitempty"`    // 每个分段的长度上限
+	SegmentBudget    string `json:"segment_budget,omitempty"`    // 长度单位：runes（字符数）或 ssml_bytes（转义后字节数）
+}
+
+// Span 结构化请求中的一个片段，未指定的语音和韵律参数继承请求的默认值
+type Span struct {
+	Text        string  `json:"text,omitempty"`          // 片段文本（纯文本，由服务端转义）
+	Voice       string  `json:"voice,omitempty"`         // 语音ID
+	Style       string  `json:"style,omitempty"`         // 说话风格
+	StyleDegree float64 `json:"styledegree,omitempty"`   // 风格强度 (0.01 到 2)
+	Role        string  `json:"role,omitempty"`          // 角色扮演
+	Rate        string  `json:"rate,omitempty"`          // 语速，数字表示百分比
+	Pitch       string  `json:"pitch,omitempty"`         // 语调，数字表示百分比
+	Volume      string  `json:"volume,omitempty"`        // 音量，数字表示百分比
+	Break       string  `json:"break,omitempty"`         // 片段后的停顿：时长（如 500ms）或强度（如 strong）
+	Emphasis    string  `json:"emphasis,omitempty"`      // 强调级别：strong、moderate、reduced、none
+	SayAs       string  `json:"say_as,omitempty"`        // 读法（say-as interpret-as），如 cardinal、date、telephone
+	SayAsFormat string  `json:"say_as_format,omitempty"` // 读法格式（say-as format），如 ymd
+	Lang        string  `json:"lang,omitempty"`          // 片段语言（<lang xml:lang>），供多语言语音朗读其他语言
+	Phoneme     string  `json:"phoneme,omitempty"`       // 音标（<phoneme ph>），如 sapi 拼音 "chong 2 qing 4"
+	Alphabet    string  `json:"alphabet,omitempty"`      // 音标字母表：sapi、ipa、ups、x-sampa（默认 ipa）
+	Sub         string  `json:"sub,omitempty"`           // 替换读法（<sub alias>），片段文本按 sub 朗读
+}
+
+// Lexicon 发音词典，将片段中的词条标注为音标或替换读法，返回标注后的片段和匹配次数
+type Lexicon interface {
+	Apply(spans []Span) ([]Span, int)
+}
+
+// CastMember 剧本角色对应的语音参数，未指定的参数继承请求的默认值
+type CastMember struct {
+	Voice string `json:"voice"`
+	Style string `json:"style,omitempty"`
+	Rate  string `json:"rate,omitempty"`
+	Pitch string `json:"pitch,omitempty"`
+}
+
+// LineTiming 剧本中一行台词在合并音频中的时间位置
+type LineTiming struct {
+	Index       int    `json:"index"`
+	Speaker     string `json:"speaker"`
+	Voice       string `json:"voice"`
+	Text        string `json:"text,omitempty"`
+	StartMs     int64  `json:"start_ms"`
+	EndMs       int64  `json:"end_ms"`
+	Substituted bool   `json:"substituted,omitempty"` // 尽力模式下以静音替代
 }
 
 // TTSResponse 表示一个语音合成响应
@@ -16,6 +98,15 @@ type TTSResponse struct {
 	AudioContent []byte `json:"audio_content"` // 音频数据
 	ContentType  string `json:"content_type"`  // MIME类型
 	CacheHit     bool   `json:"cache_hit"`     // 是否命中缓存
+
+	SubstitutedSegments []int `json:"substituted_segments,omitempty"` // 尽力模式下以静音替代的分段索引
+
+	Lines []LineTiming `json:"lines,omitempty"` // 剧本模式下每行台词的时间清单
+
+	// AudioStream 流式音频数据（长文本合并结果），非空时 AudioContent 为空
+	// 调用方读取完毕后必须关闭，以清理临时文件
+	AudioStream io.ReadCloser `json:"-"`
+	AudioSize   int64         `json:"-"` // AudioStream 的字节数
 }
 
 // OpenAIRequest OpenAI TTS请求结构体
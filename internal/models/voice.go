@@ -10,5 +10,12 @@ type Voice struct {
 	Locale          string   `json:"Locale"`          // 语言区域, 如 zh-CN
 	LocaleName      string   `json:"LocaleName"`      // 语言区域显示名称，如 中文(中国)
 	StyleList       []string `json:"StyleList,omitempty"` // 支持的说话风格列表
+	RolePlayList    []string `json:"RolePlayList,omitempty"` // 支持的角色扮演列表
 	SampleRateHertz string   `json:"SampleRateHertz"` // 采样率
+
+	VoiceType           string              `json:"VoiceType,omitempty"`           // 语音类型，如 Neural
+	Status              string              `json:"Status,omitempty"`              // 发布状态：GA、Preview 等
+	WordsPerMinute      string              `json:"WordsPerMinute,omitempty"`      // 每分钟词数（语速参考值）
+	SecondaryLocaleList []string            `json:"SecondaryLocaleList,omitempty"` // 多语言语音可以朗读的其他语言区域
+	VoiceTag            map[string][]string `json:"VoiceTag,omitempty"`            // 标签，如 TailoredScenarios、VoicePersonalities
 }
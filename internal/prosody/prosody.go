@@ -0,0 +1,266 @@
+// Package prosody 解析和规范化语速、语调、音量、风格强度和角色等韵律参数
+//
+// 语速、语调和音量支持多种写法：
+//
+//	+20、-10、20%      相对默认值的百分比，不带单位的数字按百分比处理
+//	1.2x、0.8x         相对默认值的倍数，1.2x 即 +20%
+//	fast、x-low、loud  SSML 预定义的级别
+//	+2st、-50Hz        语调的半音数或频率变化（仅语调）
+//
+// 超出范围的数值被截断到范围之内，无法解析的值返回 ErrInvalidProsody。
+package prosody
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"tts/internal/models"
+)
+
+// ErrInvalidProsody 表示韵律参数无法解析或不受支持
+var ErrInvalidProsody = errors.New("invalid prosody")
+
+// 风格强度的范围
+const (
+	MinStyleDegree = 0.01
+	MaxStyleDegree = 2.0
+)
+
+// Unit 韵律参数的单位
+type Unit string
+
+const (
+	UnitDefault  Unit = ""        // 未指定
+	UnitKeyword  Unit = "keyword" // 预定义级别，如 fast
+	UnitPercent  Unit = "%"
+	UnitHz       Unit = "Hz"
+	UnitSemitone Unit = "st"
+)
+
+// Value 解析后的韵律参数
+type Value struct {
+	Unit    Unit
+	Number  float64 // 相对默认值的变化量，单位为 Unit
+	Keyword string  // Unit 为 UnitKeyword 时的级别
+	Clamped bool    // 数值超出范围，已截断
+}
+
+// String 返回 SSML 属性值，未指定时返回空字符串，零变化返回 +0%、+0Hz 或 +0st
+func (v Value) String() string {
+	switch v.Unit {
+	case UnitDefault:
+		return ""
+	case UnitKeyword:
+		return v.Keyword
+	}
+	sign := ""
+	if v.Number >= 0 {
+		sign = "+"
+	}
+	return sign + strconv.FormatFloat(v.Number, 'f', -1, 64) + string(v.Unit)
+}
+
+// IsDefault 是否未指定（零变化不是未指定，见 String）
+func (v Value) IsDefault() bool {
+	return v.Unit == UnitDefault
+}
+
+// bounds 数值的取值范围
+type bounds struct{ min, max float64 }
+
+// param 一种韵律参数支持的写法和范围
+type param struct {
+	name     string
+	keywords map[string]bool
+	units    map[Unit]bounds
+}
+
+var (
+	// notation 可选符号的数字和可选单位
+	notation = regexp.MustCompile(`^([+-]?)(\d+(?:\.\d+)?|\.\d+)\s*(%|x|hz|st)?$`)
+
+	rate = param{
+		name:     "rate",
+		keywords: keywordSet("x-slow", "slow", "medium", "fast", "x-fast"),
+		units:    map[Unit]bounds{UnitPercent: {-50, 200}}, // 0.5 倍到 3 倍
+	}
+	pitch = param{
+		name:     "pitch",
+		keywords: keywordSet("x-low", "low", "medium", "high", "x-high"),
+		units: map[Unit]bounds{
+			UnitPercent:  {-50, 50},
+			UnitHz:       {-200, 200},
+			UnitSemitone: {-12, 12},
+		},
+	}
+	volume = param{
+		name:     "volume",
+		keywords: keywordSet("silent", "x-soft", "soft", "medium", "loud", "x-loud"),
+		units:    map[Unit]bounds{UnitPercent: {-100, 100}},
+	}
+
+	// roles Azure 语音支持的角色扮演，按小写查找
+	roles = map[string]string{}
+)
+
+func init() {
+	for _, role := range []string{
+		"Girl", "Boy", "YoungAdultFemale", "YoungAdultMale",
+		"OlderAdultFemale", "OlderAdultMale", "SeniorFemale", "SeniorMale",
+	} {
+		roles[strings.ToLower(role)] = role
+	}
+}
+
+func keywordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+// ParseRate 解析语速，范围为 -50% 到 +200%（0.5 倍到 3 倍）
+func ParseRate(s string) (Value, error) {
+	return rate.parse(s)
+}
+
+// ParsePitch 解析语调，范围为 ±50%、±200Hz 或 ±12st
+func ParsePitch(s string) (Value, error) {
+	return pitch.parse(s)
+}
+
+// ParseVolume 解析音量，范围为 -100% 到 +100%
+func ParseVolume(s string) (Value, error) {
+	return volume.parse(s)
+}
+
+func (p param) parse(s string) (Value, error) {
+	text := strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case text == "":
+		return Value{}, nil
+	case text == "default" || text == "normal":
+		return Value{Unit: UnitPercent}, nil
+	case p.keywords[text]:
+		return Value{Unit: UnitKeyword, Keyword: text}, nil
+	}
+
+	m := notation.FindStringSubmatch(text)
+	if m == nil {
+		return Value{}, fmt.Errorf("%w: %s %q", ErrInvalidProsody, p.name, s)
+	}
+	number, _ := strconv.ParseFloat(m[2], 64)
+	unit := Unit(m[3])
+	switch m[3] {
+	case "", "%":
+		unit = UnitPercent
+	case "x":
+		if m[1] != "" || number == 0 {
+			return Value{}, fmt.Errorf("%w: %s multiplier must be positive, got %q", ErrInvalidProsody, p.name, s)
+		}
+		unit, number = UnitPercent, (number-1)*100
+	case "hz":
+		unit = UnitHz
+	}
+	if m[1] == "-" {
+		number = -number
+	}
+
+	limit, ok := p.units[unit]
+	if !ok {
+		return Value{}, fmt.Errorf("%w: %s does not support unit %s", ErrInvalidProsody, p.name, unit)
+	}
+	number = math.Round(number*100) / 100
+	if number == 0 {
+		number = 0 // 去掉 -0 的符号
+	}
+	v := Value{Unit: unit, Number: number}
+	if number < limit.min || number > limit.max {
+		v.Number, v.Clamped = math.Max(limit.min, math.Min(limit.max, number)), true
+	}
+	return v, nil
+}
+
+// Attrs 返回 <prosody> 的属性（带前导空格），省略未指定和零变化的参数（SSML 中不写即为默认值），
+// 都省略时返回空字符串
+func Attrs(rateValue, pitchValue, volumeValue string) (string, error) {
+	var attrs strings.Builder
+	for _, a := range []struct {
+		p     param
+		value string
+	}{{rate, rateValue}, {pitch, pitchValue}, {volume, volumeValue}} {
+		v, err := a.p.parse(a.value)
+		if err != nil {
+			return "", err
+		}
+		if !v.IsDefault() && !(v.Unit != UnitKeyword && v.Number == 0) {
+			attrs.WriteString(" " + a.p.name + `="` + v.String() + `"`)
+		}
+	}
+	return attrs.String(), nil
+}
+
+// ParseRole 解析角色扮演（忽略大小写），空字符串和 default 返回空字符串
+func ParseRole(s string) (string, error) {
+	key := strings.ToLower(strings.TrimSpace(s))
+	if key == "" || key == "default" {
+		return "", nil
+	}
+	role, ok := roles[key]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown role %q", ErrInvalidProsody, s)
+	}
+	return role, nil
+}
+
+// CheckStyleDegree 检查风格强度，0 表示默认值
+func CheckStyleDegree(degree float64) error {
+	if degree != 0 && (degree < MinStyleDegree || degree > MaxStyleDegree) {
+		return fmt.Errorf("%w: styledegree must be between %g and %g", ErrInvalidProsody, MinStyleDegree, MaxStyleDegree)
+	}
+	return nil
+}
+
+// Normalize 将请求的语速、语调、音量改写为 SSML 属性值。零变化（如 0、1.0x、normal）
+// 改写为 +0% 等显式的值，只有未指定的参数保持空字符串，由客户端使用配置的默认值；
+// 检查风格强度和角色。返回被截断的参数名。
+func Normalize(req *models.TTSRequest) ([]string, error) {
+	var clamped []string
+	for _, f := range []struct {
+		p     param
+		value *string
+	}{{rate, &req.Rate}, {pitch, &req.Pitch}, {volume, &req.Volume}} {
+		v, err := f.p.parse(*f.value)
+		if err != nil {
+			return nil, err
+		}
+		if v.Clamped {
+			clamped = append(clamped, f.p.name)
+		}
+		*f.value = v.String()
+	}
+
+	if err := CheckStyleDegree(req.StyleDegree); err != nil {
+		return nil, err
+	}
+	role, err := ParseRole(req.Role)
+	if err != nil {
+		return nil, err
+	}
+	req.Role = role
+	return clamped, nil
+}
+
+// FromMultiplier 将倍数（如 OpenAI 接口的 speed）转换为语速，超出范围时截断
+func FromMultiplier(multiplier float64) string {
+	if multiplier <= 0 {
+		return ""
+	}
+	v, _ := rate.parse(strconv.FormatFloat(multiplier, 'f', -1, 64) + "x")
+	return v.String()
+}
@@ -0,0 +1,134 @@
+package prosody
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"tts/internal/models"
+)
+
+// TestParse 测试各种写法解析为 SSML 属性值
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func(string) (Value, error)
+		in      string
+		want    string
+		clamped bool
+	}{
+		{"空值", ParseRate, "", "", false},
+		{"零", ParseRate, "0", "+0%", false},
+		{"负零", ParseRate, "-0", "+0%", false},
+		{"一倍", ParseRate, "1.0x", "+0%", false},
+		{"零百分比", ParseRate, "+0%", "+0%", false},
+		{"零频率", ParsePitch, "0Hz", "+0Hz", false},
+		{"default", ParseRate, "default", "+0%", false},
+		{"normal", ParseVolume, "normal", "+0%", false},
+		{"数字按百分比", ParseRate, "10", "+10%", false},
+		{"百分比", ParseRate, "-20%", "-20%", false},
+		{"带加号百分比", ParseRate, "+20%", "+20%", false},
+		{"小数", ParseRate, "12.5", "+12.5%", false},
+		{"倍数", ParseRate, "1.2x", "+20%", false},
+		{"减速倍数", ParseRate, "0.75X", "-25%", false},
+		{"级别", ParseRate, "Fast", "fast", false},
+		{"语速下限", ParseRate, "-150", "-50%", true},
+		{"语速上限", ParseRate, "5x", "+200%", true},
+		{"半音", ParsePitch, "+2st", "+2st", false},
+		{"频率", ParsePitch, "-50Hz", "-50Hz", false},
+		{"语调级别", ParsePitch, "x-high", "x-high", false},
+		{"语调上限", ParsePitch, "+80", "+50%", true},
+		{"半音上限", ParsePitch, "-24st", "-12st", true},
+		{"音量", ParseVolume, "+30", "+30%", false},
+		{"音量级别", ParseVolume, "loud", "loud", false},
+		{"音量下限", ParseVolume, "-300%", "-100%", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := tt.parse(tt.in)
+			if err != nil {
+				t.Fatalf("parse(%q) error = %v", tt.in, err)
+			}
+			if got := v.String(); got != tt.want || v.Clamped != tt.clamped {
+				t.Errorf("parse(%q) = %q (clamped %v), want %q (clamped %v)", tt.in, got, v.Clamped, tt.want, tt.clamped)
+			}
+		})
+	}
+}
+
+// TestParse_Invalid 测试无法解析的值返回 ErrInvalidProsody
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(string) (Value, error)
+		in    string
+	}{
+		{"注入属性", ParseRate, `fast" onload="x`},
+		{"未知级别", ParseRate, "very-fast"},
+		{"语速不支持半音", ParseRate, "+2st"},
+		{"音量不支持频率", ParseVolume, "10Hz"},
+		{"语调级别用于语速", ParseRate, "high"},
+		{"负倍数", ParseRate, "-1.2x"},
+		{"零倍数", ParsePitch, "0x"},
+		{"多个单位", ParsePitch, "10%st"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.parse(tt.in); !errors.Is(err, ErrInvalidProsody) {
+				t.Errorf("parse(%q) error = %v, want ErrInvalidProsody", tt.in, err)
+			}
+		})
+	}
+}
+
+// TestNormalize 测试请求参数的规范化、截断和检查
+func TestNormalize(t *testing.T) {
+	req := models.TTSRequest{Rate: "1.5x", Pitch: "-150", Volume: "0", Style: "cheerful", StyleDegree: 1.5, Role: "girl"}
+	clamped, err := Normalize(&req)
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	want := models.TTSRequest{Rate: "+50%", Pitch: "-50%", Volume: "+0%", Style: "cheerful", StyleDegree: 1.5, Role: "Girl"}
+	if !reflect.DeepEqual(req, want) {
+		t.Errorf("Normalize() = %+v, want %+v", req, want)
+	}
+	if !reflect.DeepEqual(clamped, []string{"pitch"}) {
+		t.Errorf("clamped = %v, want [pitch]", clamped)
+	}
+
+	// 未指定的参数保持空字符串，由客户端使用配置的默认值
+	unset := models.TTSRequest{Rate: "normal"}
+	if _, err := Normalize(&unset); err != nil || unset.Rate != "+0%" || unset.Pitch != "" || unset.Volume != "" {
+		t.Errorf("Normalize() = %+v, %v, want rate +0%% and empty pitch and volume", unset, err)
+	}
+
+	for _, bad := range []models.TTSRequest{
+		{Rate: "fast!"},
+		{Style: "sad", StyleDegree: 3},
+		{Role: "Narrator"},
+	} {
+		if _, err := Normalize(&bad); !errors.Is(err, ErrInvalidProsody) {
+			t.Errorf("Normalize(%+v) error = %v, want ErrInvalidProsody", bad, err)
+		}
+	}
+}
+
+// TestFromMultiplier 测试倍数转换为语速
+func TestFromMultiplier(t *testing.T) {
+	for in, want := range map[float64]string{1: "+0%", 1.25: "+25%", 0.5: "-50%", 0.25: "-50%", 4: "+200%", 0: ""} {
+		if got := FromMultiplier(in); got != want {
+			t.Errorf("FromMultiplier(%v) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// TestAttrs 测试 <prosody> 属性省略未指定和零变化的参数
+func TestAttrs(t *testing.T) {
+	got, err := Attrs("+0%", "+2st", "")
+	if err != nil || got != ` pitch="+2st"` {
+		t.Errorf("Attrs() = %q, %v, want %q", got, err, ` pitch="+2st"`)
+	}
+	if got, _ := Attrs("normal", "0Hz", "+0%"); got != "" {
+		t.Errorf("Attrs() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,324 @@
+package scripting
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/ast"
+	"github.com/yuin/gopher-lua/pm"
+)
+
+// 字符串分配的限制。gopher-lua 不能限制内存，.. 运算和会生成字符串的库函数
+// 在分配前检查结果长度，并按脚本累计生成的字符串总量
+const (
+	maxStringBytes = 1 << 20  // 单个字符串的上限
+	maxAllocBytes  = 32 << 20 // 一个脚本一次运行中生成的字符串总量上限
+)
+
+// concatName 编译时 .. 运算改写为对该局部变量的调用，运行时作为主代码块的参数传入
+const concatName = "__tts_concat"
+
+// formatWidth 宽度或精度超过两位数的格式（与 Lua 5.1 一致），避免 %999999s 生成超长字符串
+var formatWidth = regexp.MustCompile(`(?:^|[^%])(?:%%)*%[-+ #0]*(?:\d{3,}|\d*\.\d{3,})`)
+
+// rewriteConcat 把代码块中的 .. 运算改写为 __tts_concat(lhs, rhs)，并在开头声明
+// local __tts_concat = ...。脚本中同名的局部变量只能遮蔽它，函数体内的 .. 仍调用受限的版本
+func rewriteConcat(chunk []ast.Stmt) []ast.Stmt {
+	rewriteExprs(reflect.ValueOf(chunk))
+	declare := &ast.LocalAssignStmt{Names: []string{concatName}, Exprs: []ast.Expr{&ast.Comma3Expr{}}}
+	return append([]ast.Stmt{declare}, chunk...)
+}
+
+// rewriteExprs 遍历语法树，替换所有 ast.Expr 位置上的 *ast.StringConcatOpExpr
+func rewriteExprs(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			rewriteExprs(v.Elem())
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		rewriteExprs(v.Elem())
+		if concat, ok := v.Interface().(*ast.StringConcatOpExpr); ok && v.CanSet() {
+			call := &ast.FuncCallExpr{
+				Func:      &ast.IdentExpr{Value: concatName},
+				Args:      []ast.Expr{concat.Lhs, concat.Rhs},
+				AdjustRet: true,
+			}
+			call.SetLine(concat.Line())
+			call.SetLastLine(concat.LastLine())
+			v.Set(reflect.ValueOf(call))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			rewriteExprs(v.Field(i))
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			rewriteExprs(v.Index(i))
+		}
+	}
+}
+
+// allocBudget 一个脚本一次运行中生成的字符串总量
+type allocBudget struct {
+	used int
+}
+
+// charge 记录新生成的字符串，超过单个字符串或总量的上限时报错；同时检查是否已超时，
+// 避免脚本在库函数中继续运行
+func (b *allocBudget) charge(L *lua.LState, n int) {
+	if n > maxStringBytes {
+		L.RaiseError("string exceeds %d bytes", maxStringBytes)
+	}
+	b.used += n
+	if b.used > maxAllocBytes {
+		L.RaiseError("script allocated more than %d bytes of strings", maxAllocBytes)
+	}
+	checkContext(L)
+}
+
+func checkContext(L *lua.LState) {
+	if ctx := L.Context(); ctx != nil && ctx.Err() != nil {
+		L.RaiseError("%v", ctx.Err())
+	}
+}
+
+// concat 受限的 .. 运算：字符串和数字在拼接前检查长度，其他值按 __concat 元方法处理
+func (b *allocBudget) concat(L *lua.LState) int {
+	lhs, rhs := L.Get(1), L.Get(2)
+	if !lua.LVCanConvToString(lhs) || !lua.LVCanConvToString(rhs) {
+		op := L.GetMetaField(lhs, "__concat")
+		if op == lua.LNil {
+			op = L.GetMetaField(rhs, "__concat")
+		}
+		if op == lua.LNil {
+			bad := lhs
+			if lua.LVCanConvToString(lhs) {
+				bad = rhs
+			}
+			L.RaiseError("attempt to concatenate a %s value", bad.Type().String())
+		}
+		L.Push(op)
+		L.Push(lhs)
+		L.Push(rhs)
+		L.Call(2, 1)
+		return 1
+	}
+	ls, rs := lua.LVAsString(lhs), lua.LVAsString(rhs)
+	b.charge(L, len(ls)+len(rs))
+	L.Push(lua.LString(ls + rs))
+	return 1
+}
+
+// meter 包装库函数：调用前检查是否已超时，返回的字符串计入总量
+func (b *allocBudget) meter(L *lua.LState, fn *lua.LFunction) *lua.LFunction {
+	return L.NewFunction(func(L *lua.LState) int {
+		checkContext(L)
+		top := L.GetTop()
+		n := callWithArgs(L, fn, top)
+		for i := top + 1; i <= L.GetTop(); i++ {
+			if s, ok := L.Get(i).(lua.LString); ok {
+				b.charge(L, len(s))
+			}
+		}
+		return n
+	})
+}
+
+// callWithArgs 用当前栈上的 top 个参数调用 fn，返回结果数量（结果位于参数之后）
+func callWithArgs(L *lua.LState, fn *lua.LFunction, top int) int {
+	L.Push(fn)
+	for i := 1; i <= top; i++ {
+		L.Push(L.Get(i))
+	}
+	L.Call(top, lua.MultRet)
+	return L.GetTop() - top
+}
+
+// limitLibraries 为 string 和 table 库中会生成字符串的函数加上长度检查，并计量所有函数返回的字符串
+func (b *allocBudget) limitLibraries(L *lua.LState) {
+	limits := map[string]map[string]func(fn *lua.LFunction) lua.LGFunction{
+		lua.StringLibName: {"rep": precheck(limitedRep), "format": precheck(limitedFormat), "gsub": limitedGsub},
+		lua.TabLibName:    {"concat": precheck(limitedTableConcat)},
+	}
+	for lib, checks := range limits {
+		table, ok := L.GetGlobal(lib).(*lua.LTable)
+		if !ok {
+			continue
+		}
+		functions := make(map[string]*lua.LFunction)
+		table.ForEach(func(key, value lua.LValue) {
+			if fn, ok := value.(*lua.LFunction); ok {
+				functions[lua.LVAsString(key)] = fn
+			}
+		})
+		for name, fn := range functions {
+			if limit, ok := checks[name]; ok {
+				fn = L.NewFunction(limit(fn))
+			}
+			table.RawSetString(name, b.meter(L, fn))
+		}
+	}
+}
+
+// precheck 先运行检查函数（检查失败时报错），再调用原函数
+func precheck(check lua.LGFunction) func(fn *lua.LFunction) lua.LGFunction {
+	return func(fn *lua.LFunction) lua.LGFunction {
+		return func(L *lua.LState) int {
+			check(L)
+			return callWithArgs(L, fn, L.GetTop())
+		}
+	}
+}
+
+// limitedRep 检查 string.rep 的结果长度
+func limitedRep(L *lua.LState) int {
+	str := L.CheckString(1)
+	n := L.CheckInt(2)
+	if len(str) > 0 && n > maxStringBytes/len(str) {
+		L.RaiseError("string.rep result exceeds %d bytes", maxStringBytes)
+	}
+	return 0
+}
+
+// limitedFormat 检查 string.format 的格式宽度和结果长度的上界
+func limitedFormat(L *lua.LState) int {
+	format := L.CheckString(1)
+	if formatWidth.MatchString(format) {
+		L.RaiseError("invalid format (width or precision too long)")
+	}
+	size := len(format)
+	for i := 2; i <= L.GetTop(); i++ {
+		if v := L.Get(i); lua.LVCanConvToString(v) {
+			size += len(lua.LVAsString(v))
+		} else {
+			size += 32 // table: 0x...、true 等
+		}
+	}
+	if size > maxStringBytes {
+		L.RaiseError("string.format result exceeds %d bytes", maxStringBytes)
+	}
+	return 0
+}
+
+// limitedTableConcat 检查 table.concat 的结果长度
+func limitedTableConcat(L *lua.LState) int {
+	tbl := L.CheckTable(1)
+	sep := L.OptString(2, "")
+	i := max(L.OptInt(3, 1), 1)
+	j := min(L.OptInt(4, tbl.Len()), tbl.Len())
+	size := 0
+	for k := i; k <= j; k++ {
+		if v := tbl.RawGetInt(k); lua.LVCanConvToString(v) {
+			size += len(lua.LVAsString(v))
+		}
+		if k < j {
+			size += len(sep)
+		}
+		if size > maxStringBytes {
+			L.RaiseError("table.concat result exceeds %d bytes", maxStringBytes)
+		}
+	}
+	return 0
+}
+
+// limitedGsub 限制 string.gsub 的结果长度。替换字符串时按匹配结果逐段生成结果
+// （原实现每次替换都复制整个字符串），超出上限时立即报错；
+// 替换表和替换函数改为包装函数，累计返回值的长度
+func limitedGsub(fn *lua.LFunction) lua.LGFunction {
+	return func(L *lua.LState) int {
+		str := L.CheckString(1)
+		pattern := L.CheckString(2)
+		switch repl := L.Get(3).(type) {
+		case lua.LString:
+			matches, err := pm.Find(pattern, []byte(str), 0, L.OptInt(4, -1))
+			if err != nil {
+				break // 由原函数报告模式错误
+			}
+			var b strings.Builder
+			prev := 0
+			for _, m := range matches {
+				b.WriteString(str[prev:m.Capture(0)])
+				expandReplacement(L, &b, string(repl), m, str)
+				prev = m.Capture(1)
+				if b.Len() > maxStringBytes {
+					L.RaiseError("string.gsub result exceeds %d bytes", maxStringBytes)
+				}
+			}
+			b.WriteString(str[prev:])
+			L.Push(lua.LString(b.String()))
+			L.Push(lua.LNumber(len(matches)))
+			return 2
+		case *lua.LTable:
+			L.Replace(3, limitedReplacement(L, len(str), func(L *lua.LState) lua.LValue {
+				return L.GetTable(repl, L.Get(1))
+			}))
+		case *lua.LFunction:
+			L.Replace(3, limitedReplacement(L, len(str), func(L *lua.LState) lua.LValue {
+				top := L.GetTop()
+				L.Push(repl)
+				for i := 1; i <= top; i++ {
+					L.Push(L.Get(i))
+				}
+				L.Call(top, 1)
+				return L.Get(-1)
+			}))
+		}
+		return callWithArgs(L, fn, L.GetTop())
+	}
+}
+
+// limitedReplacement 包装 gsub 的替换表或替换函数，替换结果的总长度超出上限时报错
+func limitedReplacement(L *lua.LState, base int, lookup func(L *lua.LState) lua.LValue) *lua.LFunction {
+	size := base
+	return L.NewFunction(func(L *lua.LState) int {
+		v := lookup(L)
+		if lua.LVCanConvToString(v) {
+			size += len(lua.LVAsString(v))
+			if size > maxStringBytes {
+				L.RaiseError("string.gsub result exceeds %d bytes", maxStringBytes)
+			}
+		}
+		L.Push(v)
+		return 1
+	})
+}
+
+// expandReplacement 写入一次匹配的替换结果，与 gopher-lua 的 gsub 相同：
+// %0-%9 为捕获（没有捕获时 %1 为整个匹配），%% 为 %，其他 % 原样保留
+func expandReplacement(L *lua.LState, b *strings.Builder, repl string, m *pm.MatchData, str string) {
+	for i := 0; i < len(repl); i++ {
+		c := repl[i]
+		if c != '%' || i == len(repl)-1 {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch c = repl[i]; {
+		case c == '%':
+			b.WriteByte('%')
+		case c >= '0' && c <= '9':
+			idx := 2 * int(c-'0')
+			if idx >= m.CaptureLength() {
+				if idx != 2 {
+					L.RaiseError("invalid capture index")
+				}
+				idx = 0
+			}
+			if m.IsPosCapture(idx) {
+				b.WriteString(strconv.Itoa(m.Capture(idx)))
+			} else {
+				b.WriteString(str[m.Capture(idx):m.Capture(idx+1)])
+			}
+		default:
+			b.WriteByte('%')
+			b.WriteByte(c)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+// Package scripting 在合成前运行用户编写的 Lua 脚本，修改或拒绝请求
+package scripting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+
+	"tts/internal/models"
+)
+
+var (
+	// ErrInvalidScript 表示脚本无法编译
+	ErrInvalidScript = errors.New("invalid script")
+	// ErrRejected 表示脚本调用 reject() 拒绝了请求
+	ErrRejected = errors.New("request rejected by script")
+	// ErrScriptFailed 表示脚本运行出错或超时（仅在 on_error 为 reject 时返回）
+	ErrScriptFailed = errors.New("script failed")
+)
+
+// 脚本出错时的处理方式
+const (
+	OnErrorSkip   = "skip"   // 丢弃出错脚本的修改，继续处理请求
+	OnErrorReject = "reject" // 请求失败
+)
+
+// 沙箱的固定限制，字符串的限制见 limits.go
+const (
+	registrySize    = 1024
+	registryMaxSize = 256 * 1024 // 值栈上限，超过时脚本报错
+)
+
+// Options 脚本运行限制
+type Options struct {
+	Timeout      time.Duration // 每个请求执行全部脚本的时间上限，超时的脚本在下一条指令处中断
+	MaxCallDepth int           // 调用栈深度上限
+	OnError      string        // 脚本出错或超时：skip 或 reject
+}
+
+// Info 请求的只读信息，脚本中通过 request.api_key、request.endpoint 读取
+type Info struct {
+	APIKey   string
+	Endpoint string
+}
+
+// script 编译后的脚本
+type script struct {
+	name  string
+	proto *lua.FunctionProto
+}
+
+// Store 加载脚本目录中的 *.lua 脚本，按文件名顺序对每个请求执行
+//
+// Watch 定期检查目录，脚本增删或修改后重新编译；编译失败时保留当前脚本。dir 为空时不执行任何脚本。
+type Store struct {
+	dir    string
+	opts   Options
+	logger zerolog.Logger
+
+	mu        sync.RWMutex
+	scripts   []script
+	signature string
+}
+
+// NewStore 创建脚本存储并编译目录中的脚本，目录不存在时从空脚本开始
+func NewStore(dir string, opts Options, logger zerolog.Logger) (*Store, error) {
+	switch opts.OnError {
+	case "":
+		opts.OnError = OnErrorSkip
+	case OnErrorSkip, OnErrorReject:
+	default:
+		return nil, fmt.Errorf("%w: unknown on_error %q", ErrInvalidScript, opts.OnError)
+	}
+	s := &Store{dir: dir, opts: opts, logger: logger}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Names 返回已加载的脚本文件名（按执行顺序）
+func (s *Store) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, len(s.scripts))
+	for i, sc := range s.scripts {
+		names[i] = sc.name
+	}
+	return names
+}
+
+// Reload 目录中的脚本变化时重新编译，返回是否重新加载
+func (s *Store) Reload() (bool, error) {
+	if s.dir == "" {
+		return false, nil
+	}
+	files, signature, err := s.list()
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if signature == s.signature {
+		return false, nil
+	}
+	scripts := make([]script, 0, len(files))
+	for _, name := range files {
+		proto, err := compile(filepath.Join(s.dir, name))
+		if err != nil {
+			return false, err
+		}
+		scripts = append(scripts, script{name: name, proto: proto})
+	}
+	s.scripts, s.signature = scripts, signature
+	return true, nil
+}
+
+// Watch 每隔 interval 检查脚本目录，脚本变化时重新加载；加载失败时保留当前脚本。
+// 返回的函数停止检查。
+func (s *Store) Watch(interval time.Duration) func() {
+	if s.dir == "" || interval <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				reloaded, err := s.Reload()
+				if err != nil {
+					s.logger.Error().Err(err).Str("dir", s.dir).Msg("Failed to reload scripts")
+				} else if reloaded {
+					s.logger.Info().Str("dir", s.dir).Strs("scripts", s.Names()).Msg("Scripts reloaded")
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// list 返回按文件名排序的脚本和由文件名、大小、修改时间组成的目录签名
+func (s *Store) list() ([]string, string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("read scripts: %w", err)
+	}
+
+	var files []string
+	var signature strings.Builder
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".lua" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, "", fmt.Errorf("read scripts: %w", err)
+		}
+		files = append(files, entry.Name())
+		fmt.Fprintf(&signature, "%s:%d:%d;", entry.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+	sort.Strings(files)
+	return files, signature.String(), nil
+}
+
+// compile 编译脚本文件
+func compile(path string) (*lua.FunctionProto, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read script: %w", err)
+	}
+	name := filepath.Base(path)
+	chunk, err := parse.Parse(bytes.NewReader(raw), name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidScript, err)
+	}
+	proto, err := lua.Compile(rewriteConcat(chunk), name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidScript, name, err)
+	}
+	return proto, nil
+}
+
+// Run 依次执行脚本，脚本修改 request 表中的字段后写回请求。
+// 脚本调用 reject() 时返回 ErrRejected；脚本出错或超时时按 on_error 跳过该脚本或返回 ErrScriptFailed。
+func (s *Store) Run(ctx context.Context, req *models.TTSRequest, info Info) error {
+	s.mu.RLock()
+	scripts := s.scripts
+	s.mu.RUnlock()
+	if len(scripts) == 0 {
+		return nil
+	}
+
+	if s.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.opts.Timeout)
+		defer cancel()
+	}
+
+	fields := fieldsOf(req)
+	for _, sc := range scripts {
+		start := time.Now()
+		next, err := s.runScript(ctx, sc, fields, info)
+		if errors.Is(err, ErrRejected) {
+			return err
+		}
+		if err != nil {
+			// 超时说明脚本本身有问题（死循环、过慢），按错误记录
+			event := s.logger.Warn()
+			if ctx.Err() != nil {
+				event = s.logger.Error()
+			}
+			event = event.Err(err).Str("script", sc.name).Dur("elapsed", time.Since(start)).Str("on_error", s.opts.OnError)
+			if s.opts.OnError == OnErrorReject {
+				event.Msg("Script failed, request rejected")
+				return fmt.Errorf("%w: %s: %v", ErrScriptFailed, sc.name, err)
+			}
+			event.Msg("Script failed, changes discarded")
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		fields = next
+	}
+	fields.apply(req)
+	return nil
+}
+
+// runScript 在新的沙箱中执行一个脚本，返回脚本修改后的字段
+func (s *Store) runScript(ctx context.Context, sc script, fields requestFields, info Info) (requestFields, error) {
+	budget := &allocBudget{}
+	L := newSandbox(s.opts.MaxCallDepth, budget)
+	defer L.Close()
+	L.SetContext(ctx)
+
+	var rejected string
+	isRejected := false
+	L.SetGlobal("reject", L.NewFunction(func(L *lua.LState) int {
+		rejected, isRejected = L.OptString(1, "rejected"), true
+		L.RaiseError("%s", rejected)
+		return 0
+	}))
+	L.SetGlobal("log", L.NewFunction(func(L *lua.LState) int {
+		s.logger.Info().Str("script", sc.name).Msg(L.CheckString(1))
+		return 0
+	}))
+
+	table := fields.table(L, info)
+	L.SetGlobal("request", table)
+
+	// 编译时 .. 运算改写为调用主代码块的第一个参数
+	L.Push(L.NewFunctionFromProto(sc.proto))
+	L.Push(L.NewFunction(budget.concat))
+	err := L.PCall(1, lua.MultRet, nil)
+	if isRejected {
+		return fields, fmt.Errorf("%w: %s", ErrRejected, rejected)
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fields, ctxErr
+	}
+	if err != nil {
+		return fields, err
+	}
+	// 脚本可能把 request 换成新的表
+	if t, ok := L.GetGlobal("request").(*lua.LTable); ok {
+		table = t
+	}
+	return readFields(table)
+}
+
+// newSandbox 创建只包含 base、table、string、math 库的 Lua 状态，
+// 去掉加载文件和代码、操作全局环境的函数，string 和 table 库生成的字符串计入 budget
+func newSandbox(maxCallDepth int, budget *allocBudget) *lua.LState {
+	L := lua.NewState(lua.Options{
+		SkipOpenLibs:    true,
+		CallStackSize:   maxCallDepth,
+		RegistrySize:    registrySize,
+		RegistryMaxSize: registryMaxSize,
+	})
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range []string{"dofile", "loadfile", "load", "loadstring", "require", "module", "collectgarbage", "getfenv", "setfenv", "newproxy", "print", "_printregs"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+	if str, ok := L.GetGlobal(lua.StringLibName).(*lua.LTable); ok {
+		str.RawSetString("dump", lua.LNil)
+	}
+	budget.limitLibraries(L)
+	return L
+}
+
+// requestFields 脚本可以修改的请求字段
+type requestFields struct {
+	Text, SSML, Script, Voice, Style, Rate, Pitch, Format string
+}
+
+// fieldNames 字段在 request 表中的名称
+var fieldNames = []string{"text", "ssml", "script", "voice", "style", "rate", "pitch", "format"}
+
+func fieldsOf(req *models.TTSRequest) requestFields {
+	return requestFields{req.Text, req.SSML, req.Script, req.Voice, req.Style, req.Rate, req.Pitch, req.Format}
+}
+
+func (f *requestFields) pointers() []*string {
+	return []*string{&f.Text, &f.SSML, &f.Script, &f.Voice, &f.Style, &f.Rate, &f.Pitch, &f.Format}
+}
+
+// apply 把字段写回请求
+func (f requestFields) apply(req *models.TTSRequest) {
+	req.Text, req.SSML, req.Script = f.Text, f.SSML, f.Script
+	req.Voice, req.Style, req.Rate, req.Pitch, req.Format = f.Voice, f.Style, f.Rate, f.Pitch, f.Format
+}
+
+// table 生成脚本中的 request 表
+func (f requestFields) table(L *lua.LState, info Info) *lua.LTable {
+	t := L.NewTable()
+	for i, p := range f.pointers() {
+		t.RawSetString(fieldNames[i], lua.LString(*p))
+	}
+	t.RawSetString("api_key", lua.LString(info.APIKey))
+	t.RawSetString("endpoint", lua.LString(info.Endpoint))
+	return t
+}
+
+// readFields 读取脚本修改后的字段，nil 表示清空，其他非字符串的值返回错误
+func readFields(t *lua.LTable) (requestFields, error) {
+	var f requestFields
+	for i, p := range f.pointers() {
+		switch v := t.RawGetString(fieldNames[i]).(type) {
+		case *lua.LNilType:
+		case lua.LString:
+			*p = string(v)
+		case lua.LNumber:
+			*p = v.String()
+		default:
+			return f, fmt.Errorf("request.%s must be a string, got %s", fieldNames[i], v.Type())
+		}
+	}
+	return f, nil
+}
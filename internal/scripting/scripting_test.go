@@ -0,0 +1,184 @@
+package scripting
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"tts/internal/models"
+)
+
+func writeScript(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestStore(t *testing.T, dir string, onError string) *Store {
+	t.Helper()
+	s, err := NewStore(dir, Options{Timeout: 200 * time.Millisecond, OnError: onError}, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	return s
+}
+
+// TestStore_Run 测试脚本按文件名顺序修改请求，并能读取只读信息
+func TestStore_Run(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "10-voice.lua", `
+if request.api_key == "team-a" and request.endpoint == "TTS POST" then
+  request.voice = "zh-CN-YunxiNeural"
+  request.style = "cheerful"
+end`)
+	writeScript(t, dir, "20-text.lua", `request.text = request.text:gsub("AI", "人工智能") .. "。"`)
+	writeScript(t, dir, "notes.txt", `request.text = "ignored"`)
+	s := newTestStore(t, dir, "")
+
+	if got, want := s.Names(), []string{"10-voice.lua", "20-text.lua"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+
+	req := models.TTSRequest{Text: "AI 朗读", Voice: "zh-CN-XiaoxiaoNeural", Rate: "0"}
+	if err := s.Run(context.Background(), &req, Info{APIKey: "team-a", Endpoint: "TTS POST"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := models.TTSRequest{Text: "人工智能 朗读。", Voice: "zh-CN-YunxiNeural", Style: "cheerful", Rate: "0"}
+	if !reflect.DeepEqual(req, want) {
+		t.Errorf("Run() = %+v, want %+v", req, want)
+	}
+}
+
+// TestStore_Concat 测试改写后的 .. 运算：数字、链式拼接和 __concat 元方法
+func TestStore_Concat(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "concat.lua", `
+local tag = setmetatable({}, {__concat = function(a, b) return "[" .. tostring(a) .. "]" end})
+request.text = request.text .. 1 .. "/" .. 2.5 .. (3 .. tag)`)
+	s := newTestStore(t, dir, OnErrorReject)
+
+	req := models.TTSRequest{Text: "第"}
+	if err := s.Run(context.Background(), &req, Info{}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if req.Text != "第1/2.5[3]" {
+		t.Errorf("Text = %q, want %q", req.Text, "第1/2.5[3]")
+	}
+}
+
+// TestStore_Reject 测试脚本拒绝请求
+func TestStore_Reject(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "reject.lua", `
+if request.text:find("广告") then
+  reject("contains ads")
+end`)
+	s := newTestStore(t, dir, "")
+
+	req := models.TTSRequest{Text: "这是广告"}
+	err := s.Run(context.Background(), &req, Info{})
+	if !errors.Is(err, ErrRejected) {
+		t.Fatalf("期望 ErrRejected, got %v", err)
+	}
+	if err := s.Run(context.Background(), &models.TTSRequest{Text: "正文"}, Info{}); err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+}
+
+// TestStore_Sandbox 测试沙箱：超时中断、禁用的库和函数、出错时的处理方式
+func TestStore_Sandbox(t *testing.T) {
+	tests := []struct {
+		name, script string
+	}{
+		{"死循环超时", `while true do end`},
+		{"没有 os 库", `os.exit(1)`},
+		{"没有 io 库", `io.open("/etc/passwd")`},
+		{"不能加载代码", `load("return 1")()`},
+		{"不能加载文件", `dofile("/etc/passwd")`},
+		{"string.rep 限长", `local s = string.rep("x", 1e9)`},
+		{".. 限长", `local s = "x" for i = 1, 40 do s = s .. s end`},
+		{"table.concat 限长", `local s = "x" for i = 1, 40 do s = table.concat({s, s}) end`},
+		{"string.format 限长", `local s = "x" for i = 1, 40 do s = string.format("%s%s", s, s) end`},
+		{"string.format 宽度", `local s = string.format("%999999s", "x")`},
+		{"string.gsub 限长", `local s = "xx" for i = 1, 40 do s = s:gsub(".", "%0%0") end`},
+		{"string.gsub 替换函数限长", `local s = string.rep("x", 1000):gsub(".", function() return string.rep("y", 10000) end)`},
+		{"字符串总量", `local t = {} for i = 1, 100 do t[i] = string.rep("x", 1e6) end`},
+		{"遮蔽受限的拼接", `local function __tts_concat(a, b) return a .. b end local s = "x" .. "y"`},
+		{"递归深度", `local function f() return 1 + f() end f()`},
+		{"字段类型", `request.voice = {}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeScript(t, dir, "01-bad.lua", `request.text = "changed"; `+tt.script)
+			writeScript(t, dir, "02-good.lua", `request.style = "calm"`)
+
+			req := models.TTSRequest{Text: "原文"}
+			start := time.Now()
+			if err := newTestStore(t, dir, OnErrorSkip).Run(context.Background(), &req, Info{}); err != nil {
+				t.Fatalf("skip 模式 Run() error = %v", err)
+			}
+			if time.Since(start) > 2*time.Second {
+				t.Errorf("脚本未被及时中断")
+			}
+			if req.Text != "原文" {
+				t.Errorf("出错脚本的修改应被丢弃, got %q", req.Text)
+			}
+
+			err := newTestStore(t, dir, OnErrorReject).Run(context.Background(), &models.TTSRequest{Text: "原文"}, Info{})
+			if !errors.Is(err, ErrScriptFailed) {
+				t.Errorf("reject 模式期望 ErrScriptFailed, got %v", err)
+			}
+		})
+	}
+}
+
+// TestStore_Reload 测试脚本修改后重新加载，编译失败时保留当前脚本
+func TestStore_Reload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "style.lua")
+	writeScript(t, dir, "style.lua", `request.style = "calm"`)
+	s := newTestStore(t, dir, "")
+
+	run := func() string {
+		req := models.TTSRequest{Text: "你好"}
+		if err := s.Run(context.Background(), &req, Info{}); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		return req.Style
+	}
+	if got := run(); got != "calm" {
+		t.Fatalf("style = %q, want calm", got)
+	}
+
+	writeScript(t, dir, "style.lua", `request.style = "cheerful"`)
+	future := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := s.Reload(); err != nil || !reloaded {
+		t.Fatalf("Reload() = %v, %v, want true, nil", reloaded, err)
+	}
+	if got := run(); got != "cheerful" {
+		t.Errorf("重新加载后 style = %q, want cheerful", got)
+	}
+
+	writeScript(t, dir, "broken.lua", `request.style = `)
+	if _, err := s.Reload(); !errors.Is(err, ErrInvalidScript) {
+		t.Errorf("语法错误期望 ErrInvalidScript, got %v", err)
+	}
+	if got := run(); got != "cheerful" {
+		t.Errorf("加载失败时应保留当前脚本, got %q", got)
+	}
+
+	if _, err := NewStore(dir, Options{OnError: "ignore"}, zerolog.Nop()); !errors.Is(err, ErrInvalidScript) {
+		t.Errorf("未知 on_error 期望 ErrInvalidScript, got %v", err)
+	}
+}
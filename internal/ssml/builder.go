@@ -0,0 +1,360 @@
+// Package ssml 将结构化的合成请求编译为 SSML
+package ssml
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"tts/internal/lang"
+	"tts/internal/models"
+	"tts/internal/prosody"
+)
+
+// ErrInvalidSpan 表示结构化请求中的片段参数无效
+var ErrInvalidSpan = errors.New("invalid span")
+
+const (
+	speakOpen = `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xmlns:mstts="http://www.w3.org/2001/mstts" xml:lang="%s">`
+	// maxBreakMs 单个停顿的最长时长（毫秒）
+	maxBreakMs = 20000
+)
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+	breakTime  = regexp.MustCompile(`^(\d+)(ms|s)$`)
+	identifier = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+	localeTag  = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+	breakStrengths   = keywordSet("none", "x-weak", "weak", "medium", "strong", "x-strong")
+	emphasisLevels   = keywordSet("strong", "moderate", "none", "reduced")
+	phoneAlphabets   = keywordSet("ipa", "sapi", "ups", "x-sampa")
+	interpretAsTypes = keywordSet(
+		"address", "cardinal", "characters", "currency", "date", "digits", "duration",
+		"fraction", "interjection", "name", "number", "ordinal", "spell-out",
+		"telephone", "time", "unit", "decimal",
+	)
+)
+
+func keywordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+// EscapeText 转义文本节点中的 &、< 和 >，文本中的标记按文字朗读
+func EscapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// EscapeAttr 转义双引号括起的属性值
+func EscapeAttr(s string) string {
+	return attrEscaper.Replace(s)
+}
+
+// expression mstts:express-as 的参数
+type expression struct {
+	style  string
+	degree float64
+	role   string
+}
+
+// Builder 逐个片段构建 SSML 文档
+//
+// 相邻片段使用相同的语音、风格时共用 <voice> 和 <mstts:express-as> 元素，
+// 语速、语调、音量只作用于所在片段。所有文本和属性值都会被转义。
+type Builder struct {
+	lang      string
+	body      strings.Builder
+	voice     string      // 当前打开的 <voice>
+	expr      *expression // 当前打开的 <mstts:express-as>
+	spans     int
+	hasSpeech bool
+}
+
+// NewBuilder 创建 SSML 构建器，lang 为空时从第一个片段的语音推断
+func NewBuilder(lang string) *Builder {
+	return &Builder{lang: lang}
+}
+
+// Add 追加一个片段，片段参数需已合并默认值
+func (b *Builder) Add(span models.Span) error {
+	index := b.spans
+	b.spans++
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: span %d: %s", ErrInvalidSpan, index, fmt.Sprintf(format, args...))
+	}
+
+	if span.Text == "" && span.Break == "" {
+		return invalid("text or break is required")
+	}
+	if strings.TrimSpace(span.Voice) == "" {
+		return invalid("voice is required")
+	}
+
+	prosody, err := prosodyAttrs(span)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	expr, err := expressionOf(span)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	content, err := contentOf(span)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	pause, err := breakOf(span.Break)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	if span.Lang != "" && !localeTag.MatchString(span.Lang) {
+		return invalid("invalid lang %q", span.Lang)
+	}
+
+	if b.lang == "" {
+		b.lang = lang.LocaleOf(span.Voice)
+	}
+	b.switchVoice(span.Voice)
+	b.switchExpression(expr)
+
+	if content != "" {
+		b.hasSpeech = true
+		if prosody != "" {
+			content = "<prosody" + prosody + ">" + content + "</prosody>"
+		}
+		if span.Lang != "" {
+			content = `<lang xml:lang="` + span.Lang + `">` + content + "</lang>"
+		}
+		b.body.WriteString(content)
+	}
+	b.body.WriteString(pause)
+	return nil
+}
+
+// String 返回完整的 SSML 文档
+func (b *Builder) String() string {
+	locale := b.lang
+	if locale == "" {
+		locale = lang.DefaultLocale
+	}
+	var doc strings.Builder
+	fmt.Fprintf(&doc, speakOpen, attrEscaper.Replace(locale))
+	doc.WriteString(b.body.String())
+	if b.expr != nil {
+		doc.WriteString("</mstts:express-as>")
+	}
+	if b.voice != "" {
+		doc.WriteString("</voice>")
+	}
+	doc.WriteString("</speak>")
+	return doc.String()
+}
+
+// switchVoice 语音变化时关闭当前元素并打开新的 <voice>
+func (b *Builder) switchVoice(voice string) {
+	if voice == b.voice {
+		return
+	}
+	b.switchExpression(nil)
+	if b.voice != "" {
+		b.body.WriteString("</voice>")
+	}
+	b.body.WriteString(`<voice name="` + attrEscaper.Replace(voice) + `">`)
+	b.voice = voice
+}
+
+// switchExpression 风格变化时关闭当前的 <mstts:express-as> 并按需打开新的
+func (b *Builder) switchExpression(expr *expression) {
+	if sameExpression(b.expr, expr) {
+		return
+	}
+	if b.expr != nil {
+		b.body.WriteString("</mstts:express-as>")
+	}
+	b.expr = expr
+	if expr == nil {
+		return
+	}
+	b.body.WriteString("<mstts:express-as")
+	if expr.style != "" {
+		b.body.WriteString(` style="` + expr.style + `"`)
+	}
+	if expr.degree != 0 {
+		b.body.WriteString(` styledegree="` + strconv.FormatFloat(expr.degree, 'f', -1, 64) + `"`)
+	}
+	if expr.role != "" {
+		b.body.WriteString(` role="` + expr.role + `"`)
+	}
+	b.body.WriteString(">")
+}
+
+func sameExpression(a, b *expression) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Compile 将结构化请求编译为 SSML，片段中未指定的参数继承请求的默认值
+func Compile(req models.TTSRequest) (string, error) {
+	if len(req.Spans) == 0 {
+		return "", fmt.Errorf("%w: spans is empty", ErrInvalidSpan)
+	}
+
+	b := NewBuilder("")
+	for _, span := range req.Spans {
+		if err := b.Add(withDefaults(span, req)); err != nil {
+			return "", err
+		}
+	}
+	if !b.hasSpeech {
+		return "", fmt.Errorf("%w: spans contain no text", ErrInvalidSpan)
+	}
+	return b.String(), nil
+}
+
+// withDefaults 用请求级参数填充片段中未指定的参数
+func withDefaults(span models.Span, req models.TTSRequest) models.Span {
+	if span.Voice == "" {
+		span.Voice = req.Voice
+	}
+	if span.Voice == req.Voice {
+		// 默认风格和角色只属于默认语音，其他语音不一定支持
+		if span.Style == "" {
+			span.Style = req.Style
+			if span.StyleDegree == 0 && span.Style != "" {
+				span.StyleDegree = req.StyleDegree
+			}
+		}
+		if span.Role == "" {
+			span.Role = req.Role
+		}
+	}
+	if span.Rate == "" {
+		span.Rate = req.Rate
+	}
+	if span.Pitch == "" {
+		span.Pitch = req.Pitch
+	}
+	if span.Volume == "" {
+		span.Volume = req.Volume
+	}
+	return span
+}
+
+// expressionOf 返回片段的风格参数，未指定风格和角色时返回 nil
+func expressionOf(span models.Span) (*expression, error) {
+	style := span.Style
+	if style == "general" {
+		style = ""
+	}
+	role, err := prosody.ParseRole(span.Role)
+	if err != nil {
+		return nil, err
+	}
+	if style == "" && role == "" {
+		if span.StyleDegree != 0 {
+			return nil, errors.New("styledegree requires style")
+		}
+		return nil, nil
+	}
+	if style != "" && !identifier.MatchString(style) {
+		return nil, fmt.Errorf("invalid style %q", style)
+	}
+	if err := prosody.CheckStyleDegree(span.StyleDegree); err != nil {
+		return nil, err
+	}
+	return &expression{style: style, degree: span.StyleDegree, role: role}, nil
+}
+
+// prosodyAttrs 返回 <prosody> 的属性，全部为默认值时返回空字符串
+func prosodyAttrs(span models.Span) (string, error) {
+	return prosody.Attrs(span.Rate, span.Pitch, span.Volume)
+}
+
+// contentOf 返回片段文本的 SSML 内容（含 say-as、phoneme、sub、emphasis）
+func contentOf(span models.Span) (string, error) {
+	if span.Text == "" {
+		if span.SayAs != "" || span.Emphasis != "" || span.Phoneme != "" || span.Sub != "" {
+			return "", errors.New("say_as, phoneme, sub and emphasis require text")
+		}
+		return "", nil
+	}
+
+	content := textEscaper.Replace(span.Text)
+	pronunciations := 0
+	for _, set := range []bool{span.SayAs != "", span.Phoneme != "", span.Sub != ""} {
+		if set {
+			pronunciations++
+		}
+	}
+	if pronunciations > 1 {
+		return "", errors.New("say_as, phoneme and sub are mutually exclusive")
+	}
+	if span.Alphabet != "" && span.Phoneme == "" {
+		return "", errors.New("alphabet requires phoneme")
+	}
+
+	switch {
+	case span.Phoneme != "":
+		alphabet := span.Alphabet
+		if alphabet == "" {
+			alphabet = "ipa"
+		}
+		if !phoneAlphabets[alphabet] {
+			return "", fmt.Errorf("unsupported alphabet %q", span.Alphabet)
+		}
+		content = `<phoneme alphabet="` + alphabet + `" ph="` + attrEscaper.Replace(span.Phoneme) + `">` + content + "</phoneme>"
+	case span.Sub != "":
+		content = `<sub alias="` + attrEscaper.Replace(span.Sub) + `">` + content + "</sub>"
+	}
+	if span.SayAs != "" {
+		if !interpretAsTypes[span.SayAs] {
+			return "", fmt.Errorf("unsupported say_as %q", span.SayAs)
+		}
+		attrs := ` interpret-as="` + span.SayAs + `"`
+		if span.SayAsFormat != "" {
+			attrs += ` format="` + attrEscaper.Replace(span.SayAsFormat) + `"`
+		}
+		content = "<say-as" + attrs + ">" + content + "</say-as>"
+	} else if span.SayAsFormat != "" {
+		return "", errors.New("say_as_format requires say_as")
+	}
+	if span.Emphasis != "" {
+		if !emphasisLevels[span.Emphasis] {
+			return "", fmt.Errorf("unsupported emphasis %q", span.Emphasis)
+		}
+		content = `<emphasis level="` + span.Emphasis + `">` + content + "</emphasis>"
+	}
+	return content, nil
+}
+
+// breakOf 返回片段后的停顿元素
+func breakOf(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	if breakStrengths[value] {
+		return `<break strength="` + value + `"/>`, nil
+	}
+	m := breakTime.FindStringSubmatch(value)
+	if m == nil {
+		return "", fmt.Errorf("invalid break %q", value)
+	}
+	ms, _ := strconv.Atoi(m[1])
+	if m[2] == "s" {
+		ms *= 1000
+	}
+	if ms > maxBreakMs {
+		return "", fmt.Errorf("break must not exceed %dms", maxBreakMs)
+	}
+	return `<break time="` + value + `"/>`, nil
+}
@@ -0,0 +1,178 @@
+package ssml
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"tts/internal/lang"
+	"tts/internal/models"
+)
+
+const testHeader = `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xmlns:mstts="http://www.w3.org/2001/mstts" xml:lang="zh-CN">`
+
+// TestCompile 测试结构化请求编译为 SSML
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name string
+		req  models.TTSRequest
+		want string
+	}{
+		{
+			name: "继承默认语音与风格",
+			req: models.TTSRequest{
+				Voice: "zh-CN-XiaoxiaoNeural", Style: "cheerful", Rate: "0", Pitch: "0",
+				Spans: []models.Span{{Text: "你好"}, {Text: "世界"}},
+			},
+			want: testHeader + `<voice name="zh-CN-XiaoxiaoNeural"><mstts:express-as style="cheerful">你好世界</mstts:express-as></voice></speak>`,
+		},
+		{
+			name: "中途切换语音",
+			req: models.TTSRequest{
+				Voice: "zh-CN-XiaoxiaoNeural", Style: "cheerful",
+				Spans: []models.Span{
+					{Text: "旁白。"},
+					{Text: "对白。", Voice: "zh-CN-YunxiNeural", Role: "Boy"},
+					{Text: "旁白继续。"},
+				},
+			},
+			want: testHeader +
+				`<voice name="zh-CN-XiaoxiaoNeural"><mstts:express-as style="cheerful">旁白。</mstts:express-as></voice>` +
+				`<voice name="zh-CN-YunxiNeural"><mstts:express-as role="Boy">对白。</mstts:express-as></voice>` +
+				`<voice name="zh-CN-XiaoxiaoNeural"><mstts:express-as style="cheerful">旁白继续。</mstts:express-as></voice></speak>`,
+		},
+		{
+			name: "韵律、停顿、强调与读法",
+			req: models.TTSRequest{
+				Voice: "zh-CN-XiaoxiaoNeural",
+				Spans: []models.Span{
+					{Text: "快速", Rate: "+20", Volume: "loud", Break: "500ms"},
+					{Text: "2024-01-02", SayAs: "date", SayAsFormat: "ymd"},
+					{Text: "重要", Emphasis: "strong", StyleDegree: 1.5, Style: "sad", Break: "strong"},
+				},
+			},
+			want: testHeader + `<voice name="zh-CN-XiaoxiaoNeural">` +
+				`<prosody rate="+20%" volume="loud">快速</prosody><break time="500ms"/>` +
+				`<say-as interpret-as="date" format="ymd">2024-01-02</say-as>` +
+				`<mstts:express-as style="sad" styledegree="1.5"><emphasis level="strong">重要</emphasis><break strength="strong"/></mstts:express-as>` +
+				`</voice></speak>`,
+		},
+		{
+			name: "标注片段语言",
+			req: models.TTSRequest{
+				Voice: "zh-CN-XiaoxiaoNeural",
+				Spans: []models.Span{{Text: "你好，"}, {Text: "hello world", Lang: "en-US", Rate: "10"}},
+			},
+			want: testHeader + `<voice name="zh-CN-XiaoxiaoNeural">你好，<lang xml:lang="en-US"><prosody rate="+10%">hello world</prosody></lang></voice></speak>`,
+		},
+		{
+			name: "音标与替换读法",
+			req: models.TTSRequest{
+				Voice: "zh-CN-XiaoxiaoNeural",
+				Spans: []models.Span{
+					{Text: "重庆", Phoneme: "chong 2 qing 4", Alphabet: "sapi"},
+					{Text: "的"},
+					{Text: "W3C", Sub: `World Wide Web "Consortium"`},
+				},
+			},
+			want: testHeader + `<voice name="zh-CN-XiaoxiaoNeural">` +
+				`<phoneme alphabet="sapi" ph="chong 2 qing 4">重庆</phoneme>的` +
+				`<sub alias="World Wide Web &quot;Consortium&quot;">W3C</sub></voice></speak>`,
+		},
+		{
+			name: "转义文本与属性",
+			req: models.TTSRequest{
+				Voice: `en-US-"Jenny"`,
+				Spans: []models.Span{{Text: `a < b & "c"`}},
+			},
+			want: strings.Replace(testHeader, "zh-CN", "en-US", 1) +
+				`<voice name="en-US-&quot;Jenny&quot;">a &lt; b &amp; "c"</voice></speak>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Compile(tt.req)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Compile() =\n%s\nwant\n%s", got, tt.want)
+			}
+			assertWellFormed(t, got)
+		})
+	}
+}
+
+// TestCompile_Invalid 测试非法片段返回 ErrInvalidSpan
+func TestCompile_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		span models.Span
+	}{
+		{"空片段", models.Span{}},
+		{"只有停顿", models.Span{Break: "1s"}},
+		{"非法语速", models.Span{Text: "a", Rate: "fast\" onload=\"x"}},
+		{"非法风格", models.Span{Text: "a", Style: "sad><break/>"}},
+		{"风格强度越界", models.Span{Text: "a", Style: "sad", StyleDegree: 3}},
+		{"强度缺少风格", models.Span{Text: "a", StyleDegree: 1}},
+		{"未知读法", models.Span{Text: "a", SayAs: "emoji"}},
+		{"格式缺少读法", models.Span{Text: "a", SayAsFormat: "ymd"}},
+		{"未知强调", models.Span{Text: "a", Emphasis: "loud"}},
+		{"未知音标字母表", models.Span{Text: "a", Phoneme: "a", Alphabet: "pinyin"}},
+		{"字母表缺少音标", models.Span{Text: "a", Alphabet: "ipa"}},
+		{"音标与替换读法同时指定", models.Span{Text: "a", Phoneme: "a", Sub: "b"}},
+		{"停顿过长", models.Span{Text: "a", Break: "60s"}},
+		{"非法停顿", models.Span{Text: "a", Break: "long"}},
+		{"非法语言", models.Span{Text: "a", Lang: `en"US`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := models.TTSRequest{Voice: "zh-CN-XiaoxiaoNeural", Spans: []models.Span{tt.span}}
+			if _, err := Compile(req); !errors.Is(err, ErrInvalidSpan) {
+				t.Errorf("Compile() error = %v, want ErrInvalidSpan", err)
+			}
+		})
+	}
+
+	if _, err := Compile(models.TTSRequest{Voice: "zh-CN-XiaoxiaoNeural"}); !errors.Is(err, ErrInvalidSpan) {
+		t.Errorf("空 spans 期望 ErrInvalidSpan, got %v", err)
+	}
+}
+
+// TestLanguageSpans 测试语言片段转换为结构化片段
+func TestLanguageSpans(t *testing.T) {
+	runs := []lang.Run{{Text: "中文，", Locale: "zh-CN"}, {Text: "English text. ", Locale: "en-US"}, {Text: "日本語です。", Locale: "ja-JP"}}
+	spans := LanguageSpans(runs, "zh-CN", map[string]string{"en-us": "en-US-JennyNeural"})
+
+	want := []models.Span{
+		{Text: "中文，"},
+		{Text: "English text. ", Voice: "en-US-JennyNeural"},
+		{Text: "日本語です。", Lang: "ja-JP"},
+	}
+	if len(spans) != len(want) {
+		t.Fatalf("LanguageSpans() = %+v", spans)
+	}
+	for i := range want {
+		if spans[i] != want[i] {
+			t.Errorf("片段 %d = %+v, want %+v", i, spans[i], want[i])
+		}
+	}
+}
+
+// assertWellFormed 校验输出是格式正确的 XML
+func assertWellFormed(t *testing.T, doc string) {
+	t.Helper()
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err != io.EOF {
+				t.Errorf("输出不是合法的 XML: %v\n%s", err, doc)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package ssml
+
+import (
+	"tts/internal/lang"
+	"tts/internal/models"
+)
+
+// LanguageSpans 将语言片段转换为结构化片段
+//
+// 与默认语音同语言的片段沿用默认语音；voices 中配置了语音的语言切换到对应语音，
+// 其他语言用 <lang> 标注，由默认语音（多语言语音）朗读。
+func LanguageSpans(runs []lang.Run, primary string, voices map[string]string) []models.Span {
+	spans := make([]models.Span, 0, len(runs))
+	for _, run := range runs {
+		span := models.Span{Text: run.Text}
+		if run.Locale != primary {
+			if voice := lang.VoiceFor(voices, run.Locale); voice != "" {
+				span.Voice = voice
+			} else {
+				span.Lang = run.Locale
+			}
+		}
+		spans = append(spans, span)
+	}
+	return spans
+}
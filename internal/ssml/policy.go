@@ -0,0 +1,308 @@
+package ssml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrPolicyViolation 表示 SSML 不符合安全策略或不是格式正确的 XML
+var ErrPolicyViolation = errors.New("ssml policy violation")
+
+// DefaultElements 默认允许的元素及其属性（按本地名称，不含命名空间前缀）。
+// 不包含 audio、lexicon、backgroundaudio 等会让上游访问外部地址的元素。
+var DefaultElements = map[string][]string{
+	"speak":      {"version", "lang", "base"},
+	"voice":      {"name", "effect"},
+	"p":          {},
+	"s":          {},
+	"break":      {"time", "strength"},
+	"prosody":    {"rate", "pitch", "volume", "contour", "range"},
+	"emphasis":   {"level"},
+	"say-as":     {"interpret-as", "format", "detail"},
+	"phoneme":    {"alphabet", "ph"},
+	"sub":        {"alias"},
+	"lang":       {"lang"},
+	"express-as": {"style", "styledegree", "role"},
+	"silence":    {"type", "value"},
+	"bookmark":   {"mark"},
+}
+
+// 策略的默认限制
+const (
+	DefaultMaxBreakMs       = 5000
+	DefaultMaxVoiceSwitches = 50
+)
+
+// AnyKey 语音限制中适用于未提供 API 密钥和未单独配置的密钥的条目
+const AnyKey = "*"
+
+// durationValue SSML 的时长写法，如 500ms、1.5s
+var durationValue = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*(ms|s)$`)
+
+// PolicyOptions SSML 安全策略的配置
+type PolicyOptions struct {
+	Elements         map[string][]string // 允许的元素 -> 允许的属性，为空时使用 DefaultElements
+	MaxBreakMs       int                 // <break time> 和 <mstts:silence value> 的最长时长（毫秒），0 使用默认值
+	MaxVoiceSwitches int                 // <voice> 元素的最大数量，0 使用默认值
+	Voices           map[string][]string // API 密钥 -> 允许的语音（支持 * 通配符），为空时不限制；配置后未列出的密钥使用 AnyKey 条目，没有该条目时不能使用任何语音
+}
+
+// Policy 检查客户端提交的 SSML：元素和属性白名单、停顿时长、语音切换次数和按 API 密钥允许的语音
+type Policy struct {
+	elements         map[string]map[string]bool
+	maxBreakMs       int
+	maxVoiceSwitches int
+	voices           map[string][]string // 小写的 API 密钥 -> 小写的语音模式
+}
+
+// PolicyError SSML 策略错误，Line 和 Column（按字符计算）从 1 开始，指向出错元素的开始位置或 XML 格式错误的位置
+type PolicyError struct {
+	Line, Column int
+	Msg          string
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("%v at line %d, column %d: %s", ErrPolicyViolation, e.Line, e.Column, e.Msg)
+}
+
+// Unwrap 使 errors.Is(err, ErrPolicyViolation) 成立
+func (e *PolicyError) Unwrap() error {
+	return ErrPolicyViolation
+}
+
+// NewPolicy 根据配置创建 SSML 策略
+func NewPolicy(opts PolicyOptions) (*Policy, error) {
+	p := &Policy{
+		elements:         make(map[string]map[string]bool),
+		maxBreakMs:       opts.MaxBreakMs,
+		maxVoiceSwitches: opts.MaxVoiceSwitches,
+		voices:           make(map[string][]string, len(opts.Voices)),
+	}
+	if p.maxBreakMs == 0 {
+		p.maxBreakMs = DefaultMaxBreakMs
+	}
+	if p.maxVoiceSwitches == 0 {
+		p.maxVoiceSwitches = DefaultMaxVoiceSwitches
+	}
+	if p.maxBreakMs < 0 || p.maxVoiceSwitches < 0 {
+		return nil, fmt.Errorf("%w: limits must not be negative", ErrPolicyViolation)
+	}
+
+	elements := opts.Elements
+	if len(elements) == 0 {
+		elements = DefaultElements
+	}
+	for name, attrs := range elements {
+		allowed := make(map[string]bool, len(attrs))
+		for _, a := range attrs {
+			allowed[strings.ToLower(a)] = true
+		}
+		p.elements[strings.ToLower(name)] = allowed
+	}
+	if _, ok := p.elements["speak"]; !ok {
+		return nil, fmt.Errorf("%w: element list must include speak", ErrPolicyViolation)
+	}
+
+	for key, patterns := range opts.Voices {
+		key = strings.ToLower(strings.TrimSpace(key))
+		p.voices[key] = make([]string, 0, len(patterns))
+		for _, pattern := range patterns {
+			pattern = strings.ToLower(strings.TrimSpace(pattern))
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("%w: invalid voice pattern %q for key %q", ErrPolicyViolation, pattern, key)
+			}
+			p.voices[key] = append(p.voices[key], pattern)
+		}
+	}
+	return p, nil
+}
+
+// voicePatterns 返回 API 密钥允许的语音模式，restricted 为 false 表示没有配置语音限制。
+// 未提供或未单独配置的密钥使用 AnyKey 条目，没有该条目时不允许任何语音
+func (p *Policy) voicePatterns(apiKey string) (patterns []string, restricted bool) {
+	if len(p.voices) == 0 {
+		return nil, false
+	}
+	key := strings.ToLower(strings.TrimSpace(apiKey))
+	if patterns, ok := p.voices[key]; ok && key != "" && key != AnyKey {
+		return patterns, true
+	}
+	return p.voices[AnyKey], true
+}
+
+// AllowVoice API 密钥是否可以使用语音。apiKey 应为通过验证的密钥，不能使用客户端声明的密钥
+func (p *Policy) AllowVoice(apiKey, voice string) bool {
+	patterns, restricted := p.voicePatterns(apiKey)
+	if !restricted {
+		return true
+	}
+	voice = strings.ToLower(strings.TrimSpace(voice))
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, voice); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Check 检查 SSML 文档，返回第一个违反策略的位置（*PolicyError）
+func (p *Policy) Check(content, apiKey string) error {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = true
+	voices := 0
+	root := true
+
+	for {
+		// 只记录偏移量，出错时再计算行和列
+		offset := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			if root {
+				return policyError(content, offset, "missing <speak> root element")
+			}
+			return nil
+		}
+		if err != nil {
+			// 格式错误时指向解析器发现错误的位置
+			msg := err.Error()
+			var syntax *xml.SyntaxError
+			if errors.As(err, &syntax) {
+				msg = syntax.Msg
+			}
+			return policyError(content, decoder.InputOffset(), "malformed XML: "+msg)
+		}
+
+		fail := func(format string, args ...any) error {
+			return policyError(content, offset, fmt.Sprintf(format, args...))
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			if root {
+				if name != "speak" {
+					return fail("root element must be <speak>, got <%s>", name)
+				}
+				root = false
+			}
+			allowed, ok := p.elements[strings.ToLower(name)]
+			if !ok {
+				return fail("element <%s> is not allowed", name)
+			}
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+					continue
+				}
+				if !allowed[strings.ToLower(a.Name.Local)] {
+					return fail("attribute %q is not allowed on <%s>", a.Name.Local, name)
+				}
+			}
+			if err := p.checkElement(t, apiKey, &voices); err != nil {
+				return fail("%s", err)
+			}
+		case xml.Directive:
+			return fail("DOCTYPE and other directives are not allowed")
+		case xml.ProcInst:
+			if t.Target != "xml" {
+				return fail("processing instruction <?%s?> is not allowed", t.Target)
+			}
+		case xml.CharData:
+			if root && strings.TrimSpace(string(t)) != "" {
+				return fail("text outside the <speak> root element")
+			}
+		}
+	}
+}
+
+// checkElement 检查元素的属性值：停顿时长、语音切换次数和允许的语音
+func (p *Policy) checkElement(e xml.StartElement, apiKey string, voices *int) error {
+	switch e.Name.Local {
+	case "break", "silence":
+		key := "time"
+		if e.Name.Local == "silence" {
+			key = "value"
+		}
+		value := attrValue(e, key)
+		if value == "" {
+			return nil
+		}
+		ms, ok := durationMs(value)
+		if !ok {
+			return fmt.Errorf("invalid duration %s=%q on <%s>", key, value, e.Name.Local)
+		}
+		if ms > float64(p.maxBreakMs) {
+			return fmt.Errorf("<%s> duration %s exceeds %dms", e.Name.Local, value, p.maxBreakMs)
+		}
+	case "voice":
+		*voices++
+		if *voices > p.maxVoiceSwitches {
+			return fmt.Errorf("more than %d <voice> elements", p.maxVoiceSwitches)
+		}
+		if name := attrValue(e, "name"); name != "" && !p.AllowVoice(apiKey, name) {
+			return fmt.Errorf("voice %q is not allowed for this API key", name)
+		}
+	}
+	return nil
+}
+
+// AllowedVoices 返回 API 密钥允许的语音模式（排序），未配置限制时返回 nil，不允许任何语音时返回空列表
+func (p *Policy) AllowedVoices(apiKey string) []string {
+	patterns, restricted := p.voicePatterns(apiKey)
+	if !restricted {
+		return nil
+	}
+	list := append([]string{}, patterns...)
+	sort.Strings(list)
+	return list
+}
+
+// policyError 返回指向字节偏移量的策略错误
+func policyError(content string, offset int64, msg string) *PolicyError {
+	line, column := position(content, offset)
+	return &PolicyError{Line: line, Column: column, Msg: msg}
+}
+
+// position 返回字节偏移量对应的行和列（按字符计算，从 1 开始）
+func position(content string, offset int64) (int, int) {
+	if offset > int64(len(content)) {
+		offset = int64(len(content))
+	}
+	before := content[:offset]
+	line := strings.Count(before, "\n") + 1
+	if i := strings.LastIndexByte(before, '\n'); i >= 0 {
+		before = before[i+1:]
+	}
+	return line, utf8.RuneCountInString(before) + 1
+}
+
+// durationMs 解析时长，返回毫秒
+func durationMs(value string) (float64, bool) {
+	m := durationValue.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	if m[2] == "s" {
+		n *= 1000
+	}
+	return n, true
+}
+
+func attrValue(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return strings.TrimSpace(a.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,154 @@
+package ssml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"tts/internal/models"
+)
+
+const testSpeak = `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xmlns:mstts="http://www.w3.org/2001/mstts" xml:lang="zh-CN">`
+
+// TestPolicy_Check 测试元素白名单、停顿时长、语音数量和按 API 密钥允许的语音，错误指向出错的行和列
+func TestPolicy_Check(t *testing.T) {
+	p, err := NewPolicy(PolicyOptions{
+		MaxBreakMs:       3000,
+		MaxVoiceSwitches: 2,
+		Voices:           map[string][]string{"Team-A": {"zh-CN-*"}, AnyKey: {"*"}},
+	})
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		name, apiKey, ssml string
+		line, column       int
+		want               string // 为空表示通过检查
+	}{
+		{"允许的元素", "", testSpeak + `<voice name="zh-CN-XiaoxiaoNeural"><mstts:express-as style="cheerful"><prosody rate="+10%">你好<break time="1.5s"/></prosody></mstts:express-as></voice></speak>`, 0, 0, ""},
+		{"不允许的元素", "", "<speak>\n  <voice name=\"a\">\n    <audio src=\"http://x/a.mp3\"/></voice></speak>", 3, 5, "element <audio>"},
+		{"不允许的属性", "", `<speak><voice name="a" onload="x">你好</voice></speak>`, 1, 8, `attribute "onload"`},
+		{"停顿过长", "", `<speak><break time="10s"/></speak>`, 1, 8, "exceeds 3000ms"},
+		{"静音过长", "", testSpeak + `<mstts:silence type="Sentenceboundary" value="4000ms"/></speak>`, 1, 126, "<silence>"},
+		{"无效的时长", "", `<speak><break time="forever"/></speak>`, 1, 8, "invalid duration"},
+		{"语音过多", "", `<speak><voice name="a">一</voice><voice name="b">二</voice><voice name="c">三</voice></speak>`, 1, 58, "more than 2"},
+		{"密钥不允许的语音", "team-a", `<speak><voice name="en-US-JennyNeural">hi</voice></speak>`, 1, 8, `voice "en-US-JennyNeural"`},
+		{"密钥允许的语音", "TEAM-A", `<speak><voice name="zh-CN-YunxiNeural">你好</voice></speak>`, 0, 0, ""},
+		{"格式错误", "", "<speak>\n<voice name=\"a\">你好</speak>", 2, 27, "malformed XML"},
+		{"未闭合的属性", "", `<speak><voice name="a>你好</voice></speak>`, 1, 26, "malformed XML"},
+		{"根元素", "", `<voice name="a">你好</voice>`, 1, 1, "root element"},
+		{"DOCTYPE", "", `<!DOCTYPE speak [<!ENTITY x "y">]><speak>&x;</speak>`, 1, 1, "directives"},
+		{"根元素外的文本", "", `你好<speak></speak>`, 1, 1, "outside"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Check(tt.ssml, tt.apiKey)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Check() error = %v", err)
+				}
+				return
+			}
+			var perr *PolicyError
+			if !errors.As(err, &perr) || !errors.Is(err, ErrPolicyViolation) {
+				t.Fatalf("Check() error = %v, want *PolicyError", err)
+			}
+			if !strings.Contains(perr.Msg, tt.want) {
+				t.Errorf("Msg = %q, want to contain %q", perr.Msg, tt.want)
+			}
+			if perr.Line != tt.line || perr.Column != tt.column {
+				t.Errorf("位置 = %d:%d, want %d:%d", perr.Line, perr.Column, tt.line, tt.column)
+			}
+		})
+	}
+}
+
+// TestPolicy_Options 测试自定义元素列表和无效的配置
+func TestPolicy_Options(t *testing.T) {
+	p, err := NewPolicy(PolicyOptions{Elements: map[string][]string{"speak": nil, "Break": {"TIME"}}})
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+	if err := p.Check(`<speak><break time="500ms"/></speak>`, ""); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+	if err := p.Check(`<speak><voice name="a">你好</voice></speak>`, ""); !errors.Is(err, ErrPolicyViolation) {
+		t.Errorf("未配置的元素应被拒绝, got %v", err)
+	}
+
+	for _, opts := range []PolicyOptions{
+		{Elements: map[string][]string{"voice": {"name"}}},
+		{MaxBreakMs: -1},
+		{Voices: map[string][]string{"k": {"zh-CN-["}}},
+	} {
+		if _, err := NewPolicy(opts); !errors.Is(err, ErrPolicyViolation) {
+			t.Errorf("NewPolicy(%+v) error = %v, want ErrPolicyViolation", opts, err)
+		}
+	}
+}
+
+// TestPolicy_Compiled 测试编译生成的 SSML 通过默认策略，片段中的停顿同样受 max_break_ms 限制
+func TestPolicy_Compiled(t *testing.T) {
+	p, err := NewPolicy(PolicyOptions{MaxBreakMs: 3000})
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+	req := models.TTSRequest{Voice: "zh-CN-XiaoxiaoNeural", Rate: "+10%", Spans: []models.Span{
+		{Text: "你好", Break: "1s", Style: "cheerful"},
+		{Text: "世界", Voice: "zh-CN-YunxiNeural", Break: "10s"},
+	}}
+	compiled, err := Compile(req)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if err := p.Check(compiled, ""); !errors.Is(err, ErrPolicyViolation) || !strings.Contains(err.Error(), "exceeds 3000ms") {
+		t.Errorf("Check() error = %v, want break limit violation", err)
+	}
+
+	req.Spans[1].Break = "2s"
+	if compiled, err = Compile(req); err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if err := p.Check(compiled, ""); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+}
+
+// TestPolicy_Voices 测试语音限制对未提供和未配置的密钥默认拒绝，AnyKey 条目适用于这些密钥
+func TestPolicy_Voices(t *testing.T) {
+	p, err := NewPolicy(PolicyOptions{Voices: map[string][]string{"team-a": {"zh-CN-*"}, "team-b": nil}})
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+	tests := []struct {
+		apiKey, voice string
+		want          bool
+	}{
+		{"TEAM-A", "zh-CN-YunxiNeural", true},
+		{"team-a", "en-US-JennyNeural", false},
+		{"team-b", "zh-CN-YunxiNeural", false},
+		{"", "zh-CN-YunxiNeural", false},
+		{"other", "zh-CN-YunxiNeural", false},
+		{"*", "zh-CN-YunxiNeural", false},
+	}
+	for _, tt := range tests {
+		if got := p.AllowVoice(tt.apiKey, tt.voice); got != tt.want {
+			t.Errorf("AllowVoice(%q, %q) = %v, want %v", tt.apiKey, tt.voice, got, tt.want)
+		}
+	}
+	if got := p.AllowedVoices(""); got == nil || len(got) != 0 {
+		t.Errorf("AllowedVoices(\"\") = %#v, want empty list", got)
+	}
+
+	p, err = NewPolicy(PolicyOptions{Voices: map[string][]string{"team-a": {"zh-CN-*"}, AnyKey: {"en-US-*"}}})
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+	if !p.AllowVoice("", "en-US-JennyNeural") || p.AllowVoice("other", "zh-CN-YunxiNeural") {
+		t.Errorf("未配置的密钥应使用 %s 条目", AnyKey)
+	}
+	if p := (&Policy{}); !p.AllowVoice("", "zh-CN-YunxiNeural") || p.AllowedVoices("") != nil {
+		t.Errorf("未配置语音限制时不应限制")
+	}
+}
@@ -0,0 +1,295 @@
+package textproc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidConversion 表示简繁转换方式或词典无效
+var ErrInvalidConversion = errors.New("invalid chinese conversion")
+
+// 简繁转换方式
+const (
+	ConversionS2T   = "s2t"   // 简体到繁体
+	ConversionS2TW  = "s2tw"  // 简体到台湾正体
+	ConversionS2TWP = "s2twp" // 简体到台湾正体，并转换为台湾常用词
+	ConversionS2HK  = "s2hk"  // 简体到香港繁体
+	ConversionT2S   = "t2s"   // 繁体到简体
+	ConversionTW2SP = "tw2sp" // 台湾正体到简体，并转换为大陆常用词
+
+	// ConversionAuto 按语音的语言选择转换方式
+	ConversionAuto = "auto"
+	// ConversionNone 不转换
+	ConversionNone = "none"
+)
+
+// defaultConversionLocales 按语音的语言选择的默认转换方式，前缀匹配（zh-cn 同样适用于 zh-CN-liaoning 等方言语音）
+var defaultConversionLocales = map[string]string{
+	"zh-cn":  ConversionT2S,
+	"zh-tw":  ConversionS2TW,
+	"zh-hk":  ConversionS2HK,
+	"wuu-cn": ConversionT2S,
+	"yue-cn": ConversionT2S,
+}
+
+// convDict 按最长匹配转换的词典，词条可以是单字或词语
+type convDict struct {
+	entries map[string]string
+	maxLen  int // 最长词条的字数
+}
+
+func newConvDict() *convDict {
+	return &convDict{entries: make(map[string]string)}
+}
+
+// add 添加词条，已有的词条优先
+func (d *convDict) add(key, value string) {
+	if _, ok := d.entries[key]; ok {
+		return
+	}
+	d.entries[key] = value
+	if n := utf8.RuneCountInString(key); n > d.maxLen {
+		d.maxLen = n
+	}
+}
+
+// addTable 添加内置词表：字表词条为两个字，词语表词条为 “原文:译文”
+func (d *convDict) addTable(table string, reverse bool) {
+	for _, entry := range strings.Fields(table) {
+		from, to, ok := strings.Cut(entry, ":")
+		if !ok {
+			runes := []rune(entry)
+			from, to = string(runes[0]), string(runes[1:])
+		}
+		if reverse {
+			from, to = to, from
+		}
+		d.add(from, to)
+	}
+}
+
+// convert 从左到右按最长匹配替换词条
+func (d *convDict) convert(text string) string {
+	if len(d.entries) == 0 {
+		return text
+	}
+	runes := []rune(text)
+	var b strings.Builder
+	b.Grow(len(text))
+	for i := 0; i < len(runes); {
+		matched := false
+		for n := min(d.maxLen, len(runes)-i); n > 0; n-- {
+			if value, ok := d.entries[string(runes[i:i+n])]; ok {
+				b.WriteString(value)
+				i += n
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteRune(runes[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// Conversion 一种简繁转换，依次执行各阶段的词典
+type Conversion struct {
+	name   string
+	stages []*convDict
+}
+
+// Name 返回转换方式名称
+func (c *Conversion) Name() string {
+	return c.name
+}
+
+// Convert 转换文本
+func (c *Conversion) Convert(text string) string {
+	for _, stage := range c.stages {
+		text = stage.convert(text)
+	}
+	return text
+}
+
+// ChineseConverter 管理各种简繁转换，按请求或语音的语言选择
+type ChineseConverter struct {
+	conversions map[string]*Conversion
+	auto        bool
+	locales     map[string]string // 小写语言前缀 -> 转换方式
+}
+
+// NewChineseConverter 创建简繁转换器。dictDir 不为空时加载其中的 OpenCC 格式词典
+// （STCharacters.txt、STPhrases.txt、TSCharacters.txt、TSPhrases.txt、TWVariants.txt、TWPhrases.txt、HKVariants.txt），
+// 词典中的词条优先于内置词表；locales 覆盖按语言选择的默认转换方式。
+func NewChineseConverter(dictDir string, auto bool, locales map[string]string) (*ChineseConverter, error) {
+	if dictDir != "" {
+		info, err := os.Stat(dictDir)
+		if err != nil {
+			return nil, fmt.Errorf("%w: dict dir: %v", ErrInvalidConversion, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%w: dict dir %s is not a directory", ErrInvalidConversion, dictDir)
+		}
+	}
+
+	st, err := loadConvDict(dictDir, []string{"STCharacters.txt", "STPhrases.txt"}, stCharacters, stPhrases)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := loadConvDict(dictDir, []string{"TSCharacters.txt", "TSPhrases.txt"}, tsExtraCharacters, tsPhrases)
+	if err != nil {
+		return nil, err
+	}
+	// 繁体到简体的字表由简体到繁体的字表反向得到，繁体词语同样反向保留
+	ts.addTable(stCharacters, true)
+	ts.addTable(stPhrases, true)
+
+	twVar, err := loadConvDict(dictDir, []string{"TWVariants.txt"}, twVariants)
+	if err != nil {
+		return nil, err
+	}
+	twPhr, err := loadConvDict(dictDir, []string{"TWPhrases.txt"}, twPhrases)
+	if err != nil {
+		return nil, err
+	}
+	hkVar, err := loadConvDict(dictDir, []string{"HKVariants.txt"}, hkVariants)
+	if err != nil {
+		return nil, err
+	}
+	twVarRev, twPhrRev := reverseConvDict(twVar), reverseConvDict(twPhr)
+
+	c := &ChineseConverter{
+		conversions: map[string]*Conversion{
+			ConversionS2T:   {name: ConversionS2T, stages: []*convDict{st}},
+			ConversionS2TW:  {name: ConversionS2TW, stages: []*convDict{st, twVar}},
+			ConversionS2TWP: {name: ConversionS2TWP, stages: []*convDict{st, twPhr, twVar}},
+			ConversionS2HK:  {name: ConversionS2HK, stages: []*convDict{st, hkVar}},
+			ConversionT2S:   {name: ConversionT2S, stages: []*convDict{ts}},
+			ConversionTW2SP: {name: ConversionTW2SP, stages: []*convDict{twVarRev, twPhrRev, ts}},
+		},
+		auto:    auto,
+		locales: make(map[string]string, len(defaultConversionLocales)+len(locales)),
+	}
+	for locale, name := range defaultConversionLocales {
+		c.locales[locale] = name
+	}
+	for locale, name := range locales {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != ConversionNone && c.conversions[name] == nil {
+			return nil, fmt.Errorf("%w: locale %s: unknown conversion %q", ErrInvalidConversion, locale, name)
+		}
+		c.locales[strings.ToLower(locale)] = name
+	}
+	return c, nil
+}
+
+// loadConvDict 合并词典目录中的 OpenCC 词典和内置词表
+func loadConvDict(dir string, names []string, tables ...string) (*convDict, error) {
+	d := newConvDict()
+	if dir != "" {
+		for _, name := range names {
+			if err := d.loadFile(filepath.Join(dir, name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, table := range tables {
+		d.addTable(table, false)
+	}
+	return d, nil
+}
+
+// loadFile 加载 OpenCC 格式的词典文件（每行 “原文<Tab>译文 [其他译文...]”，取第一个译文），文件不存在时跳过
+func (d *convDict) loadFile(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConversion, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		from, to, ok := strings.Cut(text, "\t")
+		values := strings.Fields(to)
+		if !ok || from == "" || len(values) == 0 {
+			return fmt.Errorf("%w: %s:%d: expected \"source<TAB>target\"", ErrInvalidConversion, filepath.Base(path), line)
+		}
+		d.add(from, values[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidConversion, filepath.Base(path), err)
+	}
+	return nil
+}
+
+// reverseConvDict 返回反向词典，多个原文对应同一译文时取排序最前的原文
+func reverseConvDict(d *convDict) *convDict {
+	keys := make([]string, 0, len(d.entries))
+	for key := range d.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	r := newConvDict()
+	for _, key := range keys {
+		r.add(d.entries[key], key)
+	}
+	return r
+}
+
+// Names 返回支持的转换方式（按名称排序）
+func (c *ChineseConverter) Names() []string {
+	names := make([]string, 0, len(c.conversions))
+	for name := range c.conversions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select 选择转换方式：请求指定的方式优先；未指定时若开启了自动转换，或请求指定 auto，按 locale 选择。
+// 指定 none 或 locale 没有对应的转换方式时返回 nil。
+func (c *ChineseConverter) Select(requested, locale string) (*Conversion, error) {
+	name := strings.ToLower(strings.TrimSpace(requested))
+	if name == "" && c.auto {
+		name = ConversionAuto
+	}
+	if name == ConversionAuto {
+		name = c.forLocale(locale)
+	}
+	if name == "" || name == ConversionNone {
+		return nil, nil
+	}
+	conv, ok := c.conversions[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown conversion %q", ErrInvalidConversion, requested)
+	}
+	return conv, nil
+}
+
+// forLocale 返回与 locale 最长前缀匹配的转换方式
+func (c *ChineseConverter) forLocale(locale string) string {
+	locale = strings.ToLower(locale)
+	best, name := -1, ""
+	for prefix, conv := range c.locales {
+		if (locale == prefix || strings.HasPrefix(locale, prefix+"-")) && len(prefix) > best {
+			best, name = len(prefix), conv
+		}
+	}
+	return name
+}
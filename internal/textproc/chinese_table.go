@@ -0,0 +1,195 @@
+package textproc
+
+// 内置简繁转换词表（常用字和常见一对多词语），格式为空白分隔的词条。
+// 字表每个词条由简体字和繁体字两个字组成；词语表每个词条为 “简体:繁体”。
+// 内置词表只覆盖常用字词，需要完整转换时可在 tts.chinese.dict_dir 中放置 OpenCC 格式的词典文件。
+
+// stCharacters 简体字到繁体字，一简对多繁时取最常用的写法，其余由词语表覆盖
+const stCharacters = `
+万萬 与與 丑醜 专專 业業 丛叢 东東 丝絲 丢丟 两兩 严嚴 丧喪 个個 丰豐 临臨 为為 丽麗 举舉 么麼 义義
+乌烏 乐樂 乔喬 习習 乡鄉 书書 买買 乱亂 争爭 于於 亏虧 云雲 亚亞 产產 亩畝 亲親 亵褻 亿億 仅僅 从從
+仑侖 仓倉 仪儀 们們 价價 众眾 优優 会會 伛傴 伞傘 伟偉 传傳 伤傷 伥倀 伦倫 伧傖 伪偽 伫佇 体體 佣傭
+佥僉 侠俠 侣侶 侥僥 侦偵 侧側 侨僑 侩儈 侪儕 侬儂 俦儔 俨儼 俩倆 俪儷 俭儉 债債 倾傾 偻僂 偾僨 偿償
+傥儻 傧儐 储儲 傩儺 儿兒 兑兌 兖兗 党黨 兰蘭 关關 兴興 兹茲 养養 兽獸 内內 冈岡 册冊 写寫 军軍 农農
+冯馮 冲衝 决決 况況 冻凍 净淨 凄淒 凉涼 减減 凑湊 凛凜 几幾 凤鳳 凫鳧 凭憑 凯凱 击擊 凿鑿 刍芻 划劃
+刘劉 则則 刚剛 创創 删刪 别別 刭剄 刹剎 刽劊 刿劌 剀剴 剂劑 剐剮 剑劍 剥剝 剧劇 劝勸 办辦 务務 劢勱
+动動 励勵 劲勁 劳勞 势勢 勋勳 匀勻 匦匭 匮匱 区區 医醫 华華 协協 单單 卖賣 卢盧 卤鹵 卧臥 卫衛 却卻
+厂廠 厅廳 历歷 厉厲 压壓 厌厭 厍厙 厕廁 厢廂 厣厴 厦廈 厨廚 厩廄 厮廝 县縣 叁叄 参參 双雙 发發 变變
+叙敘 叠疊 叶葉 号號 叹嘆 叽嘰 后後 吓嚇 吕呂 吗嗎 吨噸 听聽 启啟 吴吳 呐吶 呓囈 呕嘔 呖嚦 呗唄 员員
+呙咼 呛嗆 呜嗚 咏詠 咙嚨 咛嚀 咝噝 响響 哑啞 哒噠 哓嘵 哔嗶 哕噦 哗嘩 哙噲 哝噥 哟喲 唛嘜 唠嘮 唢嗩
+唤喚 啧嘖 啬嗇 啭囀 啮齧 啰囉 啸嘯 喷噴 喽嘍 嗫囁 嗳噯 嘘噓 嘤嚶 嘱囑 噜嚕 嚣囂 团團 园園 囱囪 围圍
+囵圇 国國 图圖 圆圓 圣聖 圹壙 场場 坏壞 块塊 坚堅 坛壇 坜壢 坝壩 坞塢 坟墳 坠墜 垄壟 垅壟 垆壚 垒壘
+垦墾 垩堊 垫墊 垭埡 垲塏 埘塒 埙塤 埚堝 堑塹 堕墮 墙牆 壮壯 声聲 壳殼 壶壺 处處 备備 复復 够夠 头頭
+夸誇 夹夾 夺奪 奁奩 奂奐 奋奮 奖獎 奥奧 妆妝 妇婦 妈媽 妩嫵 妪嫗 妫媯 姗姍 姹奼 娄婁 娅婭 娆嬈 娇嬌
+娈孌 娱娛 娲媧 娴嫻 婳嫿 婴嬰 婵嬋 婶嬸 媪媼 嫒嬡 嫔嬪 嫱嬙 嬷嬤 孙孫 学學 孪孿 宁寧 宝寶 实實 宠寵
+审審 宪憲 宫宮 宽寬 宾賓 寝寢 对對 寻尋 导導 寿壽 将將 尔爾 尘塵 尝嘗 尧堯 尴尷 尸屍 尽盡 层層 屉屜
+届屆 属屬 屡屢 屦屨 屿嶼 岁歲 岂豈 岖嶇 岗崗 岘峴 岚嵐 岛島 岭嶺 岽崬 岿巋 峄嶧 峡峽 峣嶢 峤嶠 峥崢
+峦巒 崂嶗 崃崍 崭嶄 嵘嶸 嵝嶁 巅巔 巩鞏 币幣 帅帥 师師 帏幃 帐帳 帘簾 帜幟 带帶 帧幀 帮幫 帱幬 帻幘
+帼幗 幂冪 干幹 并並 广廣 庄莊 庆慶 庐廬 庑廡 库庫 应應 庙廟 庞龐 废廢 廪廩 开開 异異 弃棄 张張 弥彌
+弯彎 弹彈 强強 归歸 当當 录錄 彦彥 彻徹 径徑 徕徠 忆憶 忏懺 忧憂 忾愾 怀懷 态態 怂慫 怃憮 怄慪 怅悵
+怆愴 怜憐 总總 怼懟 怿懌 恋戀 恳懇 恶惡 恸慟 恹懨 恺愷 恻惻 恼惱 恽惲 悦悅 悫愨 悬懸 悭慳 悯憫 惊驚
+惧懼 惨慘 惩懲 惫憊 惬愜 惭慚 惮憚 惯慣 愠慍 愤憤 愦憒 愿願 慑懾 懑懣 懒懶 懔懍 戆戇 戋戔 戏戲 戗戧
+战戰 戬戩 户戶 扑撲 执執 扩擴 扪捫 扫掃 扬揚 扰擾 抚撫 抛拋 抟摶 抠摳 抡掄 抢搶 护護 报報 担擔 拟擬
+拢攏 拣揀 拥擁 拦攔 拧擰 拨撥 择擇 挂掛 挚摯 挛攣 挝撾 挞撻 挟挾 挠撓 挡擋 挢撟 挣掙 挤擠 挥揮 挦撏
+捞撈 损損 捡撿 换換 捣搗 据據 掳擄 掴摑 掷擲 掸撣 掺摻 掼摜 揽攬 揿撳 搀攙 搁擱 搂摟 搅攪 携攜 摄攝
+摅攄 摆擺 摇搖 摈擯 摊攤 撄攖 撑撐 撵攆 撷擷 撸擼 撺攛 擞擻 攒攢 敌敵 敛斂 数數 斋齋 斓斕 斗鬥 斩斬
+断斷 无無 旧舊 时時 旷曠 旸暘 昙曇 昼晝 显顯 晋晉 晒曬 晓曉 晔曄 晕暈 晖暉 暂暫 暧曖 术術 朴樸 机機
+杀殺 杂雜 权權 条條 来來 杨楊 杩榪 杰傑 极極 构構 枞樅 枢樞 枣棗 枥櫪 枧梘 枨棖 枪槍 枫楓 枭梟 柜櫃
+柠檸 柽檉 栀梔 栅柵 标標 栈棧 栉櫛 栊櫳 栋棟 栌櫨 栎櫟 栏欄 树樹 栖棲 样樣 栾欒 桠椏 桡橈 桢楨 档檔
+桤榿 桥橋 桦樺 桧檜 桨槳 桩樁 梦夢 梼檮 检檢 棂欞 椁槨 椟櫝 椠槧 椤欏 椭橢 楼樓 榄欖 榇櫬 榈櫚 榉櫸
+槚檟 槛檻 槟檳 槠櫧 横橫 樯檣 樱櫻 橥櫫 橱櫥 橹櫓 橼櫞 檐簷 欢歡 欤歟 欧歐 歼殲 殁歿 殇殤 残殘 殒殞
+殓殮 殚殫 殡殯 殴毆 毁毀 毂轂 毕畢 毙斃 毡氈 毵毿 氇氌 气氣 氢氫 氩氬 氲氳 汇匯 汉漢 汤湯 汹洶 沟溝
+没沒 沣灃 沤漚 沥瀝 沦淪 沧滄 沨渢 沩溈 沪滬 泞濘 泪淚 泶澩 泷瀧 泸瀘 泺濼 泻瀉 泼潑 泽澤 泾涇 洁潔
+洒灑 洼窪 浃浹 浅淺 浆漿 浇澆 浈湞 浊濁 测測 浍澮 济濟 浏瀏 浐滻 浑渾 浒滸 浓濃 浔潯 涂塗 涛濤 涝澇
+涞淶 涟漣 涠潿 涡渦 涣渙 涤滌 润潤 涧澗 涨漲 涩澀 渊淵 渌淥 渍漬 渎瀆 渐漸 渑澠 渔漁 渗滲 温溫 游遊
+湾灣 湿濕 溃潰 溅濺 溆漵 滗潷 滚滾 滞滯 滟灩 滠灄 满滿 滢瀅 滤濾 滥濫 滦灤 滨濱 滩灘 滪澦 潆瀠 潇瀟
+潋瀲 潍濰 潜潛 潴瀦 澜瀾 濑瀨 濒瀕 灏灝 灭滅 灯燈 灵靈 灾災 灿燦 炀煬 炉爐 炖燉 炜煒 炝熗 点點 炼煉
+炽熾 烁爍 烂爛 烃烴 烛燭 烟煙 烦煩 烧燒 烨燁 烩燴 烫燙 烬燼 热熱 焕煥 焖燜 焘燾 爱愛 爷爺 牍牘 牦犛
+牵牽 牺犧 犊犢 状狀 犷獷 犸獁 犹猶 狈狽 狞獰 独獨 狭狹 狮獅 狯獪 狰猙 狱獄 狲猻 猃獫 猎獵 猕獼 猡玀
+猪豬 猫貓 猬蝟 献獻 獭獺 玑璣 玛瑪 玮瑋 环環 现現 玱瑲 玺璽 珐琺 珑瓏 珰璫 珲琿 琏璉 琐瑣 琼瓊 瑶瑤
+瑷璦 璎瓔 瓒瓚 瓮甕 瓯甌 电電 画畫 畅暢 畴疇 疖癤 疗療 疟瘧 疠癘 疡瘍 疬癧 疮瘡 疯瘋 疱皰 痈癰 痉痙
+痒癢 痨癆 痪瘓 痫癇 瘅癉 瘗瘞 瘘瘻 瘪癟 瘫癱 瘾癮 瘿癭 癞癩 癣癬 癫癲 皑皚 皱皺 皲皸 盏盞 盐鹽 监監
+盖蓋 盗盜 盘盤 眍瞘 眦眥 眬矓 着著 睁睜 睐睞 睑瞼 瞒瞞 瞩矚 矫矯 矶磯 矾礬 矿礦 砀碭 码碼 砖磚 砗硨
+砚硯 砺礪 砻礱 砾礫 础礎 硕碩 硖硤 硗磽 确確 碍礙 碛磧 碜磣 碱鹼 礼禮 祎禕 祢禰 祯禎 祷禱 祸禍
+禀稟 禄祿 禅禪 离離 秃禿 秆稈 种種 积積 称稱 秽穢 税稅 稣穌 稳穩 穑穡 穷窮 窃竊 窍竅 窑窯 窜竄 窝窩
+窥窺 窦竇 窭窶 竖豎 竞競 笃篤 笋筍 笔筆 笕筧 笺箋 笼籠 笾籩 筑築 筚篳 筛篩 筝箏 筹籌 签簽 简簡 箓籙
+箦簀 箧篋 箨籜 箩籮 箪簞 箫簫 篑簣 篓簍 篮籃 篱籬 籁籟 籴糴 类類 籼秈 粜糶 粝糲 粤粵 粪糞 粮糧 糁糝
+紧緊 絷縶 罂罌 网網 罗羅 罚罰 罢罷 罴羆 羁羈 羟羥 翘翹 耧耬 耸聳 耻恥 聂聶 聋聾 职職 聍聹 联聯 聩聵
+聪聰 肃肅 肠腸 肤膚 肮骯 肴餚 肾腎 肿腫 胀脹 胁脅 胆膽 胜勝 胧朧 胨腖 胪臚 胫脛 胶膠 脉脈 脍膾 脏髒
+脐臍 脑腦 脓膿 脔臠 脚腳 脱脫 脸臉 腊臘 腌醃 腘膕 腭齶 腻膩 腼靦 腾騰 膑臏 舆輿 舣艤 舰艦 舱艙 舻艫
+艰艱 艳豔 艺藝 节節 芈羋 芗薌 芜蕪 芦蘆 苁蓯 苇葦 苈藶 苋莧 苌萇 苍蒼 苎苧 苏蘇 苹蘋 茎莖 茏蘢 茑蔦
+茔塋 茕煢 茧繭 荆荊 荐薦 荚莢 荛蕘 荜蓽 荞蕎 荟薈 荠薺 荡蕩 荣榮 荤葷 荥滎 荦犖 荧熒 荨蕁 荩藎 荪蓀
+荫蔭 荬蕒 荭葒 药藥 莅蒞 莱萊 莲蓮 莳蒔 莴萵 莶薟 获獲 莸蕕 莹瑩 莺鶯 莼蓴 萝蘿 萤螢 营營 萦縈 萧蕭
+萨薩 葱蔥 蒇蕆 蒉蕢 蒋蔣 蒌蔞 蓝藍 蓟薊 蓠蘺 蓣蕷 蓥鎣 蓦驀 蔷薔 蔹蘞 蔺藺 蔼藹 蕲蘄 蕴蘊 薮藪 藓蘚
+虏虜 虑慮 虚虛 虫蟲 虬虯 虮蟣 虽雖 虾蝦 虿蠆 蚀蝕 蚁蟻 蚂螞 蚕蠶 蚬蜆 蛊蠱 蛎蠣 蛏蟶 蛮蠻 蛰蟄 蛱蛺
+蛲蟯 蛳螄 蛴蠐 蜕蛻 蜗蝸 蜡蠟 蝇蠅 蝈蟈 蝉蟬 蝼螻 蝾蠑 螨蟎 衅釁 衔銜 补補 衬襯 衮袞 袄襖 袅裊 袜襪
+袭襲 装裝 裆襠 裢褳 裣襝 裤褲 裥襇 褛褸 褴襤 觞觴 触觸 觯觶 誉譽 誊謄 雠讎 豮豶 赵趙 赶趕 趋趨 跃躍
+跄蹌 跞躒 践踐 跶躂 跷蹺 跸蹕 跹躚 跻躋 踊踴 踌躊 踪蹤 踬躓 踯躑 蹑躡 蹒蹣 蹰躕 蹿躥 躏躪 躜躦 躯軀
+辞辭 辩辯 辫辮 边邊 辽遼 达達 迁遷 过過 迈邁 运運 还還 这這 进進 远遠 违違 连連 迟遲 迩邇 迳逕 迹跡
+适適 选選 逊遜 递遞 逦邐 逻邏 遗遺 遥遙 邓鄧 邝鄺 邬鄔 邮郵 邹鄒 邺鄴 邻鄰 郏郟 郐鄶 郑鄭 郓鄆 郦酈
+郧鄖 郸鄲 酝醞 酦醱 酱醬 酽釅 酾釃 酿釀 释釋 里裏 鉴鑒 銮鑾 錾鏨 长長 阳陽 阴陰 阵陣 阶階 际際 陆陸
+陇隴 陈陳 陉陘 陕陝 陧隉 陨隕 险險 随隨 隐隱 隶隸 隽雋 难難 雏雛 雳靂 雾霧 霁霽 霭靄 靓靚 静靜 靥靨
+鞑韃 鞒鞽 鞯韉 韵韻 飞飛 馆館 髅髏 鬓鬢 魇魘 魉魎 鹾鹺 麦麥 黉黌 黡黶 黩黷 黪黲 黾黽 鼋黿 鼍鼉 鼹鼴
+齐齊 齑齏 龟龜 队隊 赃贓 淀澱 龚龔 台臺 舍捨 辟闢 咸鹹 仆僕 占佔 采採 纤纖 须須 饥飢 闲閒 钟鐘 准準
+
+订訂 计計 讣訃 认認 讥譏 讦訐 讧訌 讨討 让讓 讪訕 讫訖 训訓 议議 讯訊 记記 讲講 讳諱 讴謳 讵詎 讶訝
+讷訥 许許 讹訛 论論 讼訟 讽諷 设設 访訪 诀訣 证證 诂詁 诃訶 评評 诅詛 识識 诈詐 诉訴 诊診 诋詆 词詞
+诎詘 诏詔 译譯 诒詒 诓誆 诔誄 试試 诗詩 诘詰 诙詼 诚誠 诛誅 诜詵 话話 诞誕 诟詬 诠詮 诡詭 询詢 诣詣
+诤諍 该該 详詳 诧詫 诨諢 诩詡 诫誡 诬誣 语語 诮誚 误誤 诰誥 诱誘 诲誨 诳誑 说說 诵誦 请請 诸諸 诹諏
+诺諾 读讀 诼諑 诽誹 课課 诿諉 谀諛 谁誰 谂諗 调調 谄諂 谅諒 谆諄 谇誶 谈談 谊誼 谋謀 谌諶 谍諜 谎謊
+谏諫 谐諧 谑謔 谒謁 谓謂 谔諤 谕諭 谖諼 谗讒 谘諮 谙諳 谚諺 谛諦 谜謎 谝諞 谟謨 谠讜 谡謖 谢謝 谣謠
+谤謗 谥謚 谦謙 谧謐 谨謹 谩謾 谪謫 谬謬 谭譚 谮譖 谯譙 谰讕 谱譜 谲譎 谳讞 谴譴 谵譫 谶讖 诌謅
+
+钆釓 钇釔 针針 钉釘 钊釗 钋釙 钌釕 钍釷 钎釺 钏釧 钐釤 钒釩 钓釣 钔鍆 钕釹 钗釵 钙鈣 钚鈈 钛鈦 钜鉅
+钝鈍 钞鈔 钠鈉 钡鋇 钢鋼 钣鈑 钤鈐 钥鑰 钦欽 钧鈞 钨鎢 钩鉤 钪鈧 钫鈁 钬鈥 钭鈄 钮鈕 钯鈀 钰鈺 钱錢
+钲鉦 钳鉗 钴鈷 钵鉢 钶鈳 钷鉕 钸鈽 钹鈸 钺鉞 钻鑽 钼鉬 钽鉭 钾鉀 钿鈿 铀鈾 铁鐵 铂鉑 铃鈴 铄鑠 铅鉛
+铆鉚 铈鈰 铉鉉 铊鉈 铋鉍 铌鈮 铍鈹 铎鐸 铐銬 铑銠 铒鉺 铕銪 铖鋮 铗鋏 铘鋣 铙鐃 铛鐺 铜銅 铝鋁 铟銦
+铠鎧 铡鍘 铢銖 铣銑 铤鋌 铥銩 铧鏵 铨銓 铩鎩 铪鉿 铫銚 铬鉻 铭銘 铮錚 铯銫 铰鉸 铱銥 铲鏟 铳銃 铴鐋
+铵銨 银銀 铷銣 铸鑄 铹鐒 铺鋪 铼錸 链鏈 铿鏗 销銷 锁鎖 锂鋰 锄鋤 锅鍋 锆鋯 锇鋨 锈鏽 锉銼 锋鋒 锌鋅
+锍鋶 锎鐦 锏鐧 锐銳 锑銻 锒鋃 锓鋟 锔鋦 锕錒 锖錆 锗鍺 错錯 锚錨 锛錛 锞錁 锟錕 锡錫 锢錮 锣鑼 锤錘
+锥錐 锦錦 锨鍁 锩錈 锬錟 锭錠 键鍵 锯鋸 锰錳 锱錙 锲鍥 锴鍇 锵鏘 锶鍶 锷鍔 锸鍤 锹鍬 锺鍾 锻鍛 锼鎪
+锾鍰 锿鎄 镀鍍 镁鎂 镂鏤 镄鐨 镅鎇 镆鏌 镇鎮 镉鎘 镊鑷 镌鐫 镍鎳 镏鎦 镐鎬 镑鎊 镒鎰 镓鎵 镔鑌 镖鏢
+镗鏜 镘鏝 镙鏍 镛鏞 镜鏡 镝鏑 镞鏃 镟鏇 镡鐔 镢钁 镣鐐 镤鏷 镦鐓 镧鑭 镨鐠 镪鏹 镫鐙 镬鑊 镭鐳 镯鐲
+镰鐮 镱鐿 镲鑔 镳鑣 镶鑲
+
+纠糾 纡紆 红紅 纣紂 纥紇 约約 级級 纨紈 纩纊 纪紀 纫紉 纬緯 纭紜 纯純 纰紕 纱紗 纲綱 纳納 纵縱 纶綸
+纷紛 纸紙 纹紋 纺紡 纽紐 纾紓 线線 绀紺 绁紲 绂紱 练練 组組 绅紳 细細 织織 终終 绉縐 绊絆 绋紼 绌絀
+绍紹 绎繹 经經 绐紿 绑綁 绒絨 结結 绔絝 绕繞 绗絎 绘繪 给給 绚絢 绛絳 络絡 绝絕 绞絞 统統 绠綆 绡綃
+绢絹 绣繡 绥綏 绦絛 继繼 绨綈 绩績 绪緒 绫綾 续續 绮綺 绯緋 绰綽 绲緄 绳繩 维維 绵綿 绶綬 绷繃 绸綢
+绺綹 绻綣 综綜 绽綻 绾綰 绿綠 缀綴 缁緇 缂緙 缃緗 缄緘 缅緬 缆纜 缇緹 缈緲 缉緝 缋繢 缌緦 缎緞 缑緱
+缒縋 缓緩 缔締 缕縷 编編 缗緡 缘緣 缙縉 缚縛 缛縟 缜縝 缝縫 缟縞 缠纏 缡縭 缢縊 缣縑 缤繽 缥縹 缦縵
+缧縲 缨纓 缩縮 缪繆 缫繅 缬纈 缭繚 缮繕 缯繒 缰韁 缱繾 缲繰 缳繯 缴繳
+
+饦飥 饧餳 饨飩 饩餼 饪飪 饫飫 饬飭 饭飯 饮飲 饯餞 饰飾 饱飽 饲飼 饴飴 饵餌 饶饒 饷餉 饺餃 饼餅 饽餑
+饿餓 馀餘 馁餒 馄餛 馅餡 馈饋 馊餿 馋饞 馍饃 馏餾 馐饈 馑饉 馒饅 馔饌 馕饢
+
+贝貝 贞貞 负負 贡貢 财財 责責 贤賢 败敗 账賬 货貨 质質 贩販 贪貪 贫貧 贬貶 购購 贮貯 贯貫 贰貳 贱賤
+贲賁 贳貰 贴貼 贵貴 贶貺 贷貸 贸貿 费費 贺賀 贻貽 贼賊 贽贄 贾賈 贿賄 赀貲 赁賃 赂賂 资資 赅賅 赆贐
+赇賕 赈賑 赉賚 赊賒 赋賦 赌賭 赍齎 赎贖 赏賞 赐賜 赓賡 赔賠 赕賧 赖賴 赘贅 赙賻 赚賺 赛賽 赜賾 赝贗
+赞贊 赠贈 赡贍 赢贏 赣贛
+
+车車 轧軋 轨軌 轩軒 轫軔 转轉 轭軛 轮輪 软軟 轰轟 轱軲 轲軻 轳轤 轴軸 轵軹 轶軼 轸軫 轹轢 轺軺 轻輕
+轼軾 载載 轾輊 轿轎 辁輇 辂輅 较較 辄輒 辅輔 辆輛 辇輦 辈輩 辉輝 辊輥 辋輞 辍輟 辎輜 辏輳 辐輻 辑輯
+输輸 辔轡 辕轅 辖轄 辗輾 辘轆 辙轍 辚轔
+
+门門 闩閂 闪閃 闫閆 闭閉 问問 闯闖 闰閏 闱闈 闳閎 间間 闵閔 闶閌 闷悶 闸閘 闹鬧 闺閨 闻聞 闼闥 闽閩
+闾閭 阀閥 阁閣 阂閡 阃閫 阄鬮 阅閱 阆閬 阈閾 阉閹 阊閶 阋鬩 阌閿 阍閽 阎閻 阏閼 阐闡 阑闌 阒闃 阔闊
+阕闋 阖闔 阗闐 阙闕 阚闞
+
+马馬 驭馭 驮馱 驯馴 驰馳 驱驅 驳駁 驴驢 驵駔 驶駛 驷駟 驸駙 驹駒 驺騶 驻駐 驼駝 驽駑 驾駕 驿驛 骀駘
+骁驍 骂罵 骄驕 骅驊 骆駱 骇駭 骈駢 骊驪 骋騁 验驗 骏駿 骐騏 骑騎 骒騍 骓騅 骖驂 骗騙 骘騭 骚騷 骛騖
+骜驁 骝騮 骞騫 骟騸 骠驃 骡騾 骢驄 骣驏 骤驟 骥驥 骧驤
+
+鸟鳥 鸠鳩 鸡雞 鸢鳶 鸣鳴 鸥鷗 鸦鴉 鸨鴇 鸩鴆 鸪鴣 鸫鶇 鸬鸕 鸭鴨 鸯鴦 鸱鴟 鸲鴝 鸳鴛 鸵鴕 鸶鷥 鸷鷙
+鸸鴯 鸹鴰 鸺鵂 鸽鴿 鸾鸞 鸿鴻 鹁鵓 鹂鸝 鹃鵑 鹄鵠 鹅鵝 鹆鵒 鹇鷴 鹈鵜 鹉鵡 鹊鵲 鹋鶓 鹌鵪 鹎鵯 鹏鵬
+鹑鶉 鹕鶘 鹗鶚 鹘鶻 鹚鶿 鹜鶩 鹞鷂 鹤鶴 鹦鸚 鹧鷓 鹩鷯 鹪鷦 鹫鷲 鹬鷸 鹭鷺 鹰鷹 鹳鸛
+
+鱼魚 鱿魷 鲁魯 鲂魴 鲅鮁 鲆鮃 鲇鯰 鲈鱸 鲋鮒 鲍鮑 鲎鱟 鲐鮐 鲑鮭 鲒鮚 鲔鮪 鲕鮞 鲚鱭 鲛鮫 鲜鮮 鲞鯗
+鲟鱘 鲠鯁 鲡鱺 鲢鰱 鲣鰹 鲤鯉 鲥鰣 鲦鰷 鲧鯀 鲨鯊 鲩鯇 鲫鯽 鲭鯖 鲮鯪 鲰鯫 鲱鯡 鲲鯤 鲳鯧 鲵鯢
+鲷鯛 鲸鯨 鲻鯔 鲽鰈 鳃鰓 鳄鱷 鳅鰍 鳆鰒 鳇鰉 鳊鯿 鳌鰲 鳍鰭 鳎鰨 鳏鰥 鳐鰩 鳔鰾 鳕鱈 鳖鱉 鳗鰻 鳘鰵
+鳙鱅 鳜鱖 鳝鱔 鳞鱗 鳟鱒
+
+页頁 顶頂 顷頃 项項 顺順 顼頊 顽頑 顾顧 顿頓 颀頎 颁頒 颂頌 颃頏 预預 颅顱 领領 颇頗 颈頸 颉頡 颊頰
+颌頜 颍潁 颏頦 颐頤 频頻 颓頹 颔頷 颖穎 颗顆 题題 颚顎 颜顏 额額 颞顳 颟顢 颠顛 颡顙 颢顥 颤顫 颦顰
+颧顴
+
+见見 观觀 规規 觅覓 视視 觇覘 览覽 觉覺 觊覬 觋覡 觌覿 觎覦 觏覯 觐覲 觑覷 风風 飒颯 飓颶 飕颼 飘飄
+飙飆 韦韋 韧韌 韩韓 韪韙 韬韜 龙龍 齿齒 龀齔 龃齟 龄齡 龅齙 龆齠 龇齜 龈齦 龉齬 龊齪 龋齲 龌齷
+`
+
+// stPhrases 简体词语到繁体词语，覆盖字表中一简对多繁的字
+const stPhrases = `
+头发:頭髮 理发:理髮 白发:白髮 毛发:毛髮 发型:髮型 发廊:髮廊 长发:長髮 短发:短髮 卷发:捲髮 假发:假髮
+染发:染髮 发丝:髮絲 发夹:髮夾 皇后:皇后 太后:太后 王后:王后 后妃:后妃 后土:后土 干净:乾淨 干燥:乾燥
+饼干:餅乾 干杯:乾杯 干旱:乾旱 干脆:乾脆 晒干:曬乾 干货:乾貨 干枯:乾枯 干涸:乾涸 干粮:乾糧 干冰:乾冰
+干爹:乾爹 干妈:乾媽 干涉:干涉 干扰:干擾 若干:若干 干预:干預 相干:相干 公里:公里 英里:英里 海里:海里
+里程:里程 邻里:鄰里 故里:故里 千里:千里 万里:萬里 面条:麵條 面包:麵包 面粉:麵粉 方便面:方便麵 拉面:拉麵
+炒面:炒麵 面食:麵食 汤面:湯麵 一只:一隻 两只:兩隻 几只:幾隻 台风:颱風 柜台:櫃檯 台球:檯球 关系:關係
+联系:聯繫 维系:維繫 复杂:複雜 重复:重複 复制:複製 复数:複數 复印:複印 复习:複習 繁复:繁複 反复:反覆
+答复:答覆 尽管:儘管 尽量:儘量 尽快:儘快 尽早:儘早 日历:日曆 历法:曆法 农历:農曆 阳历:陽曆 阴历:陰曆
+挂历:掛曆 钟情:鍾情 钟爱:鍾愛 词汇:詞彙 汇编:彙編 汇总:彙總 批准:批准 准许:准許 不准:不准 准予:准予
+制造:製造 制作:製作 制品:製品 绘制:繪製 研制:研製 录制:錄製 印制:印製 特制:特製 收获:收穫 范围:範圍
+规范:規範 模范:模範 示范:示範 范例:範例 防范:防範 典范:典範 范畴:範疇 北斗:北斗 漏斗:漏斗 熨斗:熨斗
+星斗:星斗 斗胆:斗膽 茶几:茶几 冲洗:沖洗 冲泡:沖泡 冲凉:沖涼 冲澡:沖澡 游泳:游泳 上游:上游 下游:下游
+手表:手錶 钟表:鐘錶 表带:錶帶 怀表:懷錶 了解:瞭解 凭借:憑藉 稻谷:稻穀 谷物:穀物 五谷:五穀 谷子:穀子
+沈阳:瀋陽 公布:公佈 宣布:宣佈 分布:分佈 发布:發佈 布置:佈置 布局:佈局 伙伴:夥伴 合伙:合夥 团伙:團夥
+风采:風采 神采:神采 文采:文采 无精打采:無精打采 兴高采烈:興高采烈 折叠:摺疊 占卜:占卜 占星:占星
+周末:週末 周年:週年 周刊:週刊 周期:週期 每周:每週 上周:上週 下周:下週 本周:本週 一周:一週 两周:兩週
+周一:週一 周二:週二 周三:週三 周四:週四 周五:週五 周六:週六 周日:週日 呼吁:呼籲 五岳:五嶽 恶心:噁心
+苏醒:甦醒 胡须:鬍鬚 胡子:鬍子 划船:划船 划算:划算 萝卜:蘿蔔 委托:委託 托付:託付 拜托:拜託 寄托:寄託
+注释:註釋 注册:註冊 注解:註解 备注:備註 心脏:心臟 内脏:內臟 脏器:臟器 肾脏:腎臟 肝脏:肝臟 标签:標籤
+书签:書籤 抽签:抽籤 宿舍:宿舍 校舍:校舍 舍友:舍友 寒舍:寒舍 复辟:復辟 辟邪:辟邪 细致:細緻 精致:精緻
+别致:別緻 景致:景緻 咸丰:咸豐 咸阳:咸陽 防御:防禦 抵御:抵禦 小丑:小丑 丑角:丑角 卷起:捲起 席卷:席捲
+卷入:捲入 放松:放鬆 轻松:輕鬆 松开:鬆開 松散:鬆散 蓬松:蓬鬆 宽松:寬鬆 松弛:鬆弛 松懈:鬆懈 老板:老闆
+特征:特徵 象征:象徵 征求:徵求 征兆:徵兆 征收:徵收 征集:徵集 人云亦云:人云亦云 合并:合併 兼并:兼併
+吞并:吞併 包扎:包紮 驻扎:駐紮 古迹:古蹟 事迹:事蹟 奇迹:奇蹟 其余:其餘 剩余:剩餘 多余:多餘 业余:業餘
+余额:餘額 余下:餘下 残余:殘餘 余地:餘地 郁闷:鬱悶 忧郁:憂鬱 抑郁:抑鬱 郁郁:鬱鬱 占据:佔據 须臾:須臾
+`
+
+// tsExtraCharacters 字表之外的繁体字到简体字（词语表中的繁体字和异体字），优先于字表的反向映射
+const tsExtraCharacters = `
+髮发 乾干 臟脏 鬚须 鬍胡 麵面 隻只 佈布 託托 註注 徵征 摺折 彙汇 製制 複复 曆历 儘尽 夥伙 週周 蔔卜
+鬆松 範范 噁恶 甦苏 籤签 緻致 禦御 捲卷 闆板 穀谷 瀋沈 錶表 籲吁 嶽岳 穫获 紮扎 颱台 檯台 係系 繫系
+蹟迹 併并 餘余 鍾钟 鬱郁 裡里 鑑鉴 衞卫 啓启 麪面 綫线 爲为 僞伪 衆众 説说 峯峰 羣群
+`
+
+// tsPhrases 繁体词语到简体词语，保留字表会错误转换的简体写法
+const tsPhrases = `
+著作:著作 著名:著名 顯著:显著 著稱:著称 名著:名著 原著:原著 土著:土著 卓著:卓著 著者:著者 編著:编著
+乾隆:乾隆 乾坤:乾坤 瞭望:瞭望 慰藉:慰藉 狼藉:狼藉 覆蓋:覆盖 顛覆:颠覆 覆滅:覆灭 天翻地覆:天翻地覆
+`
+
+// twVariants 通用繁体字到台湾常用字形
+const twVariants = `裏裡 鑒鑑`
+
+// hkVariants 通用繁体字到香港常用字形
+const hkVariants = `衛衞 啟啓 鑒鑑`
+
+// twPhrases 繁体词语到台湾用语（在简体转繁体之后执行）
+const twPhrases = `
+軟件:軟體 硬件:硬體 信息:資訊 網絡:網路 互聯網:網際網路 程序:程式 打印:列印 打印機:印表機 鼠標:滑鼠 出租車:計程車
+自行車:腳踏車 視頻:影片 硬盤:硬碟 內存:記憶體 服務器:伺服器 數據庫:資料庫 默認:預設 缺省:預設 博客:部落格 短信:簡訊
+菠蘿:鳳梨 土豆:馬鈴薯 激光:雷射 方便麵:泡麵 屏幕:螢幕 光盤:光碟 U盤:隨身碟 文件夾:資料夾 數碼:數位 在線:線上
+用戶:使用者 界面:介面 菜單:選單 悉尼:雪梨 新西蘭:紐西蘭 奔馳:賓士 公交車:公車 地鐵:捷運 摩托車:機車
+幼兒園:幼稚園
+`
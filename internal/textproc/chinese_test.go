@@ -0,0 +1,146 @@
+package textproc
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestChineseConverter_Convert 测试各种转换方式的字词转换
+func TestChineseConverter_Convert(t *testing.T) {
+	c, err := NewChineseConverter("", false, nil)
+	if err != nil {
+		t.Fatalf("NewChineseConverter() error = %v", err)
+	}
+
+	tests := []struct {
+		conversion, input, want string
+	}{
+		{ConversionS2T, "头发很长，发展很快", "頭髮很長，發展很快"},
+		{ConversionS2T, "这里的面包", "這裏的麵包"},
+		{ConversionS2TW, "这里的面包", "這裡的麵包"},
+		{ConversionS2TWP, "这里的软件和网络", "這裡的軟體和網路"},
+		{ConversionS2HK, "保卫", "保衞"},
+		{ConversionT2S, "頭髮和著作", "头发和著作"},
+		{ConversionT2S, "剩餘的時鐘", "剩余的时钟"},
+		{ConversionTW2SP, "這裡的軟體", "这里的软件"},
+		{ConversionS2T, "Hello, 123", "Hello, 123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.conversion+"/"+tt.input, func(t *testing.T) {
+			conv, err := c.Select(tt.conversion, "")
+			if err != nil {
+				t.Fatalf("Select() error = %v", err)
+			}
+			if got := conv.Convert(tt.input); got != tt.want {
+				t.Errorf("Convert(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestChineseConverter_Select 测试按请求和语音的语言选择转换方式
+func TestChineseConverter_Select(t *testing.T) {
+	c, err := NewChineseConverter("", true, map[string]string{"zh-CN-liaoning": "none", "zh-HK": "S2T"})
+	if err != nil {
+		t.Fatalf("NewChineseConverter() error = %v", err)
+	}
+
+	tests := []struct {
+		name, requested, locale, want string
+	}{
+		{"大陆语音转简体", "", "zh-CN", ConversionT2S},
+		{"方言语音按前缀匹配", "", "zh-CN-shaanxi", ConversionT2S},
+		{"配置覆盖方言语音", "", "zh-CN-liaoning", ""},
+		{"配置覆盖默认方式", "", "zh-HK", ConversionS2T},
+		{"台湾语音", "auto", "zh-TW", ConversionS2TW},
+		{"其他语言不转换", "", "en-US", ""},
+		{"请求优先", "s2twp", "zh-CN", ConversionS2TWP},
+		{"请求关闭转换", "none", "zh-TW", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := c.Select(tt.requested, tt.locale)
+			if err != nil {
+				t.Fatalf("Select() error = %v", err)
+			}
+			got := ""
+			if conv != nil {
+				got = conv.Name()
+			}
+			if got != tt.want {
+				t.Errorf("Select(%q, %q) = %q, want %q", tt.requested, tt.locale, got, tt.want)
+			}
+		})
+	}
+
+	manual, _ := NewChineseConverter("", false, nil)
+	if conv, _ := manual.Select("", "zh-TW"); conv != nil {
+		t.Errorf("未开启自动转换时不应转换, got %q", conv.Name())
+	}
+	if _, err := c.Select("s2x", "zh-CN"); !errors.Is(err, ErrInvalidConversion) {
+		t.Errorf("未知转换方式期望 ErrInvalidConversion, got %v", err)
+	}
+	if _, err := NewChineseConverter("", true, map[string]string{"zh-TW": "s2x"}); !errors.Is(err, ErrInvalidConversion) {
+		t.Errorf("配置未知转换方式期望 ErrInvalidConversion, got %v", err)
+	}
+}
+
+// TestChineseConverter_DictDir 测试加载 OpenCC 格式的词典，词典中的词条优先于内置词表
+func TestChineseConverter_DictDir(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("STPhrases.txt", "# 自定义词语\n头发\t頭髮\n乾坤大挪移\t乾坤大挪移\n")
+	write("TWPhrases.txt", "軟件\t軟件 軟體\n")
+
+	c, err := NewChineseConverter(dir, false, nil)
+	if err != nil {
+		t.Fatalf("NewChineseConverter() error = %v", err)
+	}
+	conv, _ := c.Select(ConversionS2TWP, "")
+	if got, want := conv.Convert("软件"), "軟件"; got != want {
+		t.Errorf("词典词条应优先, got %q, want %q", got, want)
+	}
+	conv, _ = c.Select(ConversionS2T, "")
+	if got, want := conv.Convert("乾坤大挪移"), "乾坤大挪移"; got != want {
+		t.Errorf("Convert() = %q, want %q", got, want)
+	}
+
+	write("HKVariants.txt", "衛 衞\n")
+	if _, err := NewChineseConverter(dir, false, nil); !errors.Is(err, ErrInvalidConversion) {
+		t.Errorf("格式错误的词典期望 ErrInvalidConversion, got %v", err)
+	}
+	if _, err := NewChineseConverter(filepath.Join(dir, "missing"), false, nil); !errors.Is(err, ErrInvalidConversion) {
+		t.Errorf("不存在的词典目录期望 ErrInvalidConversion, got %v", err)
+	}
+}
+
+// TestChineseTables 检查内置词表的格式：字表每项两个字且不重复，词语表每项为 “原文:译文”
+func TestChineseTables(t *testing.T) {
+	for name, table := range map[string]string{"stCharacters": stCharacters, "tsExtraCharacters": tsExtraCharacters, "twVariants": twVariants, "hkVariants": hkVariants} {
+		seen := make(map[string]bool)
+		for _, entry := range strings.Fields(table) {
+			if utf8.RuneCountInString(entry) != 2 {
+				t.Errorf("%s: 词条 %q 应为两个字", name, entry)
+			}
+			if seen[entry] {
+				t.Errorf("%s: 词条 %q 重复", name, entry)
+			}
+			seen[entry] = true
+		}
+	}
+	for name, table := range map[string]string{"stPhrases": stPhrases, "tsPhrases": tsPhrases, "twPhrases": twPhrases} {
+		for _, entry := range strings.Fields(table) {
+			if from, to, ok := strings.Cut(entry, ":"); !ok || from == "" || to == "" {
+				t.Errorf("%s: 词条 %q 应为 原文:译文", name, entry)
+			}
+		}
+	}
+}
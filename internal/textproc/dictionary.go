@@ -0,0 +1,204 @@
+package textproc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"tts/internal/models"
+)
+
+// ErrInvalidDictionary 表示发音词典的词条无效
+var ErrInvalidDictionary = errors.New("invalid dictionary entry")
+
+// pinyinSyllable 带声调数字的拼音音节，如 chong2、lv4、lü4（5 为轻声）
+var pinyinSyllable = regexp.MustCompile(`(?i)([a-zü]+|[a-z]*u:[a-z]*)\s*([1-5])`)
+
+// Entry 发音词典中的一个词条，pinyin、ipa 和 sub 必须且只能指定一个
+type Entry struct {
+	Term   string `yaml:"term" json:"term"`
+	Pinyin string `yaml:"pinyin,omitempty" json:"pinyin,omitempty"` // 带声调数字的拼音，如 "chong2 qing4"
+	IPA    string `yaml:"ipa,omitempty" json:"ipa,omitempty"`       // 国际音标
+	Sub    string `yaml:"sub,omitempty" json:"sub,omitempty"`       // 替换读法，如 W3C -> 万维网联盟
+}
+
+// annotate 将词条写入片段，拼音转换为 SSML 的 sapi 音标
+func (e Entry) annotate(span *models.Span) {
+	switch {
+	case e.Pinyin != "":
+		span.Phoneme, span.Alphabet = sapiPinyin(e.Pinyin), "sapi"
+	case e.IPA != "":
+		span.Phoneme, span.Alphabet = e.IPA, "ipa"
+	default:
+		span.Sub = e.Sub
+	}
+}
+
+// validate 校验词条并去掉首尾空白
+func (e *Entry) validate() error {
+	e.Term = strings.TrimSpace(e.Term)
+	e.Pinyin = strings.TrimSpace(e.Pinyin)
+	e.IPA = strings.TrimSpace(e.IPA)
+	e.Sub = strings.TrimSpace(e.Sub)
+	if e.Term == "" {
+		return fmt.Errorf("%w: term is required", ErrInvalidDictionary)
+	}
+
+	readings := 0
+	for _, reading := range []string{e.Pinyin, e.IPA, e.Sub} {
+		if reading != "" {
+			readings++
+		}
+	}
+	if readings != 1 {
+		return fmt.Errorf("%w: %q must have exactly one of pinyin, ipa and sub", ErrInvalidDictionary, e.Term)
+	}
+	if e.Pinyin != "" && strings.TrimSpace(pinyinSyllable.ReplaceAllString(e.Pinyin, "")) != "" {
+		return fmt.Errorf("%w: %q has invalid pinyin %q", ErrInvalidDictionary, e.Term, e.Pinyin)
+	}
+	return nil
+}
+
+// sapiPinyin 将 "chong2 qing4" 转换为 sapi 音标 "chong 2 qing 4"，ü 写作 v
+func sapiPinyin(pinyin string) string {
+	var syllables []string
+	for _, m := range pinyinSyllable.FindAllStringSubmatch(pinyin, -1) {
+		syllable := strings.ToLower(m[1])
+		syllable = strings.NewReplacer("ü", "v", "u:", "v").Replace(syllable)
+		syllables = append(syllables, syllable+" "+m[2])
+	}
+	return strings.Join(syllables, " ")
+}
+
+// Dictionary 编译后的发音词典，同一位置优先匹配最长的词条
+type Dictionary struct {
+	entries map[rune][]Entry // 首字 -> 词条，按长度降序
+	size    int
+}
+
+// NewDictionary 编译词条，后出现的同名词条覆盖先出现的
+func NewDictionary(entries []Entry) (*Dictionary, error) {
+	byTerm := make(map[string]Entry, len(entries))
+	for _, e := range entries {
+		if err := e.validate(); err != nil {
+			return nil, err
+		}
+		byTerm[e.Term] = e
+	}
+
+	d := &Dictionary{entries: make(map[rune][]Entry), size: len(byTerm)}
+	for _, e := range byTerm {
+		first, _ := utf8.DecodeRuneInString(e.Term)
+		d.entries[first] = append(d.entries[first], e)
+	}
+	for _, list := range d.entries {
+		sort.Slice(list, func(i, j int) bool {
+			if len(list[i].Term) != len(list[j].Term) {
+				return len(list[i].Term) > len(list[j].Term)
+			}
+			return list[i].Term < list[j].Term
+		})
+	}
+	return d, nil
+}
+
+// Len 返回词条数量
+func (d *Dictionary) Len() int {
+	if d == nil {
+		return 0
+	}
+	return d.size
+}
+
+// Apply 在片段文本中查找词条，匹配的部分拆分为带音标或替换读法的片段
+//
+// 拆分出的片段继承原片段的语音和韵律参数，停顿只保留在最后一个片段之后；
+// 已指定读法、音标或替换读法的片段保持不变。返回标注后的片段和匹配次数。
+func (d *Dictionary) Apply(spans []models.Span) ([]models.Span, int) {
+	if d.Len() == 0 {
+		return spans, 0
+	}
+
+	result := make([]models.Span, 0, len(spans))
+	matches := 0
+	for _, span := range spans {
+		if span.Text == "" || span.SayAs != "" || span.Phoneme != "" || span.Sub != "" {
+			result = append(result, span)
+			continue
+		}
+
+		pieces, n := d.split(span)
+		matches += n
+		result = append(result, pieces...)
+	}
+	return result, matches
+}
+
+// split 按词条拆分一个片段
+func (d *Dictionary) split(span models.Span) ([]models.Span, int) {
+	text := span.Text
+	var pieces []models.Span
+	matches := 0
+	plainStart := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		entry, ok := d.longestAt(text, i, r)
+		if !ok {
+			i += size
+			continue
+		}
+
+		if plainStart < i {
+			plain := span
+			plain.Text, plain.Break = text[plainStart:i], ""
+			pieces = append(pieces, plain)
+		}
+		annotated := span
+		annotated.Text, annotated.Break = entry.Term, ""
+		entry.annotate(&annotated)
+		pieces = append(pieces, annotated)
+		matches++
+		i += len(entry.Term)
+		plainStart = i
+	}
+
+	if matches == 0 {
+		return []models.Span{span}, 0
+	}
+	if plainStart < len(text) {
+		plain := span
+		plain.Text, plain.Break = text[plainStart:], ""
+		pieces = append(pieces, plain)
+	}
+	pieces[len(pieces)-1].Break = span.Break
+	return pieces, matches
+}
+
+// longestAt 返回 text[i:] 开头能匹配的最长词条
+//
+// 以 ASCII 字母或数字开头（结尾）的词条要求前（后）一个字符不是 ASCII 单词字符，
+// 避免 AI 匹配 MAIL、SAID 中的字母；中文词条不受影响。
+func (d *Dictionary) longestAt(text string, i int, first rune) (Entry, bool) {
+	for _, e := range d.entries[first] {
+		if !strings.HasPrefix(text[i:], e.Term) {
+			continue
+		}
+		if isASCIIWord(first) && i > 0 && isASCIIWord(rune(text[i-1])) {
+			continue
+		}
+		last, _ := utf8.DecodeLastRuneInString(e.Term)
+		if end := i + len(e.Term); isASCIIWord(last) && end < len(text) && isASCIIWord(rune(text[end])) {
+			continue
+		}
+		return e, true
+	}
+	return Entry{}, false
+}
+
+// isASCIIWord 判断是否为 ASCII 字母、数字或下划线（与正则 \b 的单词字符一致）
+func isASCIIWord(r rune) bool {
+	return r == '_' || r < utf8.RuneSelf && (r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+}
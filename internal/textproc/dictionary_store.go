@@ -0,0 +1,283 @@
+package textproc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
+)
+
+// dictionaryFile 词典文件格式：全局词条和按 API 密钥划分的词条
+type dictionaryFile struct {
+	Global []Entry            `yaml:"global"`
+	Keys   map[string][]Entry `yaml:"keys,omitempty"`
+}
+
+// DictionaryStore 管理全局和按 API 密钥划分的发音词典，API 密钥不区分大小写
+//
+// 词条保存在 YAML 文件中，通过 API 修改后写回文件；Watch 定期检查文件的修改时间，
+// 文件被外部修改后自动重新加载。path 为空时词典只保存在内存中。
+type DictionaryStore struct {
+	path string
+
+	mu      sync.RWMutex
+	data    dictionaryFile
+	global  *Dictionary
+	keys    map[string]*Dictionary // 小写的 API 密钥 -> 合并全局词条后的词典
+	modTime time.Time
+}
+
+// NewDictionaryStore 创建词典存储并加载词典文件，文件不存在时从空词典开始
+func NewDictionaryStore(path string) (*DictionaryStore, error) {
+	s := &DictionaryStore{path: path}
+	if err := s.compile(dictionaryFile{}); err != nil {
+		return nil, err
+	}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// For 返回 API 密钥使用的词典（全局词条加上该密钥的词条，同名时密钥的词条优先）
+func (s *DictionaryStore) For(apiKey string) *Dictionary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if d, ok := s.keys[foldKey(apiKey)]; ok && apiKey != "" {
+		return d
+	}
+	return s.global
+}
+
+// Entries 返回词条，apiKey 为空时返回全局词条
+func (s *DictionaryStore) Entries(apiKey string) []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries := s.data.Global
+	if apiKey != "" {
+		entries = s.data.Keys[foldKey(apiKey)]
+	}
+	return append([]Entry(nil), entries...)
+}
+
+// Put 添加或更新词条（按 term 匹配）并写回词典文件
+func (s *DictionaryStore) Put(apiKey string, entries []Entry) error {
+	for i := range entries {
+		if err := entries[i].validate(); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data := s.clone()
+	data.setEntries(apiKey, mergeEntries(data.entries(apiKey), entries))
+	return s.commit(data)
+}
+
+// Delete 删除词条并写回词典文件，词条不存在时返回 false
+func (s *DictionaryStore) Delete(apiKey, term string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data := s.clone()
+	list := data.entries(apiKey)
+	for i := range list {
+		if list[i].Term == term {
+			data.setEntries(apiKey, append(list[:i], list[i+1:]...))
+			return true, s.commit(data)
+		}
+	}
+	return false, nil
+}
+
+// Reload 文件修改时间变化时重新加载词典文件，返回是否重新加载
+func (s *DictionaryStore) Reload() (bool, error) {
+	if s.path == "" {
+		return false, nil
+	}
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("stat dictionary: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if info.ModTime().Equal(s.modTime) {
+		return false, nil
+	}
+	raw, err := os.ReadFile(s.path)
+	if err != nil {
+		return false, fmt.Errorf("read dictionary: %w", err)
+	}
+	var data dictionaryFile
+	if err := yaml.Unmarshal(raw, &data); err != nil {
+		return false, fmt.Errorf("%w: parse %s: %v", ErrInvalidDictionary, s.path, err)
+	}
+	data.Keys = foldKeys(data.Keys)
+	if err := s.compile(data); err != nil {
+		return false, err
+	}
+	s.modTime = info.ModTime()
+	return true, nil
+}
+
+// Watch 每隔 interval 检查词典文件，文件变化时重新加载；加载失败时保留当前词典。
+// 返回的函数停止检查。
+func (s *DictionaryStore) Watch(interval time.Duration, logger zerolog.Logger) func() {
+	if s.path == "" || interval <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				reloaded, err := s.Reload()
+				if err != nil {
+					logger.Error().Err(err).Str("path", s.path).Msg("Failed to reload pronunciation dictionary")
+				} else if reloaded {
+					logger.Info().Str("path", s.path).Msg("Pronunciation dictionary reloaded")
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// compile 编译全部词典并替换当前数据，调用方需持有写锁
+func (s *DictionaryStore) compile(data dictionaryFile) error {
+	global, err := NewDictionary(data.Global)
+	if err != nil {
+		return err
+	}
+	keys := make(map[string]*Dictionary, len(data.Keys))
+	for key, entries := range data.Keys {
+		merged := append(append([]Entry(nil), data.Global...), entries...)
+		if keys[key], err = NewDictionary(merged); err != nil {
+			return err
+		}
+	}
+	s.data, s.global, s.keys = data, global, keys
+	return nil
+}
+
+// commit 写回文件后替换当前数据，写入失败时保留当前词典；调用方需持有写锁
+func (s *DictionaryStore) commit(data dictionaryFile) error {
+	if s.path == "" {
+		return s.compile(data)
+	}
+
+	raw, err := yaml.Marshal(&data)
+	if err != nil {
+		return fmt.Errorf("encode dictionary: %w", err)
+	}
+	// 先写临时文件再重命名，避免 Watch 读到写了一半的文件
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".dictionary-*.yaml")
+	if err != nil {
+		return fmt.Errorf("write dictionary: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write dictionary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write dictionary: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("write dictionary: %w", err)
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return s.compile(data)
+}
+
+// clone 复制当前数据，修改失败时不影响正在使用的词典
+func (s *DictionaryStore) clone() dictionaryFile {
+	data := dictionaryFile{
+		Global: append([]Entry(nil), s.data.Global...),
+		Keys:   make(map[string][]Entry, len(s.data.Keys)),
+	}
+	for key, entries := range s.data.Keys {
+		data.Keys[key] = append([]Entry(nil), entries...)
+	}
+	return data
+}
+
+// entries 返回 API 密钥对应的词条列表，apiKey 为空时返回全局词条
+func (f *dictionaryFile) entries(apiKey string) []Entry {
+	if apiKey == "" {
+		return f.Global
+	}
+	return f.Keys[foldKey(apiKey)]
+}
+
+// setEntries 替换 API 密钥对应的词条列表，密钥的词条为空时删除该密钥
+func (f *dictionaryFile) setEntries(apiKey string, entries []Entry) {
+	switch {
+	case apiKey == "":
+		f.Global = entries
+	case len(entries) == 0:
+		delete(f.Keys, foldKey(apiKey))
+	default:
+		f.Keys[foldKey(apiKey)] = entries
+	}
+}
+
+// foldKey 返回 API 密钥的小写形式，与 ssml.voices 和 replace.keys 相同，密钥不区分大小写
+func foldKey(apiKey string) string {
+	return strings.ToLower(strings.TrimSpace(apiKey))
+}
+
+// foldKeys 将词典文件中的 API 密钥改为小写，只有大小写不同的密钥合并为一个，
+// 同名词条按密钥排序后靠后的优先
+func foldKeys(keys map[string][]Entry) map[string][]Entry {
+	if len(keys) == 0 {
+		return keys
+	}
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	folded := make(map[string][]Entry, len(keys))
+	for _, name := range names {
+		key := foldKey(name)
+		folded[key] = mergeEntries(folded[key], keys[name])
+	}
+	return folded
+}
+
+// mergeEntries 将 entries 合并到 list：同名（按 term 匹配）的词条被覆盖，其他词条追加到末尾
+func mergeEntries(list, entries []Entry) []Entry {
+	for _, e := range entries {
+		replaced := false
+		for i := range list {
+			if list[i].Term == e.Term {
+				list[i] = e
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			list = append(list, e)
+		}
+	}
+	return list
+}
@@ -0,0 +1,192 @@
+package textproc
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tts/internal/models"
+)
+
+// TestDictionary_Apply 测试最长匹配、属性继承和停顿位置
+func TestDictionary_Apply(t *testing.T) {
+	d, err := NewDictionary([]Entry{
+		{Term: "银行", Pinyin: "yin2 hang2"},
+		{Term: "银行行长", Pinyin: "yin2 hang2 hang2 zhang3"},
+		{Term: "W3C", Sub: "万维网联盟"},
+		{Term: "tomato", IPA: "təˈmɑːtoʊ"},
+		{Term: "绿", Pinyin: "lü4"},
+	})
+	if err != nil {
+		t.Fatalf("NewDictionary() error = %v", err)
+	}
+
+	got, matches := d.Apply([]models.Span{
+		{Text: "银行行长在W3C说", Voice: "zh-CN-YunxiNeural", Rate: "10", Break: "500ms"},
+		{Text: "2024", SayAs: "date"},
+		{Text: "tomato绿"},
+	})
+	want := []models.Span{
+		{Text: "银行行长", Voice: "zh-CN-YunxiNeural", Rate: "10", Phoneme: "yin 2 hang 2 hang 2 zhang 3", Alphabet: "sapi"},
+		{Text: "在", Voice: "zh-CN-YunxiNeural", Rate: "10"},
+		{Text: "W3C", Voice: "zh-CN-YunxiNeural", Rate: "10", Sub: "万维网联盟"},
+		{Text: "说", Voice: "zh-CN-YunxiNeural", Rate: "10", Break: "500ms"},
+		{Text: "2024", SayAs: "date"},
+		{Text: "tomato", Phoneme: "təˈmɑːtoʊ", Alphabet: "ipa"},
+		{Text: "绿", Phoneme: "lv 4", Alphabet: "sapi"},
+	}
+	if matches != 4 {
+		t.Errorf("matches = %d, want 4", matches)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Apply() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("片段 %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if spans, n := d.Apply([]models.Span{{Text: "行走"}}); n != 0 || len(spans) != 1 {
+		t.Errorf("无匹配时应原样返回, got %+v", spans)
+	}
+}
+
+// TestDictionary_WordBoundary 测试 ASCII 词条只在单词边界处匹配
+func TestDictionary_WordBoundary(t *testing.T) {
+	d, err := NewDictionary([]Entry{{Term: "AI", Sub: "人工智能"}})
+	if err != nil {
+		t.Fatalf("NewDictionary() error = %v", err)
+	}
+	for _, text := range []string{"MAIL", "SAID", "AI2", "_AI"} {
+		if _, n := d.Apply([]models.Span{{Text: text}}); n != 0 {
+			t.Errorf("%q 不应匹配 AI", text)
+		}
+	}
+	for _, text := range []string{"AI", "用AI写作", "AI, MAIL and AI.", "(AI)"} {
+		if _, n := d.Apply([]models.Span{{Text: text}}); n == 0 {
+			t.Errorf("%q 应匹配 AI", text)
+		}
+	}
+}
+
+// TestNewDictionary_Invalid 测试非法词条
+func TestNewDictionary_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry Entry
+	}{
+		{"缺少词条", Entry{Pinyin: "a1"}},
+		{"缺少读音", Entry{Term: "重庆"}},
+		{"多个读音", Entry{Term: "重庆", Pinyin: "chong2 qing4", Sub: "崇庆"}},
+		{"拼音缺少声调", Entry{Term: "重庆", Pinyin: "chong qing"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewDictionary([]Entry{tt.entry}); !errors.Is(err, ErrInvalidDictionary) {
+				t.Errorf("NewDictionary() error = %v, want ErrInvalidDictionary", err)
+			}
+		})
+	}
+}
+
+// TestDictionaryStore 测试全局与按密钥的词条、写回文件和重新加载
+func TestDictionaryStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dictionary.yaml")
+	initial := "global:\n  - term: 重庆\n    pinyin: chong2 qing4\n"
+	if err := os.WriteFile(path, []byte(initial), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewDictionaryStore(path)
+	if err != nil {
+		t.Fatalf("NewDictionaryStore() error = %v", err)
+	}
+	if s.For("").Len() != 1 {
+		t.Fatalf("全局词条数 = %d, want 1", s.For("").Len())
+	}
+
+	// 按密钥添加词条，覆盖同名的全局词条
+	if err := s.Put("key-a", []Entry{{Term: "重庆", Sub: "崇庆"}, {Term: "行长", Pinyin: "hang2 zhang3"}}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	spans, _ := s.For("key-a").Apply([]models.Span{{Text: "重庆行长"}})
+	if len(spans) != 2 || spans[0].Sub != "崇庆" || spans[1].Phoneme != "hang 2 zhang 3" {
+		t.Errorf("密钥词典 = %+v", spans)
+	}
+	if s.For("key-b").Len() != 1 {
+		t.Errorf("未配置的密钥应使用全局词典")
+	}
+	if err := s.Put("", []Entry{{Term: "重庆"}}); !errors.Is(err, ErrInvalidDictionary) {
+		t.Errorf("非法词条期望 ErrInvalidDictionary, got %v", err)
+	}
+
+	// 写回的文件可以重新加载
+	reopened, err := NewDictionaryStore(path)
+	if err != nil {
+		t.Fatalf("NewDictionaryStore() error = %v", err)
+	}
+	if got := reopened.Entries("key-a"); len(got) != 2 {
+		t.Errorf("重新打开后的密钥词条 = %+v", got)
+	}
+
+	if deleted, err := s.Delete("key-a", "行长"); err != nil || !deleted {
+		t.Errorf("Delete() = %v, %v", deleted, err)
+	}
+	if deleted, _ := s.Delete("", "不存在"); deleted {
+		t.Error("删除不存在的词条应返回 false")
+	}
+
+	// 外部修改文件后重新加载
+	updated := "global:\n  - term: 重庆\n    pinyin: chong2 qing4\n  - term: 长安\n    pinyin: chang2 an1\n"
+	if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := s.Reload(); err != nil || !reloaded {
+		t.Fatalf("Reload() = %v, %v", reloaded, err)
+	}
+	if s.For("").Len() != 2 || s.For("key-a").Len() != 2 {
+		t.Errorf("重新加载后词条数 = %d/%d", s.For("").Len(), s.For("key-a").Len())
+	}
+
+	// 文件内容无效时保留当前词典
+	if err := os.WriteFile(path, []byte("global: [{term: 重庆}]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	future = future.Add(time.Minute)
+	_ = os.Chtimes(path, future, future)
+	if _, err := s.Reload(); !errors.Is(err, ErrInvalidDictionary) {
+		t.Errorf("Reload() error = %v, want ErrInvalidDictionary", err)
+	}
+	if s.For("").Len() != 2 {
+		t.Errorf("加载失败后应保留当前词典")
+	}
+}
+
+// TestDictionaryStore_KeyCase 测试 API 密钥不区分大小写，文件中只有大小写不同的密钥合并
+func TestDictionaryStore_KeyCase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dictionary.yaml")
+	content := "global: []\nkeys:\n  Key-A:\n    - term: 重庆\n      sub: 崇庆\n  key-a:\n    - term: 行长\n      pinyin: hang2 zhang3\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewDictionaryStore(path)
+	if err != nil {
+		t.Fatalf("NewDictionaryStore() error = %v", err)
+	}
+	if got := s.For("KEY-A").Len(); got != 2 {
+		t.Errorf("For(KEY-A) 词条数 = %d, want 2", got)
+	}
+	if err := s.Put("key-A", []Entry{{Term: "重庆", Pinyin: "chong2 qing4"}}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got := s.Entries("Key-a"); len(got) != 2 || got[0].Pinyin != "chong2 qing4" {
+		t.Errorf("Entries(Key-a) = %+v", got)
+	}
+}
@@ -0,0 +1,169 @@
+package textproc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"tts/internal/models"
+)
+
+// ErrInvalidDirective 表示行内指令无效
+var ErrInvalidDirective = errors.New("invalid directive")
+
+// directiveToken 匹配 [name]、[name:arg] 和 [/name]，前面带反斜杠时按原文输出
+var directiveToken = regexp.MustCompile(`\\?\[(/?)([a-z]+)(?::([^\[\]\n]*))?\]`)
+
+// directiveArg 指令参数的要求
+type directiveArg int
+
+const (
+	argRequired directiveArg = iota
+	argOptional
+	argNone
+)
+
+// directive 一种行内指令。除 pause 外的指令作用于其后的文本，直到对应的结束标记或文本末尾
+type directive struct {
+	arg      directiveArg
+	fallback string // argOptional 时的默认参数
+	apply    func(span *models.Span, arg string)
+}
+
+var directives = map[string]directive{
+	"pause":    {arg: argRequired},
+	"voice":    {arg: argRequired, apply: func(s *models.Span, v string) { s.Voice = v }},
+	"style":    {arg: argRequired, apply: func(s *models.Span, v string) { s.Style = v }},
+	"role":     {arg: argRequired, apply: func(s *models.Span, v string) { s.Role = v }},
+	"rate":     {arg: argRequired, apply: func(s *models.Span, v string) { s.Rate = v }},
+	"pitch":    {arg: argRequired, apply: func(s *models.Span, v string) { s.Pitch = v }},
+	"volume":   {arg: argRequired, apply: func(s *models.Span, v string) { s.Volume = v }},
+	"lang":     {arg: argRequired, apply: func(s *models.Span, v string) { s.Lang = v }},
+	"emphasis": {arg: argOptional, fallback: "moderate", apply: func(s *models.Span, v string) { s.Emphasis = v }},
+	"spell":    {arg: argNone, apply: func(s *models.Span, _ string) { s.SayAs = "characters" }},
+}
+
+// DirectiveResult 行内指令解析结果
+type DirectiveResult struct {
+	Text  string        // 去掉指令后的纯文本，供不能使用 SSML 的请求使用
+	Spans []models.Span // 结构化片段；文本不含指令时为 nil
+}
+
+// openDirective 尚未结束的指令
+type openDirective struct {
+	name string
+	arg  string
+}
+
+// ParseDirectives 解析文本中的行内指令：
+//
+//	[pause:800ms]                      在此处停顿（时长或 strong 等强度），不能与另一个停顿相邻
+//	[voice:zh-CN-YunxiNeural]...[/voice] 切换语音，style、role、rate、pitch、volume、lang 用法相同
+//	[emphasis]...[/emphasis]           强调，可指定级别如 [emphasis:strong]
+//	[spell]ABC[/spell]                 逐字朗读
+//
+// 未结束的指令作用到文本末尾。[name:arg] 和 [/name] 形式的未知指令返回错误，
+// 不带参数的未知 [name] 和 \[ 转义的指令按原文朗读。参数值由 SSML 编译时校验。
+func ParseDirectives(text string) (DirectiveResult, error) {
+	var (
+		spans  []models.Span
+		stack  []openDirective
+		buf    strings.Builder
+		plain  strings.Builder
+		parsed bool
+	)
+	invalid := func(offset int, format string, args ...interface{}) error {
+		pos := utf8.RuneCountInString(text[:offset]) + 1
+		return fmt.Errorf("%w: at character %d: %s", ErrInvalidDirective, pos, fmt.Sprintf(format, args...))
+	}
+	write := func(s string) {
+		buf.WriteString(s)
+		plain.WriteString(s)
+	}
+	flush := func() {
+		if buf.Len() == 0 {
+			return
+		}
+		var span models.Span
+		for _, open := range stack {
+			directives[open.name].apply(&span, open.arg)
+		}
+		span.Text = buf.String()
+		spans = append(spans, span)
+		buf.Reset()
+	}
+
+	pos := 0
+	for _, m := range directiveToken.FindAllStringSubmatchIndex(text, -1) {
+		write(text[pos:m[0]])
+		pos = m[1]
+		token := text[m[0]:m[1]]
+		if strings.HasPrefix(token, `\`) {
+			write(token[1:])
+			continue
+		}
+
+		closing := m[3] > m[2]
+		name := text[m[4]:m[5]]
+		hasArg := m[6] >= 0
+		arg := ""
+		if hasArg {
+			arg = strings.TrimSpace(text[m[6]:m[7]])
+		}
+
+		d, known := directives[name]
+		if !known {
+			if closing || hasArg {
+				return DirectiveResult{}, invalid(m[0], "unknown directive %q", token)
+			}
+			write(token)
+			continue
+		}
+		parsed = true
+		flush()
+
+		switch {
+		case closing:
+			if hasArg || name == "pause" {
+				return DirectiveResult{}, invalid(m[0], "invalid closing directive %q", token)
+			}
+			i := len(stack) - 1
+			for i >= 0 && stack[i].name != name {
+				i--
+			}
+			if i < 0 {
+				return DirectiveResult{}, invalid(m[0], "%q has no matching [%s]", token, name)
+			}
+			stack = append(stack[:i], stack[i+1:]...)
+		case d.arg == argRequired && arg == "":
+			return DirectiveResult{}, invalid(m[0], "directive %q requires a value, e.g. [%s:value]", token, name)
+		case d.arg == argNone && hasArg:
+			return DirectiveResult{}, invalid(m[0], "directive %q takes no value", token)
+		case name == "pause":
+			// 停顿加在前一个片段之后，文本开头的停顿单独成为片段。
+			// 两个停顿之间没有文本时无法分别表示，不合并以免时长超出预期
+			switch {
+			case len(spans) == 0:
+				spans = append(spans, models.Span{Break: arg})
+			case spans[len(spans)-1].Break != "":
+				return DirectiveResult{}, invalid(m[0], "consecutive pauses at %q, use a single [pause]", token)
+			default:
+				spans[len(spans)-1].Break = arg
+			}
+		default:
+			if arg == "" {
+				arg = d.fallback
+			}
+			stack = append(stack, openDirective{name: name, arg: arg})
+		}
+	}
+	write(text[pos:])
+	flush()
+
+	if !parsed {
+		return DirectiveResult{Text: plain.String()}, nil
+	}
+	return DirectiveResult{Text: plain.String(), Spans: spans}, nil
+}
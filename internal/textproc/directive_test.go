@@ -0,0 +1,98 @@
+package textproc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"tts/internal/models"
+	"tts/internal/ssml"
+)
+
+// TestParseDirectives 测试停顿、嵌套指令、逐字朗读和转义
+func TestParseDirectives(t *testing.T) {
+	text := `[pause:200ms]你好[pause:800ms][voice:zh-CN-YunxiNeural]我是[rate:+20]云希[/rate]，` +
+		`编号[spell]ABC[/spell][/voice][style:cheerful]见到你[emphasis]真高兴[/emphasis]\[pause:1s][注]`
+	got, err := ParseDirectives(text)
+	if err != nil {
+		t.Fatalf("ParseDirectives() error = %v", err)
+	}
+
+	const yunxi = "zh-CN-YunxiNeural"
+	want := []models.Span{
+		{Break: "200ms"},
+		{Text: "你好", Break: "800ms"},
+		{Text: "我是", Voice: yunxi},
+		{Text: "云希", Voice: yunxi, Rate: "+20"},
+		{Text: "，编号", Voice: yunxi},
+		{Text: "ABC", Voice: yunxi, SayAs: "characters"},
+		{Text: "见到你", Style: "cheerful"},
+		{Text: "真高兴", Style: "cheerful", Emphasis: "moderate"},
+		{Text: "[pause:1s][注]", Style: "cheerful"},
+	}
+	if len(got.Spans) != len(want) {
+		t.Fatalf("Spans = %+v, want %+v", got.Spans, want)
+	}
+	for i := range want {
+		if got.Spans[i] != want[i] {
+			t.Errorf("片段 %d = %+v, want %+v", i, got.Spans[i], want[i])
+		}
+	}
+	if got.Text != "你好我是云希，编号ABC见到你真高兴[pause:1s][注]" {
+		t.Errorf("Text = %q", got.Text)
+	}
+}
+
+// TestParseDirectives_Escape 测试指令参数和文本在编译为 SSML 时被转义
+func TestParseDirectives_Escape(t *testing.T) {
+	got, err := ParseDirectives(`a<b [voice:x"><y]&c`)
+	if err != nil {
+		t.Fatalf("ParseDirectives() error = %v", err)
+	}
+	compiled, err := ssml.Compile(models.TTSRequest{Voice: "zh-CN-XiaoxiaoNeural", Spans: got.Spans})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	for _, want := range []string{"a&lt;b ", `<voice name="x&quot;&gt;&lt;y">&amp;c`} {
+		if !strings.Contains(compiled, want) {
+			t.Errorf("SSML = %s, want containing %s", compiled, want)
+		}
+	}
+}
+
+// TestParseDirectives_PlainText 测试不含指令的文本原样返回
+func TestParseDirectives_PlainText(t *testing.T) {
+	for _, text := range []string{"参考文献[1]和[注]", "数组 a[i] 的值", "[文档](https://example.com)"} {
+		got, err := ParseDirectives(text)
+		if err != nil || got.Spans != nil || got.Text != text {
+			t.Errorf("ParseDirectives(%q) = %+v, %v", text, got, err)
+		}
+	}
+}
+
+// TestParseDirectives_Invalid 测试未知指令和不匹配的结束标记
+func TestParseDirectives_Invalid(t *testing.T) {
+	tests := []struct {
+		name, text, message string
+	}{
+		{"未知指令", "你好[speed:fast]", `at character 3: unknown directive "[speed:fast]"`},
+		{"未知结束标记", "你好[/speed]", "unknown directive"},
+		{"缺少参数", "[voice]你好", "requires a value"},
+		{"多余参数", "[spell:on]ABC", "takes no value"},
+		{"不匹配的结束标记", "你好[/rate]", "has no matching [rate]"},
+		{"停顿没有结束标记", "你好[/pause]", "invalid closing directive"},
+		{"相邻的停顿", "你好[pause:500ms][pause:800ms]世界", `at character 16: consecutive pauses at "[pause:800ms]"`},
+		{"开头相邻的停顿", "[pause:1s][voice:zh-CN-YunxiNeural][pause:1s]你好", "consecutive pauses"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDirectives(tt.text)
+			if !errors.Is(err, ErrInvalidDirective) {
+				t.Fatalf("error = %v, want ErrInvalidDirective", err)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error = %q, want containing %q", err, tt.message)
+			}
+		})
+	}
+}
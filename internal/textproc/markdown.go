@@ -0,0 +1,349 @@
+package textproc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"tts/internal/models"
+)
+
+// ErrInvalidMarkdown 表示 Markdown 处理参数无效
+var ErrInvalidMarkdown = errors.New("invalid markdown options")
+
+// MarkdownMode Markdown 的处理方式
+type MarkdownMode string
+
+const (
+	// MarkdownOff 不处理，原样朗读
+	MarkdownOff MarkdownMode = "off"
+	// MarkdownPlain 删除全部 *、#、>、~ 符号（旧行为）
+	MarkdownPlain MarkdownMode = "plain"
+	// MarkdownRender 解析文档结构，渲染为带停顿的 SSML
+	MarkdownRender MarkdownMode = "markdown"
+)
+
+// 代码块的处理方式
+const (
+	CodeSkip     = "skip"
+	CodeAnnounce = "announce"
+)
+
+// ParseMarkdownMode 解析 Markdown 处理方式，空字符串返回 fallback
+func ParseMarkdownMode(mode string, fallback MarkdownMode) (MarkdownMode, error) {
+	switch MarkdownMode(mode) {
+	case "":
+		return fallback, nil
+	case MarkdownOff, MarkdownPlain, MarkdownRender:
+		return MarkdownMode(mode), nil
+	}
+	return "", fmt.Errorf("%w: unknown mode %q", ErrInvalidMarkdown, mode)
+}
+
+// MarkdownOptions Markdown 渲染参数
+type MarkdownOptions struct {
+	HeadingPause   time.Duration // 标题后的停顿
+	ParagraphPause time.Duration // 段落、引用、代码块和分隔线后的停顿
+	ListPause      time.Duration // 列表项和表格行之间的停顿
+	Emphasis       bool          // 标题和粗体使用 <emphasis> 强调
+	Code           string        // 代码块：skip 跳过，announce 提示已省略
+}
+
+// MarkdownResult Markdown 渲染结果
+type MarkdownResult struct {
+	Text  string        // 纯文本形式，块之间以换行分隔，供不能使用 SSML 的请求使用
+	Spans []models.Span // 带停顿的结构化片段；文本不含 Markdown 结构时为 nil
+}
+
+var (
+	markdownStripper = strings.NewReplacer("**", "", "*", "", "#", "", ">", "", "~", "")
+
+	headingLine   = regexp.MustCompile(`^ {0,3}(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+	ruleLine      = regexp.MustCompile(`^ {0,3}([-*_])(?:[ \t]*([-*_])){2,}[ \t]*$`)
+	fenceLine     = regexp.MustCompile("^ {0,3}(```+|~~~+)")
+	quoteLine     = regexp.MustCompile(`^ {0,3}>[ \t]?`)
+	listItemLine  = regexp.MustCompile(`^[ \t]*(?:[-*+]|\d{1,9}[.)])[ \t]+(.*)$`)
+	tableDelimRow = regexp.MustCompile(`^[ \t]*\|?[ \t]*:?-{3,}:?[ \t]*(?:\|[ \t]*:?-{3,}:?[ \t]*)*\|?[ \t]*$`)
+
+	imagePattern  = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkPattern   = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	autoLink      = regexp.MustCompile(`<(?:https?|mailto):[^>\s]+>`)
+	codeSpan      = regexp.MustCompile("`+([^`]+)`+")
+	strikePattern = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	emPattern     = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+	strongPattern = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__`)
+)
+
+// StripMarkdown 删除文本中全部的 *、#、>、~ 符号（plain 模式）
+func StripMarkdown(text string) string {
+	return markdownStripper.Replace(text)
+}
+
+// blockKind Markdown 块的类型
+type blockKind int
+
+const (
+	blockParagraph blockKind = iota
+	blockHeading
+	blockListItem
+	blockCode
+	blockTableRow
+	blockRule
+)
+
+type block struct {
+	kind blockKind
+	text string
+}
+
+// RenderMarkdown 解析 Markdown 文档：标题后停顿更长（可选强调），列表项和表格行之间以停顿分隔，
+// 代码块跳过或提示，链接和图片只读文字。文本中的 C#、3 > 2、~50% 等不属于 Markdown 语法的符号保持不变。
+func RenderMarkdown(text, locale string, opts MarkdownOptions) MarkdownResult {
+	blocks, structured := parseBlocks(text)
+	if !structured {
+		return MarkdownResult{Text: text}
+	}
+
+	var spans []models.Span
+	var lines []string
+	for _, b := range blocks {
+		pause := opts.ParagraphPause
+		var emphasis string
+		switch b.kind {
+		case blockHeading:
+			pause = opts.HeadingPause
+			if opts.Emphasis {
+				emphasis = "strong"
+			}
+		case blockListItem, blockTableRow:
+			pause = opts.ListPause
+		case blockRule:
+			spans = appendPause(spans, opts.ParagraphPause)
+			continue
+		case blockCode:
+			if opts.Code != CodeAnnounce {
+				continue
+			}
+			b.text = codeLabel(locale)
+		}
+
+		segments := inlineSegments(b.text)
+		if len(segments) == 0 {
+			continue
+		}
+		plain := ""
+		for _, seg := range segments {
+			span := models.Span{Text: seg.text, Emphasis: emphasis}
+			if seg.strong && opts.Emphasis && emphasis == "" {
+				span.Emphasis = "moderate"
+			}
+			spans = append(spans, span)
+			plain += seg.text
+		}
+		spans = appendPause(spans, pause)
+		if b.kind != blockParagraph {
+			plain = terminate(plain, locale)
+		}
+		lines = append(lines, plain)
+	}
+	return MarkdownResult{Text: strings.Join(lines, "\n"), Spans: spans}
+}
+
+// MarkdownInline 删除一行文本中的行内 Markdown 标记（粗体、斜体、删除线、行内代码、链接）
+func MarkdownInline(text string) string {
+	var b strings.Builder
+	for _, seg := range inlineSegments(text) {
+		b.WriteString(seg.text)
+	}
+	return b.String()
+}
+
+// parseBlocks 将文本拆分为块，返回文本是否包含 Markdown 结构
+func parseBlocks(text string) ([]block, bool) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var blocks []block
+	var paragraph []string
+	structured := false
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, block{kind: blockParagraph, text: strings.Join(paragraph, "\n")})
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case fenceLine.MatchString(line):
+			flush()
+			fence := strings.TrimLeft(fenceLine.FindStringSubmatch(line)[1], " ")
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, lines[i])
+			}
+			blocks = append(blocks, block{kind: blockCode, text: strings.Join(code, "\n")})
+			structured = true
+		case headingLine.MatchString(line):
+			flush()
+			blocks = append(blocks, block{kind: blockHeading, text: headingLine.FindStringSubmatch(line)[2]})
+			structured = true
+		case ruleLine.MatchString(line) && sameRuleMarks(line):
+			flush()
+			blocks = append(blocks, block{kind: blockRule})
+			structured = true
+		case strings.Contains(line, "|") && i+1 < len(lines) && tableDelimRow.MatchString(lines[i+1]):
+			flush()
+			blocks = append(blocks, block{kind: blockTableRow, text: tableRow(line)})
+			for i += 2; i < len(lines) && strings.Contains(lines[i], "|"); i++ {
+				blocks = append(blocks, block{kind: blockTableRow, text: tableRow(lines[i])})
+			}
+			i--
+			structured = true
+		case listItemLine.MatchString(line):
+			flush()
+			blocks = append(blocks, block{kind: blockListItem, text: listItemLine.FindStringSubmatch(line)[1]})
+			structured = true
+		case quoteLine.MatchString(line):
+			// 引用去掉标记后按段落朗读，嵌套引用逐层去掉
+			for quoteLine.MatchString(line) {
+				line = quoteLine.ReplaceAllString(line, "")
+			}
+			paragraph = append(paragraph, line)
+			structured = true
+		default:
+			paragraph = append(paragraph, line)
+			if !structured && MarkdownInline(line) != line {
+				structured = true
+			}
+		}
+	}
+	flush()
+	return blocks, structured
+}
+
+// sameRuleMarks 分隔线必须由同一种符号组成
+func sameRuleMarks(line string) bool {
+	marks := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, line)
+	return strings.Count(marks, marks[:1]) == len(marks)
+}
+
+// tableRow 将表格行的单元格以逗号连接
+func tableRow(line string) string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(strings.TrimSuffix(line, "|"), "|")
+	var cells []string
+	for _, cell := range strings.Split(line, "|") {
+		if cell = strings.TrimSpace(cell); cell != "" {
+			cells = append(cells, cell)
+		}
+	}
+	return strings.Join(cells, "，")
+}
+
+// inlineSegment 行内文本片段，strong 表示粗体
+type inlineSegment struct {
+	text   string
+	strong bool
+}
+
+// inlineSegments 去掉行内标记，按粗体拆分为片段
+func inlineSegments(text string) []inlineSegment {
+	text = imagePattern.ReplaceAllString(text, "$1")
+	text = linkPattern.ReplaceAllString(text, "$1")
+	text = autoLink.ReplaceAllString(text, "")
+	text = codeSpan.ReplaceAllString(text, "$1")
+	text = strikePattern.ReplaceAllString(text, "$1")
+
+	var segments []inlineSegment
+	add := func(s string, strong bool) {
+		s = stripEmphasis(s)
+		if strings.TrimSpace(s) != "" {
+			segments = append(segments, inlineSegment{text: s, strong: strong})
+		}
+	}
+	pos := 0
+	for _, m := range strongPattern.FindAllStringSubmatchIndex(text, -1) {
+		add(text[pos:m[0]], false)
+		if m[2] >= 0 {
+			add(text[m[2]:m[3]], true)
+		} else {
+			add(text[m[4]:m[5]], true)
+		}
+		pos = m[1]
+	}
+	add(text[pos:], false)
+	return segments
+}
+
+// stripEmphasis 去掉单个 * 的斜体标记。* 紧挨 ASCII 字母或数字的外侧时不是强调，
+// 例如 2*3*4、a*b*c 保持不变
+func stripEmphasis(s string) string {
+	var b strings.Builder
+	pos := 0
+	for _, m := range emPattern.FindAllStringSubmatchIndex(s, -1) {
+		if m[0] > 0 && isASCIIWord(rune(s[m[0]-1])) || m[1] < len(s) && isASCIIWord(rune(s[m[1]])) {
+			continue
+		}
+		b.WriteString(s[pos:m[0]])
+		b.WriteString(s[m[2]:m[3]])
+		pos = m[1]
+	}
+	if pos == 0 {
+		return s
+	}
+	b.WriteString(s[pos:])
+	return b.String()
+}
+
+// appendPause 把停顿加在最后一个片段之后，已有停顿时取较长者
+func appendPause(spans []models.Span, pause time.Duration) []models.Span {
+	if pause <= 0 || len(spans) == 0 {
+		return spans
+	}
+	last := &spans[len(spans)-1]
+	if existing, ok := breakDuration(last.Break); !ok || existing < pause {
+		last.Break = strconv.FormatInt(pause.Milliseconds(), 10) + "ms"
+	}
+	return spans
+}
+
+// breakDuration 解析以毫秒表示的停顿
+func breakDuration(value string) (time.Duration, bool) {
+	ms, err := strconv.Atoi(strings.TrimSuffix(value, "ms"))
+	if err != nil || !strings.HasSuffix(value, "ms") {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// terminate 文本没有以标点结尾时补上句号，纯文本朗读时保留块之间的停顿
+func terminate(text, locale string) string {
+	last, _ := utf8.DecodeLastRuneInString(strings.TrimSpace(text))
+	if last == utf8.RuneError || unicode.IsPunct(last) {
+		return text
+	}
+	if strings.HasPrefix(strings.ToLower(locale), "zh") {
+		return text + "。"
+	}
+	return text + "."
+}
+
+// codeLabel 代码块的提示语
+func codeLabel(locale string) string {
+	if strings.HasPrefix(strings.ToLower(locale), "zh") {
+		return "此处省略代码"
+	}
+	return "Code omitted"
+}
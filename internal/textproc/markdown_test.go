@@ -0,0 +1,95 @@
+package textproc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"tts/internal/models"
+)
+
+var testMarkdownOptions = MarkdownOptions{
+	HeadingPause:   700 * time.Millisecond,
+	ParagraphPause: 400 * time.Millisecond,
+	ListPause:      300 * time.Millisecond,
+	Emphasis:       true,
+	Code:           CodeSkip,
+}
+
+// TestRenderMarkdown_PlainText 测试不含 Markdown 结构的文本原样返回
+func TestRenderMarkdown_PlainText(t *testing.T) {
+	for _, text := range []string{"我在学 C# 语言", "3 > 2 成立", "涨幅约 ~50%", "第一行\n第二行"} {
+		got := RenderMarkdown(text, "zh-CN", testMarkdownOptions)
+		if got.Text != text || got.Spans != nil {
+			t.Errorf("RenderMarkdown(%q) = %+v, want 原样返回", text, got)
+		}
+	}
+}
+
+// TestRenderMarkdown_Document 测试标题、列表、表格、代码块和链接
+func TestRenderMarkdown_Document(t *testing.T) {
+	text := "# 使用说明\n\n安装后阅读[文档](https://example.com)，**务必**备份。\n\n" +
+		"- 第一步\n- 第二步\n\n```go\nfmt.Println(1)\n```\n\n| 名称 | 价格 |\n| --- | --- |\n| 苹果 | 5元 |\n\n---\n> 引用的话"
+	got := RenderMarkdown(text, "zh-CN", testMarkdownOptions)
+
+	want := []models.Span{
+		{Text: "使用说明", Emphasis: "strong", Break: "700ms"},
+		{Text: "安装后阅读文档，"},
+		{Text: "务必", Emphasis: "moderate"},
+		{Text: "备份。", Break: "400ms"},
+		{Text: "第一步", Break: "300ms"},
+		{Text: "第二步", Break: "300ms"},
+		{Text: "名称，价格", Break: "300ms"},
+		{Text: "苹果，5元", Break: "400ms"},
+		{Text: "引用的话", Break: "400ms"},
+	}
+	if len(got.Spans) != len(want) {
+		t.Fatalf("Spans = %+v, want %+v", got.Spans, want)
+	}
+	for i := range want {
+		if got.Spans[i] != want[i] {
+			t.Errorf("片段 %d = %+v, want %+v", i, got.Spans[i], want[i])
+		}
+	}
+
+	wantText := "使用说明。\n安装后阅读文档，务必备份。\n第一步。\n第二步。\n名称，价格。\n苹果，5元。\n引用的话"
+	if got.Text != wantText {
+		t.Errorf("Text = %q, want %q", got.Text, wantText)
+	}
+}
+
+// TestRenderMarkdown_CodeAnnounce 测试代码块提示
+func TestRenderMarkdown_CodeAnnounce(t *testing.T) {
+	opts := testMarkdownOptions
+	opts.Code = CodeAnnounce
+	got := RenderMarkdown("Run this:\n\n```\nmake\n```", "en-US", opts)
+	if got.Text != "Run this:\nCode omitted." {
+		t.Errorf("Text = %q", got.Text)
+	}
+	if len(got.Spans) != 2 || got.Spans[1].Text != "Code omitted" {
+		t.Errorf("Spans = %+v", got.Spans)
+	}
+}
+
+// TestStripMarkdown 测试 plain 模式和行内标记
+func TestStripMarkdown(t *testing.T) {
+	if got := StripMarkdown("# **标题** > ~"); got != " 标题  " {
+		t.Errorf("StripMarkdown() = %q", got)
+	}
+	if got := MarkdownInline("A：**你好**，看 `code` 和 ~~旧~~[链接](http://x)"); got != "A：你好，看 code 和 旧链接" {
+		t.Errorf("MarkdownInline() = %q", got)
+	}
+	for in, want := range map[string]string{
+		"2*3*4 等于 24":         "2*3*4 等于 24",
+		"a*b*c":               "a*b*c",
+		"这是*重点*内容":            "这是重点内容",
+		"an *important* word": "an important word",
+	} {
+		if got := MarkdownInline(in); got != want {
+			t.Errorf("MarkdownInline(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if _, err := ParseMarkdownMode("html", MarkdownRender); !errors.Is(err, ErrInvalidMarkdown) {
+		t.Errorf("未知模式期望 ErrInvalidMarkdown, got %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+// Package textproc 在合成前处理请求文本（数字、日期等的规范化读法）
+package textproc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"tts/internal/models"
+)
+
+// ErrInvalidNormalize 表示规范化参数无效（未知模式或规则）
+var ErrInvalidNormalize = errors.New("invalid normalization options")
+
+// Mode 规范化的输出方式
+type Mode string
+
+const (
+	// ModeOff 不做规范化
+	ModeOff Mode = "off"
+	// ModeRewrite 改写为读法文本，如 3.5% -> 百分之三点五
+	ModeRewrite Mode = "rewrite"
+	// ModeSayAs 日期、时间、电话用 say-as 标注，交给上游按类型朗读，其他类型改写
+	ModeSayAs Mode = "say_as"
+)
+
+// 规则名称
+const (
+	RuleDate     = "date"
+	RuleTime     = "time"
+	RulePhone    = "phone"
+	RuleCurrency = "currency"
+	RulePercent  = "percent"
+	RuleUnit     = "unit"
+	RuleOrdinal  = "ordinal"
+	RuleVersion  = "version"
+	RuleNumber   = "number"
+)
+
+// ruleOrder 全部规则名称，各语言的规则按同样的顺序排列，同一位置有多个规则匹配时优先使用靠前的规则
+var ruleOrder = []string{
+	RuleDate, RuleTime, RulePhone, RuleVersion, RuleCurrency,
+	RulePercent, RuleUnit, RuleOrdinal, RuleNumber,
+}
+
+// ParseMode 解析规范化模式，空字符串返回 fallback
+func ParseMode(mode string, fallback Mode) (Mode, error) {
+	switch Mode(mode) {
+	case "":
+		return fallback, nil
+	case ModeOff, ModeRewrite, ModeSayAs:
+		return Mode(mode), nil
+	}
+	return "", fmt.Errorf("%w: unknown mode %q", ErrInvalidNormalize, mode)
+}
+
+// Change 一处规范化改写，供调试接口展示
+type Change struct {
+	Rule        string `json:"rule"`
+	Original    string `json:"original"`
+	Replacement string `json:"replacement"`
+	SayAs       string `json:"say_as,omitempty"`
+}
+
+// Result 规范化结果
+type Result struct {
+	Text    string        // 全部改写为读法后的文本
+	Spans   []models.Span // ModeSayAs 且有 say-as 标注时的结构化片段，否则为 nil
+	Changes []Change
+}
+
+// token 规则匹配到的一处文本
+type token struct {
+	rule        string
+	priority    int
+	start, end  int
+	spoken      string // 改写后的读法
+	interpretAs string // say-as 类型，为空时只能改写
+	format      string
+	sayAsText   string
+}
+
+// rule 一条规范化规则，render 返回 false 时放弃本次匹配
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+	render  func(m []string, t *token) bool
+}
+
+// Normalizer 按语言将数字、日期、时间、货币、百分比、电话、单位、序数和版本号改写为读法
+type Normalizer struct {
+	mode    Mode
+	enabled map[string]bool
+}
+
+// NewNormalizer 创建规范化器，rules 为空时启用全部规则
+func NewNormalizer(mode Mode, rules []string) (*Normalizer, error) {
+	if _, err := ParseMode(string(mode), ModeRewrite); err != nil {
+		return nil, err
+	}
+	if mode == "" {
+		mode = ModeRewrite
+	}
+	enabled := make(map[string]bool, len(ruleOrder))
+	for _, name := range ruleOrder {
+		enabled[name] = len(rules) == 0
+	}
+	for _, name := range rules {
+		if _, ok := enabled[name]; !ok {
+			return nil, fmt.Errorf("%w: unknown rule %q", ErrInvalidNormalize, name)
+		}
+		enabled[name] = true
+	}
+	return &Normalizer{mode: mode, enabled: enabled}, nil
+}
+
+// Mode 返回默认的规范化模式
+func (n *Normalizer) Mode() Mode {
+	return n.mode
+}
+
+// Rules 返回启用的规则名称
+func (n *Normalizer) Rules() []string {
+	var names []string
+	for _, name := range ruleOrder {
+		if n.enabled[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Normalize 按 locale（如 zh-CN、en-US）规范化文本，不支持的语言原样返回
+func (n *Normalizer) Normalize(text, locale string, mode Mode) Result {
+	if mode == "" {
+		mode = n.mode
+	}
+	rules := rulesFor(locale)
+	if mode == ModeOff || rules == nil {
+		return Result{Text: text}
+	}
+
+	tokens := n.match(text, rules)
+	result := Result{Changes: make([]Change, 0, len(tokens))}
+	var rewritten strings.Builder
+	var plain strings.Builder // say-as 模式下尚未输出的普通文本
+	annotated := false
+	pos := 0
+	for _, t := range tokens {
+		rewritten.WriteString(text[pos:t.start])
+		rewritten.WriteString(t.spoken)
+		plain.WriteString(text[pos:t.start])
+		pos = t.end
+
+		change := Change{Rule: t.rule, Original: text[t.start:t.end], Replacement: t.spoken}
+		if mode == ModeSayAs && t.interpretAs != "" {
+			if plain.Len() > 0 {
+				result.Spans = append(result.Spans, models.Span{Text: plain.String()})
+				plain.Reset()
+			}
+			result.Spans = append(result.Spans, models.Span{Text: t.sayAsText, SayAs: t.interpretAs, SayAsFormat: t.format})
+			change.Replacement = t.sayAsText
+			change.SayAs = t.interpretAs
+			annotated = true
+		} else {
+			plain.WriteString(t.spoken)
+		}
+		result.Changes = append(result.Changes, change)
+	}
+	rewritten.WriteString(text[pos:])
+	plain.WriteString(text[pos:])
+	if plain.Len() > 0 {
+		result.Spans = append(result.Spans, models.Span{Text: plain.String()})
+	}
+
+	result.Text = rewritten.String()
+	if !annotated {
+		result.Spans = nil
+	}
+	return result
+}
+
+// match 找出所有启用规则的匹配，重叠时保留起始位置靠前、优先级高的匹配
+func (n *Normalizer) match(text string, rules []rule) []token {
+	var candidates []token
+	for priority, r := range rules {
+		if !n.enabled[r.name] {
+			continue
+		}
+		for _, loc := range r.pattern.FindAllStringSubmatchIndex(text, -1) {
+			m := make([]string, len(loc)/2)
+			for i := range m {
+				if loc[2*i] >= 0 {
+					m[i] = text[loc[2*i]:loc[2*i+1]]
+				}
+			}
+			t := token{rule: r.name, priority: priority, start: loc[0], end: loc[1]}
+			if r.render(m, &t) {
+				candidates = append(candidates, t)
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].start != candidates[j].start {
+			return candidates[i].start < candidates[j].start
+		}
+		return candidates[i].priority < candidates[j].priority
+	})
+
+	var tokens []token
+	end := 0
+	for _, t := range candidates {
+		if t.start < end {
+			continue
+		}
+		tokens = append(tokens, t)
+		end = t.end
+	}
+	return tokens
+}
+
+// rulesFor 返回语言对应的规则，不支持的语言返回 nil
+func rulesFor(locale string) []rule {
+	switch strings.ToLower(strings.SplitN(locale, "-", 2)[0]) {
+	case "zh":
+		return zhRules
+	case "en":
+		return enRules
+	}
+	return nil
+}
+
+// NormalizeSpans 规范化结构化片段的文本；say_as 模式下片段按标注拆分，拆分出的片段继承原片段的参数
+func (n *Normalizer) NormalizeSpans(spans []models.Span, locale string, mode Mode) []models.Span {
+	result := make([]models.Span, 0, len(spans))
+	for _, span := range spans {
+		if span.Text == "" || span.SayAs != "" || span.Phoneme != "" || span.Sub != "" {
+			result = append(result, span)
+			continue
+		}
+
+		normalized := n.Normalize(span.Text, locale, mode)
+		if normalized.Spans == nil {
+			span.Text = normalized.Text
+			result = append(result, span)
+			continue
+		}
+		for i, part := range normalized.Spans {
+			piece := span
+			piece.Text, piece.SayAs, piece.SayAsFormat, piece.Break = part.Text, part.SayAs, part.SayAsFormat, ""
+			if i == len(normalized.Spans)-1 {
+				piece.Break = span.Break
+			}
+			result = append(result, piece)
+		}
+	}
+	return result
+}
@@ -0,0 +1,151 @@
+package textproc
+
+import (
+	"errors"
+	"testing"
+
+	"tts/internal/models"
+)
+
+// TestZhInteger 测试中文整数读法
+func TestZhInteger(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"0", "零"},
+		{"7", "七"},
+		{"10", "十"},
+		{"15", "十五"},
+		{"20", "二十"},
+		{"105", "一百零五"},
+		{"1010", "一千零一十"},
+		{"10010", "一万零一十"},
+		{"100000", "十万"},
+		{"12000000", "一千二百万"},
+		{"100000001", "一亿零一"},
+	}
+	for _, tt := range tests {
+		if got := zhInteger(tt.in); got != tt.want {
+			t.Errorf("zhInteger(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if got := zhNumber("1,234.05"); got != "一千二百三十四点零五" {
+		t.Errorf("zhNumber(1,234.05) = %q", got)
+	}
+	if got := zhNumber("007"); got != "零零七" {
+		t.Errorf("zhNumber(007) = %q", got)
+	}
+}
+
+// TestEnOrdinal 测试英文序数词
+func TestEnOrdinal(t *testing.T) {
+	tests := map[int64]string{
+		1:    "first",
+		2:    "second",
+		12:   "twelfth",
+		20:   "twentieth",
+		22:   "twenty-second",
+		101:  "one hundred first",
+		1000: "one thousandth",
+	}
+	for n, want := range tests {
+		if got := enOrdinal(n); got != want {
+			t.Errorf("enOrdinal(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+// TestNormalize_Rewrite 测试改写为读法
+func TestNormalize_Rewrite(t *testing.T) {
+	n, err := NewNormalizer(ModeRewrite, nil)
+	if err != nil {
+		t.Fatalf("NewNormalizer() error = %v", err)
+	}
+
+	tests := []struct {
+		name, locale, in, want string
+	}{
+		{"日期", "zh-CN", "会议定于2024-01-02举行", "会议定于二零二四年一月二日举行"},
+		{"年份", "zh-CN", "1998年出生", "一九九八年出生"},
+		{"时间", "zh-CN", "14:05出发，18:00到达", "十四点零五分出发，十八点整到达"},
+		{"手机号", "zh-CN", "电话13812345678", "电话幺三八 幺二三四 五六七八"},
+		{"固话", "zh-CN", "拨打010-12345678", "拨打零幺零 幺二三四 五六七八"},
+		{"货币", "zh-CN", "售价￥1,299.5", "售价一千二百九十九点五元"},
+		{"百分比", "zh-CN", "增长3.5%", "增长百分之三点五"},
+		{"单位", "zh-CN", "时速120km/h，气温-5℃", "时速一百二十千米每小时，气温零下五摄氏度"},
+		{"温度区间不是负数", "zh-CN", "3-5℃", "三-五摄氏度"},
+		{"序数", "zh-CN", "第12章", "第十二章"},
+		{"版本号", "zh-CN", "升级到v2.10.1", "升级到V二点十点一"},
+		{"普通数字", "zh-CN", "共有105人", "共有一百零五人"},
+		{"英文日期和时间", "en-US", "Due 2024-03-01 at 14:30", "Due March first, 2024 at 2:30 PM"},
+		{"英文序数和百分比", "en-US", "the 22nd item, up 50%", "the twenty-second item, up 50 percent"},
+		{"英文货币和单位", "en-US", "$20.50 for 1 km", "20 dollars and 50 cents for 1 kilometer"},
+		{"英文电话", "en-US", "call (555) 123-4567", "call 5 5 5, 1 2 3, 4 5 6 7"},
+		{"不支持的语言", "ja-JP", "2024-01-02", "2024-01-02"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := n.Normalize(tt.in, tt.locale, "")
+			if got.Text != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got.Text, tt.want)
+			}
+			if got.Spans != nil {
+				t.Errorf("改写模式不应返回片段: %+v", got.Spans)
+			}
+		})
+	}
+}
+
+// TestNormalize_SayAs 测试 say-as 模式标注日期、时间和电话
+func TestNormalize_SayAs(t *testing.T) {
+	n, _ := NewNormalizer(ModeRewrite, nil)
+
+	got := n.Normalize("2024/1/2 上午9:30 拨打13812345678，优惠50%", "zh-CN", ModeSayAs)
+	want := []models.Span{
+		{Text: "2024-01-02", SayAs: "date", SayAsFormat: "ymd"},
+		{Text: " 上午"},
+		{Text: "9:30", SayAs: "time", SayAsFormat: "hms24"},
+		{Text: " 拨打"},
+		{Text: "13812345678", SayAs: "telephone"},
+		{Text: "，优惠百分之五十"},
+	}
+	if len(got.Spans) != len(want) {
+		t.Fatalf("Spans = %+v, want %+v", got.Spans, want)
+	}
+	for i := range want {
+		if got.Spans[i] != want[i] {
+			t.Errorf("片段 %d = %+v, want %+v", i, got.Spans[i], want[i])
+		}
+	}
+	if len(got.Changes) != 4 || got.Changes[0].SayAs != "date" || got.Changes[3].Rule != RulePercent {
+		t.Errorf("Changes = %+v", got.Changes)
+	}
+
+	// 没有可标注的内容时只改写
+	if got := n.Normalize("增长3.5%", "zh-CN", ModeSayAs); got.Spans != nil || got.Text != "增长百分之三点五" {
+		t.Errorf("Normalize() = %+v", got)
+	}
+}
+
+// TestNewNormalizer 测试规则选择与参数校验
+func TestNewNormalizer(t *testing.T) {
+	n, err := NewNormalizer(ModeRewrite, []string{RulePercent})
+	if err != nil {
+		t.Fatalf("NewNormalizer() error = %v", err)
+	}
+	if got := n.Normalize("第3名，增长5%", "zh-CN", ""); got.Text != "第3名，增长百分之五" {
+		t.Errorf("只启用 percent 规则, got %q", got.Text)
+	}
+	if got := n.Normalize("增长5%", "zh-CN", ModeOff); got.Text != "增长5%" || len(got.Changes) != 0 {
+		t.Errorf("off 模式应原样返回, got %+v", got)
+	}
+
+	if _, err := NewNormalizer(ModeRewrite, []string{"emoji"}); !errors.Is(err, ErrInvalidNormalize) {
+		t.Errorf("未知规则期望 ErrInvalidNormalize, got %v", err)
+	}
+	if _, err := NewNormalizer("loud", nil); !errors.Is(err, ErrInvalidNormalize) {
+		t.Errorf("未知模式期望 ErrInvalidNormalize, got %v", err)
+	}
+}
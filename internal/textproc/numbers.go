@@ -0,0 +1,180 @@
+package textproc
+
+import (
+	"strconv"
+	"strings"
+)
+
+var (
+	zhDigitNames = []string{"零", "一", "二", "三", "四", "五", "六", "七", "八", "九"}
+	zhSmallUnits = []string{"", "十", "百", "千"}
+	zhBigUnits   = []string{"", "万", "亿", "万亿"}
+
+	enOnes = []string{
+		"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+		"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen",
+		"seventeen", "eighteen", "nineteen",
+	}
+	enTens       = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	enScales     = []string{"", "thousand", "million", "billion", "trillion"}
+	enOrdinalMap = map[string]string{
+		"one": "first", "two": "second", "three": "third", "five": "fifth",
+		"eight": "eighth", "nine": "ninth", "twelve": "twelfth",
+	}
+)
+
+// zhDigits 逐位读数字，电话号码中的 1 读作 "幺"
+func zhDigits(digits string, phone bool) string {
+	var b strings.Builder
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			continue
+		}
+		if phone && r == '1' {
+			b.WriteString("幺")
+			continue
+		}
+		b.WriteString(zhDigitNames[r-'0'])
+	}
+	return b.String()
+}
+
+// zhInteger 按中文读法读整数，如 10010 -> 一万零一十；超过万亿的数字逐位读
+func zhInteger(digits string) string {
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" {
+		return "零"
+	}
+	if len(digits) > 16 {
+		return zhDigits(digits, false)
+	}
+
+	// 从高位起每四位一组
+	var groups []string
+	head := len(digits) % 4
+	if head > 0 {
+		groups = append(groups, digits[:head])
+	}
+	for i := head; i < len(digits); i += 4 {
+		groups = append(groups, digits[i:i+4])
+	}
+
+	var b strings.Builder
+	zero := false
+	for gi, group := range groups {
+		if strings.Trim(group, "0") == "" {
+			zero = b.Len() > 0
+			continue
+		}
+		for i, r := range group {
+			d := r - '0'
+			if d == 0 {
+				zero = b.Len() > 0
+				continue
+			}
+			if zero {
+				b.WriteString("零")
+				zero = false
+			}
+			b.WriteString(zhDigitNames[d])
+			b.WriteString(zhSmallUnits[len(group)-1-i])
+		}
+		b.WriteString(zhBigUnits[len(groups)-1-gi])
+	}
+
+	// 10-19 读作 "十X" 而不是 "一十X"
+	spoken := b.String()
+	if strings.HasPrefix(spoken, "一十") {
+		spoken = strings.TrimPrefix(spoken, "一")
+	}
+	return spoken
+}
+
+// zhNumber 按中文读法读数字，支持负号、千分位和小数；以 0 开头的多位数逐位读
+func zhNumber(number string) string {
+	number = strings.ReplaceAll(number, ",", "")
+	prefix := ""
+	if strings.HasPrefix(number, "-") {
+		prefix = "负"
+		number = number[1:]
+	}
+	intPart, fracPart, hasFrac := strings.Cut(number, ".")
+
+	var spoken string
+	if len(intPart) > 1 && intPart[0] == '0' {
+		spoken = zhDigits(intPart, false)
+	} else {
+		spoken = zhInteger(intPart)
+	}
+	if hasFrac && fracPart != "" {
+		spoken += "点" + zhDigits(fracPart, false)
+	}
+	return prefix + spoken
+}
+
+// enInteger 按英文读法读整数，如 1234 -> one thousand two hundred thirty-four
+func enInteger(n int64) string {
+	if n < 0 {
+		return "minus " + enInteger(-n)
+	}
+	if n < 20 {
+		return enOnes[n]
+	}
+
+	var parts []string
+	for scale := len(enScales) - 1; scale >= 0; scale-- {
+		unit := int64(1)
+		for i := 0; i < scale; i++ {
+			unit *= 1000
+		}
+		chunk := n / unit % 1000
+		if chunk == 0 {
+			continue
+		}
+		words := enHundreds(int(chunk))
+		if enScales[scale] != "" {
+			words += " " + enScales[scale]
+		}
+		parts = append(parts, words)
+	}
+	return strings.Join(parts, " ")
+}
+
+// enHundreds 读 1-999
+func enHundreds(n int) string {
+	var parts []string
+	if n >= 100 {
+		parts = append(parts, enOnes[n/100]+" hundred")
+		n %= 100
+	}
+	switch {
+	case n == 0:
+	case n < 20:
+		parts = append(parts, enOnes[n])
+	case n%10 == 0:
+		parts = append(parts, enTens[n/10])
+	default:
+		parts = append(parts, enTens[n/10]+"-"+enOnes[n%10])
+	}
+	return strings.Join(parts, " ")
+}
+
+// enOrdinal 英文序数词，如 22 -> twenty-second
+func enOrdinal(n int64) string {
+	words := enInteger(n)
+	cut := strings.LastIndexAny(words, " -") + 1
+	last := words[cut:]
+	if ordinal, ok := enOrdinalMap[last]; ok {
+		return words[:cut] + ordinal
+	}
+	if strings.HasSuffix(last, "y") {
+		return words[:cut] + strings.TrimSuffix(last, "y") + "ieth"
+	}
+	return words + "th"
+}
+
+// parseInt 解析去掉千分位的整数
+func parseInt(digits string) (int64, bool) {
+	n, err := strconv.ParseInt(strings.ReplaceAll(digits, ",", ""), 10, 64)
+	return n, err == nil
+}
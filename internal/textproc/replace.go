@@ -0,0 +1,194 @@
+package textproc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidRule 表示替换规则或规则集无效
+var ErrInvalidRule = errors.New("invalid replace rule")
+
+// RuleSetNone 请求中指定该规则集名称时不做替换
+const RuleSetNone = "none"
+
+// ReplaceRule 一条文本替换规则，按在规则集中的顺序依次执行
+type ReplaceRule struct {
+	Name        string `json:"name,omitempty"`
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement"`       // 正则规则可使用 $1、${name} 引用分组
+	Regex       bool   `json:"regex,omitempty"`   // 为 false 时按字面文本匹配
+	Scope       string `json:"scope,omitempty"`   // 适用的语言，如 zh、en-US，多个以逗号分隔，为空时适用于全部语言
+	Enabled     *bool  `json:"enabled,omitempty"` // 为空时启用
+}
+
+// RuleHit 一条规则的替换次数
+type RuleHit struct {
+	Rule  string `json:"rule"`
+	Count int    `json:"count"`
+}
+
+// compiledRule 编译后的规则
+type compiledRule struct {
+	name        string
+	literal     string
+	pattern     *regexp.Regexp
+	replacement string
+	scopes      []string
+}
+
+// inScope 判断规则是否适用于 locale，zh 匹配 zh-CN、zh-TW 等
+func (r compiledRule) inScope(locale string) bool {
+	if len(r.scopes) == 0 {
+		return true
+	}
+	locale = strings.ToLower(locale)
+	for _, scope := range r.scopes {
+		if locale == scope || strings.HasPrefix(locale, scope+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// RuleSet 有序的替换规则集
+type RuleSet struct {
+	name  string
+	rules []compiledRule
+}
+
+// NewRuleSet 编译规则集，跳过未启用的规则
+func NewRuleSet(name string, rules []ReplaceRule) (*RuleSet, error) {
+	set := &RuleSet{name: name}
+	for i, rule := range rules {
+		if rule.Enabled != nil && !*rule.Enabled {
+			continue
+		}
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("%w: rule set %q: rule %d: pattern is required", ErrInvalidRule, name, i)
+		}
+
+		compiled := compiledRule{name: rule.Name, replacement: rule.Replacement}
+		if compiled.name == "" {
+			compiled.name = fmt.Sprintf("#%d", i+1)
+		}
+		if rule.Regex {
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("%w: rule set %q: rule %s: %v", ErrInvalidRule, name, compiled.name, err)
+			}
+			compiled.pattern = pattern
+		} else {
+			compiled.literal = rule.Pattern
+		}
+		for _, scope := range strings.Split(rule.Scope, ",") {
+			if scope = strings.ToLower(strings.TrimSpace(scope)); scope != "" {
+				compiled.scopes = append(compiled.scopes, scope)
+			}
+		}
+		set.rules = append(set.rules, compiled)
+	}
+	return set, nil
+}
+
+// Name 返回规则集名称
+func (s *RuleSet) Name() string {
+	return s.name
+}
+
+// Apply 按顺序执行适用于 locale 的规则，返回替换后的文本和每条生效规则的替换次数
+func (s *RuleSet) Apply(text, locale string) (string, []RuleHit) {
+	var hits []RuleHit
+	for _, rule := range s.rules {
+		if !rule.inScope(locale) {
+			continue
+		}
+
+		var count int
+		if rule.pattern != nil {
+			count = len(rule.pattern.FindAllStringIndex(text, -1))
+			if count > 0 {
+				text = rule.pattern.ReplaceAllString(text, rule.replacement)
+			}
+		} else {
+			count = strings.Count(text, rule.literal)
+			if count > 0 {
+				text = strings.ReplaceAll(text, rule.literal, rule.replacement)
+			}
+		}
+		if count > 0 {
+			hits = append(hits, RuleHit{Rule: rule.name, Count: count})
+		}
+	}
+	return text, hits
+}
+
+// Replacer 管理命名规则集，按请求或 API 密钥选择
+type Replacer struct {
+	sets       map[string]*RuleSet
+	defaultSet string
+	keys       map[string]string // API 密钥（小写）-> 规则集名称
+}
+
+// NewReplacer 编译全部规则集；规则集名称不区分大小写，defaultSet 和 keys 引用的规则集必须存在
+func NewReplacer(sets map[string][]ReplaceRule, defaultSet string, keys map[string]string) (*Replacer, error) {
+	r := &Replacer{
+		sets:       make(map[string]*RuleSet, len(sets)),
+		defaultSet: strings.ToLower(defaultSet),
+		keys:       make(map[string]string, len(keys)),
+	}
+	for name, rules := range sets {
+		name = strings.ToLower(name)
+		if name == RuleSetNone {
+			return nil, fmt.Errorf("%w: rule set name %q is reserved", ErrInvalidRule, RuleSetNone)
+		}
+		set, err := NewRuleSet(name, rules)
+		if err != nil {
+			return nil, err
+		}
+		r.sets[name] = set
+	}
+	if r.defaultSet != "" && r.sets[r.defaultSet] == nil {
+		return nil, fmt.Errorf("%w: unknown default rule set %q", ErrInvalidRule, defaultSet)
+	}
+	for key, name := range keys {
+		name = strings.ToLower(name)
+		if name != RuleSetNone && r.sets[name] == nil {
+			return nil, fmt.Errorf("%w: unknown rule set %q", ErrInvalidRule, name)
+		}
+		r.keys[strings.ToLower(key)] = name
+	}
+	return r, nil
+}
+
+// Names 返回全部规则集名称（按名称排序）
+func (r *Replacer) Names() []string {
+	names := make([]string, 0, len(r.sets))
+	for name := range r.sets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select 选择规则集：请求指定的规则集优先，其次是 API 密钥对应的规则集，最后是默认规则集。
+// 指定 none 或没有可用的规则集时返回 nil。
+func (r *Replacer) Select(requested, apiKey string) (*RuleSet, error) {
+	name := strings.ToLower(strings.TrimSpace(requested))
+	if name == "" {
+		var ok bool
+		if name, ok = r.keys[strings.ToLower(apiKey)]; !ok || apiKey == "" {
+			name = r.defaultSet
+		}
+	}
+	if name == "" || name == RuleSetNone {
+		return nil, nil
+	}
+	set, ok := r.sets[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown rule set %q", ErrInvalidRule, requested)
+	}
+	return set, nil
+}
@@ -0,0 +1,85 @@
+package textproc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestRuleSet_Apply 测试规则按顺序执行、语言范围和启用开关
+func TestRuleSet_Apply(t *testing.T) {
+	disabled := false
+	set, err := NewRuleSet("webnovel", []ReplaceRule{
+		{Name: "url", Pattern: `https?://\S+`, Regex: true},
+		{Name: "tail", Pattern: "本章未完，请点击下一页继续阅读"},
+		{Name: "separator", Pattern: `[-=*]{3,}`, Regex: true, Replacement: "\n"},
+		{Name: "punct", Pattern: `([！？。])[！？。]+`, Regex: true, Replacement: "$1"},
+		{Name: "english", Pattern: "Chapter", Replacement: "第", Scope: "en"},
+		{Name: "off", Pattern: "他", Replacement: "她", Enabled: &disabled},
+	})
+	if err != nil {
+		t.Fatalf("NewRuleSet() error = %v", err)
+	}
+
+	text := "他来了！！！访问 https://example.com/ad 看更多=====Chapter 本章未完，请点击下一页继续阅读"
+	got, hits := set.Apply(text, "zh-CN")
+	want := "他来了！访问  看更多\nChapter "
+	if got != want {
+		t.Errorf("Apply() = %q, want %q", got, want)
+	}
+	wantHits := []RuleHit{{"url", 1}, {"tail", 1}, {"separator", 1}, {"punct", 1}}
+	if !reflect.DeepEqual(hits, wantHits) {
+		t.Errorf("hits = %+v, want %+v", hits, wantHits)
+	}
+
+	if got, _ := set.Apply("Chapter 1", "en-US"); got != "第 1" {
+		t.Errorf("en 范围的规则应作用于 en-US, got %q", got)
+	}
+}
+
+// TestReplacer_Select 测试按请求、API 密钥和默认值选择规则集
+func TestReplacer_Select(t *testing.T) {
+	sets := map[string][]ReplaceRule{
+		"WebNovel": {{Pattern: "广告"}},
+		"news":     {{Pattern: "编辑"}},
+	}
+	r, err := NewReplacer(sets, "news", map[string]string{"Key-A": "webnovel", "key-b": "none"})
+	if err != nil {
+		t.Fatalf("NewReplacer() error = %v", err)
+	}
+
+	tests := []struct {
+		name, requested, apiKey, want string
+	}{
+		{"默认规则集", "", "", "news"},
+		{"按密钥选择", "", "key-a", "webnovel"},
+		{"密钥关闭替换", "", "key-b", ""},
+		{"请求优先", "NEWS", "key-a", "news"},
+		{"请求关闭替换", "none", "key-a", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := r.Select(tt.requested, tt.apiKey)
+			if err != nil {
+				t.Fatalf("Select() error = %v", err)
+			}
+			got := ""
+			if set != nil {
+				got = set.Name()
+			}
+			if got != tt.want {
+				t.Errorf("Select() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := r.Select("missing", ""); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("未知规则集期望 ErrInvalidRule, got %v", err)
+	}
+	if _, err := NewReplacer(sets, "missing", nil); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("未知默认规则集期望 ErrInvalidRule, got %v", err)
+	}
+	if _, err := NewRuleSet("bad", []ReplaceRule{{Pattern: "(", Regex: true}}); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("非法正则期望 ErrInvalidRule, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package textproc
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	enPhonePattern   = regexp.MustCompile(`(?:\+1[\s-]?)?(?:\((\d{3})\)\s?|\b(\d{3})[-.\s])(\d{3})[-.\s](\d{4})\b`)
+	enOrdinalPattern = regexp.MustCompile(`\b(\d+)(st|nd|rd|th)\b`)
+)
+
+var (
+	enMonths     = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	enCurrencies = map[string][3]string{
+		// 单数、复数、辅币单位
+		"$": {"dollar", "dollars", "cents"}, "€": {"euro", "euros", "cents"},
+		"£": {"pound", "pounds", "pence"}, "¥": {"yuan", "yuan", "fen"}, "￥": {"yuan", "yuan", "fen"},
+	}
+	enUnits = map[string][2]string{
+		"km/h": {"kilometer per hour", "kilometers per hour"}, "m/s": {"meter per second", "meters per second"},
+		"kWh": {"kilowatt hour", "kilowatt hours"}, "km": {"kilometer", "kilometers"}, "kg": {"kilogram", "kilograms"},
+		"cm": {"centimeter", "centimeters"}, "mm": {"millimeter", "millimeters"}, "mg": {"milligram", "milligrams"},
+		"ml": {"milliliter", "milliliters"}, "mL": {"milliliter", "milliliters"},
+		"GHz": {"gigahertz", "gigahertz"}, "MHz": {"megahertz", "megahertz"}, "kHz": {"kilohertz", "kilohertz"}, "Hz": {"hertz", "hertz"},
+		"°C": {"degree Celsius", "degrees Celsius"}, "℃": {"degree Celsius", "degrees Celsius"},
+		"°F": {"degree Fahrenheit", "degrees Fahrenheit"}, "℉": {"degree Fahrenheit", "degrees Fahrenheit"},
+	}
+)
+
+// enRules 英文规则，顺序与 ruleOrder 一致；普通数字由上游朗读
+var enRules = []rule{
+	{RuleDate, datePattern, func(m []string, t *token) bool {
+		year, month, day, ok := parseDate(m[1], m[2], m[3])
+		if !ok {
+			return false
+		}
+		mon, _ := strconv.Atoi(month)
+		d, _ := strconv.Atoi(day)
+		t.spoken = enMonths[mon-1] + " " + enOrdinal(int64(d)) + ", " + year
+		t.interpretAs, t.format, t.sayAsText = "date", "ymd", year+"-"+month+"-"+day
+		return true
+	}},
+	{RuleTime, timePattern, func(m []string, t *token) bool {
+		hour, minute, _, ok := parseTime(m[1], m[2], m[3])
+		if !ok {
+			return false
+		}
+		suffix := "AM"
+		if hour >= 12 && hour < 24 {
+			suffix = "PM"
+		}
+		hour %= 12
+		if hour == 0 {
+			hour = 12
+		}
+		spoken := strconv.Itoa(hour)
+		if minute > 0 {
+			spoken += ":" + twoDigits(minute)
+		}
+		t.spoken = spoken + " " + suffix
+		t.interpretAs, t.format, t.sayAsText = "time", "hms24", m[0]
+		return true
+	}},
+	{RulePhone, enPhonePattern, func(m []string, t *token) bool {
+		area := m[1] + m[2]
+		t.spoken = spellDigits(area) + ", " + spellDigits(m[3]) + ", " + spellDigits(m[4])
+		t.interpretAs, t.sayAsText = "telephone", m[0]
+		return true
+	}},
+	{RuleVersion, versionPattern, func(m []string, t *token) bool {
+		prefix, version := m[1], m[2]
+		if version == "" {
+			prefix, version = m[3], m[4]
+		}
+		spoken := strings.ReplaceAll(version, ".", " point ")
+		if prefix != "" {
+			spoken = "version " + spoken
+		}
+		t.spoken = spoken
+		return true
+	}},
+	{RuleCurrency, currencyPattern, func(m []string, t *token) bool {
+		names := enCurrencies[m[1]]
+		whole, cents, _ := strings.Cut(m[2], ".")
+		spoken := whole + " " + names[1]
+		if n, ok := parseInt(whole); ok && n == 1 {
+			spoken = whole + " " + names[0]
+		}
+		if c, err := strconv.Atoi((cents + "00")[:2]); err == nil && cents != "" && c > 0 {
+			spoken += " and " + strconv.Itoa(c) + " " + names[2]
+		}
+		t.spoken = spoken
+		return true
+	}},
+	{RulePercent, percentPattern, func(m []string, t *token) bool {
+		word := " percent"
+		if m[2] == "‰" {
+			word = " per mille"
+		}
+		t.spoken = m[1] + word
+		return true
+	}},
+	{RuleUnit, belowZeroPattern, func(m []string, t *token) bool {
+		t.spoken = m[1] + "minus " + m[2] + " " + enUnits[m[3]][1]
+		return true
+	}},
+	{RuleUnit, unitPattern, func(m []string, t *token) bool {
+		names := enUnits[m[2]+m[3]]
+		if m[1] == "1" {
+			t.spoken = m[1] + " " + names[0]
+		} else {
+			t.spoken = m[1] + " " + names[1]
+		}
+		return true
+	}},
+	{RuleOrdinal, enOrdinalPattern, func(m []string, t *token) bool {
+		n, ok := parseInt(m[1])
+		if !ok || n > 1e12 {
+			return false
+		}
+		t.spoken = enOrdinal(n)
+		return true
+	}},
+}
+
+// spellDigits 逐位读数字，以空格分隔
+func spellDigits(digits string) string {
+	return strings.Join(strings.Split(digits, ""), " ")
+}
@@ -0,0 +1,162 @@
+package textproc
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// numPattern 数字（可带千分位和小数）
+const numPattern = `(?:\d{1,3}(?:,\d{3})+|\d+)(?:\.\d+)?`
+
+var (
+	datePattern     = regexp.MustCompile(`\b(\d{4})[-/.](\d{1,2})[-/.](\d{1,2})\b`)
+	yearPattern     = regexp.MustCompile(`\b(\d{4})年`)
+	timePattern     = regexp.MustCompile(`\b(\d{1,2}):(\d{2})(?::(\d{2}))?\b`)
+	mobilePattern   = regexp.MustCompile(`(\+86[\s-]?)?\b(1[3-9]\d)[\s-]?(\d{4})[\s-]?(\d{4})\b`)
+	landPattern     = regexp.MustCompile(`\b(0\d{2,3}|400)-(\d{3,4})-?(\d{4})\b`)
+	versionPattern  = regexp.MustCompile(`\b([vV])?(\d+(?:\.\d+){2,3})\b|\b([vV])(\d+\.\d+)\b`)
+	currencyPattern = regexp.MustCompile(`([¥￥$€£])\s?(` + numPattern + `)`)
+	percentPattern  = regexp.MustCompile(`(` + numPattern + `)\s?([%％‰])`)
+	unitPattern     = regexp.MustCompile(`(` + numPattern + `)\s?(?:(km/h|m/s|kWh|km|kg|cm|mm|mg|ml|mL|GHz|MHz|kHz|Hz|°C|°F)\b|(℃|℉))`)
+	// 负温度：负号前不能是数字，避免把 "3-5℃" 读成负数
+	belowZeroPattern = regexp.MustCompile(`(^|[^\d.])-(` + numPattern + `)\s?(°C|°F|℃|℉)`)
+	zhOrdinalPat     = regexp.MustCompile(`第\s?(\d+)`)
+	numberPattern    = regexp.MustCompile(numPattern)
+)
+
+var (
+	zhCurrencies = map[string]string{"¥": "元", "￥": "元", "$": "美元", "€": "欧元", "£": "英镑"}
+	zhUnits      = map[string]string{
+		"km/h": "千米每小时", "m/s": "米每秒", "kWh": "千瓦时", "km": "千米", "kg": "千克",
+		"cm": "厘米", "mm": "毫米", "mg": "毫克", "ml": "毫升", "mL": "毫升",
+		"GHz": "吉赫", "MHz": "兆赫", "kHz": "千赫", "Hz": "赫兹",
+		"°C": "摄氏度", "℃": "摄氏度", "°F": "华氏度", "℉": "华氏度",
+	}
+)
+
+// zhRules 中文规则，顺序与 ruleOrder 一致
+var zhRules = []rule{
+	{RuleDate, datePattern, func(m []string, t *token) bool {
+		year, month, day, ok := parseDate(m[1], m[2], m[3])
+		if !ok {
+			return false
+		}
+		t.spoken = zhDigits(year, false) + "年" + zhInteger(month) + "月" + zhInteger(day) + "日"
+		t.interpretAs, t.format, t.sayAsText = "date", "ymd", year+"-"+month+"-"+day
+		return true
+	}},
+	{RuleDate, yearPattern, func(m []string, t *token) bool {
+		t.spoken = zhDigits(m[1], false) + "年"
+		return true
+	}},
+	{RuleTime, timePattern, func(m []string, t *token) bool {
+		hour, minute, second, ok := parseTime(m[1], m[2], m[3])
+		if !ok {
+			return false
+		}
+		spoken := zhInteger(strconv.Itoa(hour)) + "点"
+		switch {
+		case minute == 0 && m[3] == "":
+			spoken += "整"
+		case minute < 10:
+			spoken += "零" + zhInteger(strconv.Itoa(minute)) + "分"
+		default:
+			spoken += zhInteger(strconv.Itoa(minute)) + "分"
+		}
+		if m[3] != "" {
+			spoken += zhInteger(strconv.Itoa(second)) + "秒"
+		}
+		t.spoken = spoken
+		t.interpretAs, t.format, t.sayAsText = "time", "hms24", m[0]
+		return true
+	}},
+	{RulePhone, mobilePattern, func(m []string, t *token) bool {
+		spoken := zhDigits(m[2], true) + " " + zhDigits(m[3], true) + " " + zhDigits(m[4], true)
+		if m[1] != "" {
+			spoken = "加八六 " + spoken
+		}
+		t.spoken = spoken
+		t.interpretAs, t.sayAsText = "telephone", m[0]
+		return true
+	}},
+	{RulePhone, landPattern, func(m []string, t *token) bool {
+		t.spoken = zhDigits(m[1], true) + " " + zhDigits(m[2], true) + " " + zhDigits(m[3], true)
+		t.interpretAs, t.sayAsText = "telephone", m[0]
+		return true
+	}},
+	{RuleVersion, versionPattern, func(m []string, t *token) bool {
+		prefix, version := m[1], m[2]
+		if version == "" {
+			prefix, version = m[3], m[4]
+		}
+		parts := strings.Split(version, ".")
+		for i, part := range parts {
+			parts[i] = zhInteger(part)
+		}
+		if prefix != "" {
+			prefix = "V"
+		}
+		t.spoken = prefix + strings.Join(parts, "点")
+		return true
+	}},
+	{RuleCurrency, currencyPattern, func(m []string, t *token) bool {
+		t.spoken = zhNumber(m[2]) + zhCurrencies[m[1]]
+		return true
+	}},
+	{RulePercent, percentPattern, func(m []string, t *token) bool {
+		prefix := "百分之"
+		if m[2] == "‰" {
+			prefix = "千分之"
+		}
+		t.spoken = prefix + zhNumber(m[1])
+		return true
+	}},
+	{RuleUnit, belowZeroPattern, func(m []string, t *token) bool {
+		t.spoken = m[1] + "零下" + zhNumber(m[2]) + zhUnits[m[3]]
+		return true
+	}},
+	{RuleUnit, unitPattern, func(m []string, t *token) bool {
+		t.spoken = zhNumber(m[1]) + zhUnits[m[2]+m[3]]
+		return true
+	}},
+	{RuleOrdinal, zhOrdinalPat, func(m []string, t *token) bool {
+		t.spoken = "第" + zhInteger(m[1])
+		return true
+	}},
+	{RuleNumber, numberPattern, func(m []string, t *token) bool {
+		t.spoken = zhNumber(m[0])
+		return true
+	}},
+}
+
+// parseDate 校验并规范化年月日（月、日补齐两位）
+func parseDate(year, month, day string) (string, string, string, bool) {
+	mon, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+	if mon < 1 || mon > 12 || d < 1 || d > 31 {
+		return "", "", "", false
+	}
+	return year, twoDigits(mon), twoDigits(d), true
+}
+
+// parseTime 校验时间，秒可以为空
+func parseTime(hour, minute, second string) (int, int, int, bool) {
+	h, _ := strconv.Atoi(hour)
+	m, _ := strconv.Atoi(minute)
+	s := 0
+	if second != "" {
+		s, _ = strconv.Atoi(second)
+	}
+	if h > 24 || m > 59 || s > 59 {
+		return 0, 0, 0, false
+	}
+	return h, m, s, true
+}
+
+func twoDigits(n int) string {
+	if n < 10 {
+		return "0" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,233 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	custom_errors "tts/internal/errors"
+	"tts/internal/tts/limiter"
+)
+
+// Outcome 表示一次上游调用的结果类别，用于驱动自适应并发控制
+type Outcome int
+
+const (
+	// OutcomeSuccess 调用成功
+	OutcomeSuccess Outcome = iota
+	// OutcomeError 普通失败（计入错误率）
+	OutcomeError
+	// OutcomeOverload 上游过载信号（429 或超时），触发乘性减小
+	OutcomeOverload
+	// OutcomeIgnored 与上游健康状况无关的结果（如客户端断开），不参与调整
+	OutcomeIgnored
+)
+
+// AdaptiveConfig 自适应并发控制配置
+type AdaptiveConfig struct {
+	Enabled       bool          // 是否启用 AIMD 自适应并发
+	TargetLatency time.Duration // 目标延迟，窗口平均延迟不超过该值时才增大并发
+	MaxErrorRate  float64       // 窗口内允许的最大错误率（0-1），超过时减小并发
+}
+
+// AdaptiveLimiter 基于 AIMD（加性增、乘性减）的并发限制器
+//
+// 每完成一个"窗口"（数量等于当前并发上限）的任务评估一次：
+// 延迟与错误率健康时上限加 1；错误率过高时减 1；
+// 一旦出现 429 或超时，立即将上限减半。
+type AdaptiveLimiter struct {
+	mu            sync.Mutex
+	limit         int
+	minLimit      int
+	maxLimit      int
+	inFlight      int
+	targetLatency time.Duration
+	maxErrorRate  float64
+
+	// 当前评估窗口
+	windowCount   int
+	windowErrors  int
+	windowLatency time.Duration
+
+	// 等待者通知通道，容量变化时关闭以广播
+	notify chan struct{}
+}
+
+// NewAdaptiveLimiter 创建自适应并发限制器
+// 如果未启用自适应，上限固定为 maxLimit
+func NewAdaptiveLimiter(minLimit, maxLimit int, cfg AdaptiveConfig) *AdaptiveLimiter {
+	if maxLimit < 1 {
+		maxLimit = 1
+	}
+	if minLimit < 1 {
+		minLimit = 1
+	}
+	if minLimit > maxLimit {
+		minLimit = maxLimit
+	}
+	if cfg.TargetLatency <= 0 {
+		cfg.TargetLatency = 5 * time.Second
+	}
+	if cfg.MaxErrorRate <= 0 {
+		cfg.MaxErrorRate = 0.1
+	}
+
+	limit := maxLimit
+	if cfg.Enabled {
+		// 从一半开始，由上游的实际表现决定是否继续增大
+		limit = (maxLimit + 1) / 2
+		if limit < minLimit {
+			limit = minLimit
+		}
+	} else {
+		minLimit = maxLimit
+	}
+
+	return &AdaptiveLimiter{
+		limit:         limit,
+		minLimit:      minLimit,
+		maxLimit:      maxLimit,
+		targetLatency: cfg.TargetLatency,
+		maxErrorRate:  cfg.MaxErrorRate,
+		notify:        make(chan struct{}),
+	}
+}
+
+// Acquire 等待一个并发槽位
+func (l *AdaptiveLimiter) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inFlight < l.limit {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		wait := l.notify
+		l.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Release 释放槽位并记录本次调用的结果
+func (l *AdaptiveLimiter) Release(outcome Outcome, latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	l.record(outcome, latency)
+	l.broadcast()
+}
+
+// record 根据调用结果调整并发上限（需持有锁）
+func (l *AdaptiveLimiter) record(outcome Outcome, latency time.Duration) {
+	if l.minLimit == l.maxLimit {
+		return
+	}
+
+	switch outcome {
+	case OutcomeIgnored:
+		return
+	case OutcomeOverload:
+		// 乘性减小，并开始新的评估窗口
+		l.limit /= 2
+		if l.limit < l.minLimit {
+			l.limit = l.minLimit
+		}
+		l.resetWindow()
+		return
+	case OutcomeError:
+		l.windowErrors++
+	}
+
+	l.windowCount++
+	l.windowLatency += latency
+	if l.windowCount < l.limit {
+		return
+	}
+
+	errorRate := float64(l.windowErrors) / float64(l.windowCount)
+	avgLatency := l.windowLatency / time.Duration(l.windowCount)
+	switch {
+	case errorRate > l.maxErrorRate:
+		if l.limit > l.minLimit {
+			l.limit--
+		}
+	case avgLatency <= l.targetLatency:
+		if l.limit < l.maxLimit {
+			l.limit++
+		}
+	}
+	l.resetWindow()
+}
+
+// resetWindow 清空评估窗口（需持有锁）
+func (l *AdaptiveLimiter) resetWindow() {
+	l.windowCount = 0
+	l.windowErrors = 0
+	l.windowLatency = 0
+}
+
+// broadcast 唤醒所有等待者（需持有锁）
+func (l *AdaptiveLimiter) broadcast() {
+	close(l.notify)
+	l.notify = make(chan struct{})
+}
+
+// Limit 返回当前并发上限
+func (l *AdaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// InFlight 返回当前正在执行的任务数
+func (l *AdaptiveLimiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+// classifyOutcome 将任务执行结果归类为 AIMD 的输入信号
+func classifyOutcome(jobCtx context.Context, err error) Outcome {
+	if err == nil {
+		return OutcomeSuccess
+	}
+
+	// 请求方已取消（如客户端断开），与上游状况无关
+	if jobCtx.Err() != nil {
+		return OutcomeIgnored
+	}
+
+	// 在本地并发队列中等待超时，说明本服务排队过多，与上游状况无关
+	if errors.Is(err, limiter.ErrQueueTimeout) {
+		return OutcomeIgnored
+	}
+
+	var upstreamErr *custom_errors.UpstreamError
+	if errors.As(err, &upstreamErr) {
+		switch upstreamErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return OutcomeOverload
+		}
+		return OutcomeError
+	}
+
+	// 上游调用超时（任务自身的 context 未结束，因此是单次调用的超时）
+	if errors.Is(err, context.DeadlineExceeded) {
+		return OutcomeOverload
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return OutcomeOverload
+	}
+
+	return OutcomeError
+}
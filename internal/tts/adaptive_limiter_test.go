@@ -0,0 +1,158 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	custom_errors "tts/internal/errors"
+	"tts/internal/tts/limiter"
+)
+
+// TestAdaptiveLimiter_Disabled 测试未启用自适应时上限固定
+func TestAdaptiveLimiter_Disabled(t *testing.T) {
+	l := NewAdaptiveLimiter(1, 4, AdaptiveConfig{})
+	if got := l.Limit(); got != 4 {
+		t.Fatalf("Limit() = %d, want 4", got)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		_ = l.Acquire(ctx)
+		l.Release(OutcomeOverload, time.Millisecond)
+	}
+	if got := l.Limit(); got != 4 {
+		t.Errorf("未启用自适应时上限不应变化, Limit() = %d", got)
+	}
+}
+
+// TestAdaptiveLimiter_AdditiveIncrease 测试健康时加性增大
+func TestAdaptiveLimiter_AdditiveIncrease(t *testing.T) {
+	l := NewAdaptiveLimiter(1, 8, AdaptiveConfig{Enabled: true, TargetLatency: time.Second})
+	start := l.Limit()
+	if start != 4 {
+		t.Fatalf("初始上限 = %d, want 4", start)
+	}
+
+	ctx := context.Background()
+	// 完成一个窗口（数量等于当前上限）的健康调用
+	for i := 0; i < start; i++ {
+		_ = l.Acquire(ctx)
+		l.Release(OutcomeSuccess, 100*time.Millisecond)
+	}
+	if got := l.Limit(); got != start+1 {
+		t.Errorf("健康窗口后 Limit() = %d, want %d", got, start+1)
+	}
+
+	// 持续健康时不超过上限
+	for i := 0; i < 100; i++ {
+		_ = l.Acquire(ctx)
+		l.Release(OutcomeSuccess, 100*time.Millisecond)
+	}
+	if got := l.Limit(); got != 8 {
+		t.Errorf("Limit() = %d, want 8", got)
+	}
+}
+
+// TestAdaptiveLimiter_SlowLatency 测试延迟超过目标时不增大
+func TestAdaptiveLimiter_SlowLatency(t *testing.T) {
+	l := NewAdaptiveLimiter(1, 8, AdaptiveConfig{Enabled: true, TargetLatency: time.Second})
+	start := l.Limit()
+
+	ctx := context.Background()
+	for i := 0; i < start*3; i++ {
+		_ = l.Acquire(ctx)
+		l.Release(OutcomeSuccess, 2*time.Second)
+	}
+	if got := l.Limit(); got != start {
+		t.Errorf("延迟超标时 Limit() = %d, want %d", got, start)
+	}
+}
+
+// TestAdaptiveLimiter_MultiplicativeDecrease 测试过载时减半
+func TestAdaptiveLimiter_MultiplicativeDecrease(t *testing.T) {
+	l := NewAdaptiveLimiter(1, 8, AdaptiveConfig{Enabled: true})
+	ctx := context.Background()
+
+	for i := 0; i < 50; i++ {
+		_ = l.Acquire(ctx)
+		l.Release(OutcomeSuccess, time.Millisecond)
+	}
+	if got := l.Limit(); got != 8 {
+		t.Fatalf("Limit() = %d, want 8", got)
+	}
+
+	_ = l.Acquire(ctx)
+	l.Release(OutcomeOverload, time.Millisecond)
+	if got := l.Limit(); got != 4 {
+		t.Errorf("过载后 Limit() = %d, want 4", got)
+	}
+
+	for i := 0; i < 5; i++ {
+		_ = l.Acquire(ctx)
+		l.Release(OutcomeOverload, time.Millisecond)
+	}
+	if got := l.Limit(); got != 1 {
+		t.Errorf("Limit() 不应低于下限, got %d", got)
+	}
+}
+
+// TestAdaptiveLimiter_AcquireBlocks 测试达到上限时阻塞
+func TestAdaptiveLimiter_AcquireBlocks(t *testing.T) {
+	l := NewAdaptiveLimiter(1, 1, AdaptiveConfig{})
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("期望阻塞直到超时, got %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		_ = l.Acquire(context.Background())
+		close(acquired)
+	}()
+	l.Release(OutcomeSuccess, time.Millisecond)
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("释放后等待者未被唤醒")
+	}
+}
+
+// TestClassifyOutcome 测试调用结果分类
+func TestClassifyOutcome(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want Outcome
+	}{
+		{"成功", context.Background(), nil, OutcomeSuccess},
+		{"429", context.Background(), fmt.Errorf("wrap: %w", custom_errors.NewUpstreamError(http.StatusTooManyRequests, "TTS API 错误", nil)), OutcomeOverload},
+		{"超时", context.Background(), fmt.Errorf("wrap: %w", context.DeadlineExceeded), OutcomeOverload},
+		{"400", context.Background(), custom_errors.NewUpstreamError(http.StatusBadRequest, "TTS API 错误", nil), OutcomeError},
+		{"客户端取消", cancelled, context.Canceled, OutcomeIgnored},
+		{"本地排队超时", context.Background(), fmt.Errorf("wrap: %w", limiter.ErrQueueTimeout), OutcomeIgnored},
+		{"503", context.Background(), custom_errors.NewUpstreamError(http.StatusServiceUnavailable, "TTS API 错误", nil), OutcomeOverload},
+		{"错误信息包含 timeout", context.Background(), errors.New("invalid ssml: timeout attribute"), OutcomeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyOutcome(tt.ctx, tt.err); got != tt.want {
+				t.Errorf("classifyOutcome() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrNoMP3Frame 表示在音频数据中找不到有效的 MP3 帧
+var ErrNoMP3Frame = errors.New("no valid mp3 frame found")
+
+// MPEG Layer III 比特率表（kbps），按版本区分
+var (
+	mpeg1L3Bitrates = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mpeg2L3Bitrates = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+)
+
+// MPEG 采样率表（Hz），按版本区分
+var (
+	mpeg1SampleRates  = [3]int{44100, 48000, 32000}
+	mpeg2SampleRates  = [3]int{22050, 24000, 16000}
+	mpeg25SampleRates = [3]int{11025, 12000, 8000}
+)
+
+// frameHeader 表示解析后的 MP3 帧头
+type frameHeader struct {
+	raw        [4]byte
+	mpeg1      bool // 是否为 MPEG-1（否则为 MPEG-2/2.5）
+	bitrate    int  // kbps
+	sampleRate int  // Hz
+	padding    bool
+	mono       bool
+}
+
+// parseFrameHeader 解析 4 字节的 MPEG Layer III 帧头
+func parseFrameHeader(b []byte) (frameHeader, bool) {
+	var h frameHeader
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return h, false
+	}
+
+	version := (b[1] >> 3) & 0x03
+	layer := (b[1] >> 1) & 0x03
+	bitrateIdx := b[2] >> 4
+	sampleIdx := (b[2] >> 2) & 0x03
+	if version == 1 || layer != 1 || bitrateIdx == 0 || bitrateIdx == 15 || sampleIdx == 3 {
+		return h, false
+	}
+
+	copy(h.raw[:], b[:4])
+	h.padding = (b[2]>>1)&0x01 == 1
+	h.mono = b[3]>>6 == 3
+	switch version {
+	case 3:
+		h.mpeg1 = true
+		h.bitrate = mpeg1L3Bitrates[bitrateIdx]
+		h.sampleRate = mpeg1SampleRates[sampleIdx]
+	case 2:
+		h.bitrate = mpeg2L3Bitrates[bitrateIdx]
+		h.sampleRate = mpeg2SampleRates[sampleIdx]
+	default:
+		h.bitrate = mpeg2L3Bitrates[bitrateIdx]
+		h.sampleRate = mpeg25SampleRates[sampleIdx]
+	}
+	return h, true
+}
+
+// frameSize 返回帧的字节长度（含帧头）
+func (h frameHeader) frameSize() int {
+	coefficient := 72
+	if h.mpeg1 {
+		coefficient = 144
+	}
+	size := coefficient * h.bitrate * 1000 / h.sampleRate
+	if h.padding {
+		size++
+	}
+	return size
+}
+
+// samplesPerFrame 返回每帧的采样数
+func (h frameHeader) samplesPerFrame() int {
+	if h.mpeg1 {
+		return 1152
+	}
+	return 576
+}
+
+// frameDuration 返回每帧的播放时长
+func (h frameHeader) frameDuration() time.Duration {
+	return time.Duration(h.samplesPerFrame()) * time.Second / time.Duration(h.sampleRate)
+}
+
+// findFirstFrame 在数据中查找第一个有效帧的位置
+// 要求紧随其后还有一个有效帧，以避免把音频数据误判为帧头
+func findFirstFrame(data []byte) (int, frameHeader, bool) {
+	data = removeID3Tags(data)
+	offset := 0
+	for offset+4 <= len(data) {
+		idx := bytes.IndexByte(data[offset:], 0xFF)
+		if idx < 0 {
+			break
+		}
+		pos := offset + idx
+		if h, ok := parseFrameHeader(data[pos:]); ok {
+			next := pos + h.frameSize()
+			if next+4 > len(data) {
+				return pos, h, true
+			}
+			if _, ok := parseFrameHeader(data[next:]); ok {
+				return pos, h, true
+			}
+		}
+		offset = pos + 1
+	}
+	return 0, frameHeader{}, false
+}
+
+// GenerateSilence 生成与参考音频相同编码参数的静音 MP3 数据
+// 静音帧的 side info 与主数据全部为零，解码后为无声
+func GenerateSilence(reference []byte, duration time.Duration) ([]byte, error) {
+	_, h, ok := findFirstFrame(reference)
+	if !ok {
+		return nil, ErrNoMP3Frame
+	}
+
+	// 使用无 CRC、无填充的帧头，保证每帧长度一致
+	header := h.raw
+	header[1] |= 0x01
+	header[2] &^= 0x02
+	h.padding = false
+
+	frameCount := int((duration + h.frameDuration() - 1) / h.frameDuration())
+	if frameCount < 1 {
+		frameCount = 1
+	}
+
+	size := h.frameSize()
+	out := make([]byte, size*frameCount)
+	for i := 0; i < frameCount; i++ {
+		copy(out[i*size:], header[:])
+	}
+	return out, nil
+}
+
+// IsMP3Format 上游的音频格式名称（如 audio-24khz-48kbitrate-mono-mp3）是否为 MP3。
+// 时长计算和静音生成只支持 MP3
+func IsMP3Format(format string) bool {
+	return strings.HasSuffix(strings.ToLower(strings.TrimSpace(format)), "-mp3")
+}
+
+// Duration 通过累加帧时长计算 MP3 数据的播放时长
+// 遇到无法解析的数据（如尾部标签）时停止
+func Duration(data []byte) (time.Duration, error) {
+	data = removeID3Tags(data)
+	pos, _, ok := findFirstFrame(data)
+	if !ok {
+		return 0, ErrNoMP3Frame
+	}
+
+	var total time.Duration
+	for pos+4 <= len(data) {
+		h, ok := parseFrameHeader(data[pos:])
+		if !ok || pos+h.frameSize() > len(data) {
+			break
+		}
+		total += h.frameDuration()
+		pos += h.frameSize()
+	}
+	return total, nil
+}
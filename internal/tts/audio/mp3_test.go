@@ -0,0 +1,83 @@
+package audio
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// 24kHz 48kbps 单声道（MPEG-2 Layer III，服务默认输出格式）的帧头
+var testHeader = []byte{0xFF, 0xF3, 0x64, 0xC4}
+
+func makeFrames(header []byte, count int) []byte {
+	h, _ := parseFrameHeader(header)
+	size := h.frameSize()
+	data := make([]byte, size*count)
+	for i := 0; i < count; i++ {
+		copy(data[i*size:], header)
+	}
+	return data
+}
+
+func TestParseFrameHeader(t *testing.T) {
+	h, ok := parseFrameHeader(testHeader)
+	if !ok {
+		t.Fatal("parseFrameHeader() 无法解析有效帧头")
+	}
+	if h.mpeg1 || h.bitrate != 48 || h.sampleRate != 24000 || !h.mono {
+		t.Errorf("帧头解析错误: %+v", h)
+	}
+	if got := h.frameSize(); got != 144 {
+		t.Errorf("frameSize() = %d, want 144", got)
+	}
+	if got := h.frameDuration(); got != 24*time.Millisecond {
+		t.Errorf("frameDuration() = %v, want 24ms", got)
+	}
+
+	if _, ok := parseFrameHeader([]byte{0xFF, 0xF3, 0xF4, 0xC4}); ok {
+		t.Error("无效比特率索引不应被解析")
+	}
+	if _, ok := parseFrameHeader([]byte{0x49, 0x44, 0x33, 0x03}); ok {
+		t.Error("ID3 标签不应被解析为帧头")
+	}
+}
+
+func TestGenerateSilence(t *testing.T) {
+	reference := append([]byte("ID3\x03\x00\x00\x00\x00\x00\x00"), makeFrames(testHeader, 3)...)
+
+	silence, err := GenerateSilence(reference, time.Second)
+	if err != nil {
+		t.Fatalf("GenerateSilence() error = %v", err)
+	}
+
+	// 1 秒 / 24ms 每帧，向上取整为 42 帧
+	if got, want := len(silence), 42*144; got != want {
+		t.Errorf("静音长度 = %d 字节, want %d", got, want)
+	}
+	if _, ok := parseFrameHeader(silence[144:]); !ok {
+		t.Error("生成的静音帧无法被解析")
+	}
+
+	if _, err := GenerateSilence([]byte("not audio"), time.Second); err == nil {
+		t.Error("非 MP3 参考数据应返回错误")
+	}
+}
+
+// TestDuration 测试按帧累加计算播放时长
+func TestDuration(t *testing.T) {
+	data := append([]byte("ID3\x03\x00\x00\x00\x00\x00\x00"), makeFrames(testHeader, 50)...)
+	// 尾部不完整的帧不计入
+	data = append(data, testHeader...)
+
+	got, err := Duration(data)
+	if err != nil {
+		t.Fatalf("Duration() error = %v", err)
+	}
+	if want := 50 * 24 * time.Millisecond; got != want {
+		t.Errorf("Duration() = %v, want %v", got, want)
+	}
+
+	if _, err := Duration([]byte("not mp3")); !errors.Is(err, ErrNoMP3Frame) {
+		t.Errorf("非 MP3 数据期望 ErrNoMP3Frame, got %v", err)
+	}
+}
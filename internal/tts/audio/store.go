@@ -0,0 +1,259 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrStoreFull 表示分段音频超过了存储的容量限制
+var ErrStoreFull = errors.New("segment store size limit exceeded")
+
+// storeDirPrefix 临时目录名前缀，用于识别并清理残留目录
+const storeDirPrefix = "tts-segments-"
+
+// SegmentStore 分段音频存储接口
+// 合成过程中每个分段的音频写入存储后即可释放，合并时再按顺序读取
+type SegmentStore interface {
+	// Put 保存指定索引的分段音频
+	Put(index int, data []byte) error
+	// Open 打开指定索引的分段音频
+	Open(index int) (io.ReadCloser, error)
+	// Has 判断指定索引的分段是否已保存
+	Has(index int) bool
+	// Len 返回分段总数
+	Len() int
+	// TotalSize 返回已保存音频的总字节数
+	TotalSize() int64
+	// Close 释放存储占用的资源（删除临时文件）
+	Close() error
+}
+
+// MemoryStore 基于内存的分段存储，用于临时目录不可用时的回退
+type MemoryStore struct {
+	mu       sync.Mutex
+	segments [][]byte
+	size     int64
+	maxBytes int64
+}
+
+// NewMemoryStore 创建内存分段存储
+// maxBytes 为总大小上限，0 表示不限制
+func NewMemoryStore(count int, maxBytes int64) *MemoryStore {
+	return &MemoryStore{
+		segments: make([][]byte, count),
+		maxBytes: maxBytes,
+	}
+}
+
+// Put 保存指定索引的分段音频
+func (s *MemoryStore) Put(index int, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if index < 0 || index >= len(s.segments) {
+		return fmt.Errorf("invalid segment index: %d", index)
+	}
+	delta := int64(len(data)) - int64(len(s.segments[index]))
+	if s.maxBytes > 0 && s.size+delta > s.maxBytes {
+		return ErrStoreFull
+	}
+	s.segments[index] = data
+	s.size += delta
+	return nil
+}
+
+// Open 打开指定索引的分段音频
+func (s *MemoryStore) Open(index int) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if index < 0 || index >= len(s.segments) || s.segments[index] == nil {
+		return nil, fmt.Errorf("missing audio segment at index %d", index)
+	}
+	return io.NopCloser(bytes.NewReader(s.segments[index])), nil
+}
+
+// Has 判断指定索引的分段是否已保存
+func (s *MemoryStore) Has(index int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return index >= 0 && index < len(s.segments) && s.segments[index] != nil
+}
+
+// Len 返回分段总数
+func (s *MemoryStore) Len() int {
+	return len(s.segments)
+}
+
+// TotalSize 返回已保存音频的总字节数
+func (s *MemoryStore) TotalSize() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Segments 返回所有分段音频
+func (s *MemoryStore) Segments() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.segments
+}
+
+// Close 释放分段音频
+func (s *MemoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.segments = make([][]byte, len(s.segments))
+	s.size = 0
+	return nil
+}
+
+// FileStore 基于临时目录的分段存储
+// 每个请求使用独立的目录，Close 时整体删除
+type FileStore struct {
+	mu       sync.Mutex
+	dir      string
+	sizes    []int64 // 每个分段的字节数，-1 表示尚未保存
+	size     int64
+	maxBytes int64
+	closed   bool
+}
+
+// NewFileStore 在 baseDir 下创建一个新的分段存储目录
+// baseDir 为空时使用系统临时目录，maxBytes 为总大小上限（0 表示不限制）
+func NewFileStore(baseDir string, count int, maxBytes int64) (*FileStore, error) {
+	if baseDir == "" {
+		baseDir = os.TempDir()
+	}
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create spill directory: %w", err)
+	}
+	dir, err := os.MkdirTemp(baseDir, storeDirPrefix+"*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create segment store: %w", err)
+	}
+
+	sizes := make([]int64, count)
+	for i := range sizes {
+		sizes[i] = -1
+	}
+	return &FileStore{
+		dir:      dir,
+		sizes:    sizes,
+		maxBytes: maxBytes,
+	}, nil
+}
+
+// Dir 返回存储目录
+func (s *FileStore) Dir() string {
+	return s.dir
+}
+
+// Path 返回指定索引分段的文件路径
+func (s *FileStore) Path(index int) string {
+	return filepath.Join(s.dir, fmt.Sprintf("seg_%06d.mp3", index))
+}
+
+// Put 将分段音频写入文件
+func (s *FileStore) Put(index int, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errors.New("segment store is closed")
+	}
+	if index < 0 || index >= len(s.sizes) {
+		return fmt.Errorf("invalid segment index: %d", index)
+	}
+	previous := s.sizes[index]
+	if previous < 0 {
+		previous = 0
+	}
+	delta := int64(len(data)) - previous
+	if s.maxBytes > 0 && s.size+delta > s.maxBytes {
+		return ErrStoreFull
+	}
+
+	if err := os.WriteFile(s.Path(index), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write segment %d: %w", index, err)
+	}
+	s.sizes[index] = int64(len(data))
+	s.size += delta
+	return nil
+}
+
+// Open 打开指定索引的分段文件
+func (s *FileStore) Open(index int) (io.ReadCloser, error) {
+	if !s.Has(index) {
+		return nil, fmt.Errorf("missing audio segment at index %d", index)
+	}
+	return os.Open(s.Path(index))
+}
+
+// Has 判断指定索引的分段是否已保存
+func (s *FileStore) Has(index int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return index >= 0 && index < len(s.sizes) && s.sizes[index] >= 0
+}
+
+// Len 返回分段总数
+func (s *FileStore) Len() int {
+	return len(s.sizes)
+}
+
+// TotalSize 返回已保存音频的总字节数
+func (s *FileStore) TotalSize() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Close 删除存储目录及其中的所有文件
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return os.RemoveAll(s.dir)
+}
+
+// CleanupStaleStores 删除 baseDir 下超过 maxAge 的残留分段目录
+// 用于清理进程异常退出后遗留的临时文件，返回删除的目录数
+func CleanupStaleStores(baseDir string, maxAge time.Duration) (int, error) {
+	if baseDir == "" {
+		baseDir = os.TempDir()
+	}
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	removed := 0
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), storeDirPrefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(baseDir, entry.Name())); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
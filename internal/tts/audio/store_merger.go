@@ -0,0 +1,227 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// StoreMerger 从分段存储中合并音频的合并器
+type StoreMerger interface {
+	MergeStore(ctx context.Context, store SegmentStore) (*MergedAudio, error)
+}
+
+// MergedAudio 合并后的音频流
+// 读取完毕后必须调用 Close，以释放合并结果及分段存储占用的临时文件
+type MergedAudio struct {
+	reader  io.Reader
+	closer  io.Closer
+	size    int64
+	cleanup func() error
+}
+
+// Read 读取合并后的音频数据
+func (a *MergedAudio) Read(p []byte) (int, error) {
+	return a.reader.Read(p)
+}
+
+// Size 返回合并后音频的字节数
+func (a *MergedAudio) Size() int64 {
+	return a.size
+}
+
+// Close 关闭音频流并清理临时文件
+func (a *MergedAudio) Close() error {
+	var errs []error
+	if a.closer != nil {
+		errs = append(errs, a.closer.Close())
+	}
+	if a.cleanup != nil {
+		errs = append(errs, a.cleanup())
+	}
+	return errors.Join(errs...)
+}
+
+// MergeStore 合并分段存储中的音频
+// 文件存储使用 FFmpeg concat demuxer 直接读取磁盘上的分段，并将结果写入同一目录，
+// 内存占用与文本长度无关；其他存储回退到内存合并
+func (m *FFmpegMerger) MergeStore(ctx context.Context, store SegmentStore) (*MergedAudio, error) {
+	if store.Len() == 0 {
+		return nil, errors.New("no segments to merge")
+	}
+	for i := 0; i < store.Len(); i++ {
+		if !store.Has(i) {
+			return nil, fmt.Errorf("missing audio segment at index %d", i)
+		}
+	}
+
+	fileStore, ok := store.(*FileStore)
+	if !ok {
+		return m.mergeStoreInMemory(store)
+	}
+
+	// 只有一个片段时直接返回该文件
+	if fileStore.Len() == 1 {
+		return openMerged(fileStore.Path(0), fileStore.Close)
+	}
+
+	output := filepath.Join(fileStore.Dir(), "merged.mp3")
+	if err := m.checkFFmpeg(); err != nil {
+		m.logger.Warn().Err(err).Msg("FFmpeg not available, falling back to frame concatenation")
+		if err := concatFiles(fileStore, output); err != nil {
+			return nil, err
+		}
+		return openMerged(output, fileStore.Close)
+	}
+
+	if err := m.concatWithDemuxer(ctx, fileStore, output); err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		m.logger.Warn().Err(err).Msg("FFmpeg concat failed, falling back to frame concatenation")
+		if err := concatFiles(fileStore, output); err != nil {
+			return nil, err
+		}
+	}
+	return openMerged(output, fileStore.Close)
+}
+
+// concatWithDemuxer 使用 FFmpeg concat demuxer 无损拼接磁盘上的分段
+func (m *FFmpegMerger) concatWithDemuxer(ctx context.Context, store *FileStore, output string) error {
+	listPath := filepath.Join(store.Dir(), "concat.txt")
+	var list strings.Builder
+	for i := 0; i < store.Len(); i++ {
+		// concat 列表中的单引号需要转义为 '\''
+		path := strings.ReplaceAll(store.Path(i), "'", `'\''`)
+		fmt.Fprintf(&list, "file '%s'\n", path)
+	}
+	if err := os.WriteFile(listPath, []byte(list.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to write concat list: %w", err)
+	}
+
+	// 所有分段的编码参数一致，直接复制音频流，无需重新编码
+	cmd := exec.CommandContext(ctx,
+		m.ffmpegPath,
+		"-hide_banner",
+		"-loglevel", "error",
+		"-f", "concat",
+		"-safe", "0",
+		"-i", listPath,
+		"-c", "copy",
+		"-f", "mp3",
+		"-y", output,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		m.logger.Error().
+			Err(err).
+			Str("stderr", stderr.String()).
+			Msg("FFmpeg concat merge failed")
+		return fmt.Errorf("ffmpeg concat failed: %w, stderr: %s", err, stderr.String())
+	}
+
+	m.logger.Debug().Int("segments", store.Len()).Msg("Successfully merged audio segments via concat demuxer")
+	return nil
+}
+
+// mergeStoreInMemory 将存储中的分段读入内存后合并
+func (m *FFmpegMerger) mergeStoreInMemory(store SegmentStore) (*MergedAudio, error) {
+	segments := make([][]byte, store.Len())
+	for i := range segments {
+		r, err := store.Open(i)
+		if err != nil {
+			return nil, err
+		}
+		segments[i], err = io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read segment %d: %w", i, err)
+		}
+	}
+
+	merged, err := m.Merge(segments)
+	if err != nil {
+		return nil, err
+	}
+	return &MergedAudio{
+		reader:  bytes.NewReader(merged),
+		size:    int64(len(merged)),
+		cleanup: store.Close,
+	}, nil
+}
+
+// openMerged 打开合并结果文件
+func openMerged(path string, cleanup func() error) (*MergedAudio, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open merged audio: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to stat merged audio: %w", err)
+	}
+	return &MergedAudio{
+		reader:  f,
+		closer:  f,
+		size:    info.Size(),
+		cleanup: cleanup,
+	}, nil
+}
+
+// concatFiles 逐个读取分段文件，去除 ID3 标签后按帧顺序拼接到 output（回退方案）
+func concatFiles(store *FileStore, output string) error {
+	out, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create merged audio: %w", err)
+	}
+	w := bufio.NewWriter(out)
+
+	for i := 0; i < store.Len(); i++ {
+		if err := copySegment(w, store, i); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to write merged audio: %w", err)
+	}
+	return out.Close()
+}
+
+// copySegment 将单个分段去除 ID3 标签后写入 w
+func copySegment(w io.Writer, store SegmentStore, index int) error {
+	r, err := store.Open(index)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if _, err := copyWithoutID3(w, r); err != nil {
+		return fmt.Errorf("failed to copy segment %d: %w", index, err)
+	}
+	return nil
+}
+
+// copyWithoutID3 复制 MP3 数据，跳过开头的 ID3v2 标签
+func copyWithoutID3(w io.Writer, r io.Reader) (int64, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(10)
+	if err == nil && header[0] == 'I' && header[1] == 'D' && header[2] == '3' {
+		size := int(header[6])<<21 | int(header[7])<<14 | int(header[8])<<7 | int(header[9])
+		if _, err := br.Discard(size + 10); err != nil {
+			return 0, err
+		}
+	}
+	return io.Copy(w, br)
+}
@@ -0,0 +1,153 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// TestFileStore 测试文件存储的读写、容量限制与清理
+func TestFileStore(t *testing.T) {
+	base := t.TempDir()
+	store, err := NewFileStore(base, 2, 100)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+
+	if err := store.Put(0, bytes.Repeat([]byte{1}, 60)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := store.Put(1, bytes.Repeat([]byte{2}, 60)); !errors.Is(err, ErrStoreFull) {
+		t.Fatalf("超出容量时期望 ErrStoreFull, got %v", err)
+	}
+	// 覆盖已有分段只计算增量
+	if err := store.Put(0, bytes.Repeat([]byte{3}, 40)); err != nil {
+		t.Fatalf("覆盖分段失败: %v", err)
+	}
+	if err := store.Put(1, bytes.Repeat([]byte{4}, 60)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got := store.TotalSize(); got != 100 {
+		t.Errorf("TotalSize() = %d, want 100", got)
+	}
+
+	r, err := store.Open(0)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if !bytes.Equal(data, bytes.Repeat([]byte{3}, 40)) {
+		t.Error("读取的分段内容与写入不一致")
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := os.Stat(store.Dir()); !os.IsNotExist(err) {
+		t.Error("Close() 后存储目录应被删除")
+	}
+}
+
+// TestMemoryStore 测试内存存储的容量限制
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore(2, 10)
+	if err := store.Put(0, make([]byte, 8)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := store.Put(1, make([]byte, 8)); !errors.Is(err, ErrStoreFull) {
+		t.Fatalf("超出容量时期望 ErrStoreFull, got %v", err)
+	}
+	if store.Has(1) {
+		t.Error("写入失败的分段不应存在")
+	}
+	if _, err := store.Open(1); err == nil {
+		t.Error("打开不存在的分段应返回错误")
+	}
+}
+
+// TestCleanupStaleStores 测试只清理超时的残留目录
+func TestCleanupStaleStores(t *testing.T) {
+	base := t.TempDir()
+	stale := filepath.Join(base, storeDirPrefix+"stale")
+	fresh := filepath.Join(base, storeDirPrefix+"fresh")
+	other := filepath.Join(base, "other")
+	for _, dir := range []string{stale, fresh, other} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	for _, dir := range []string{stale, other} {
+		if err := os.Chtimes(dir, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := CleanupStaleStores(base, time.Hour)
+	if err != nil {
+		t.Fatalf("CleanupStaleStores() error = %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("removed = %d, want 1", removed)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("残留目录应被删除")
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Error("未超时的目录不应被删除")
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Error("非分段存储目录不应被删除")
+	}
+}
+
+// TestMergeStore_Fallback 测试 FFmpeg 不可用时从磁盘按帧拼接并流式输出
+func TestMergeStore_Fallback(t *testing.T) {
+	store, err := NewFileStore(t.TempDir(), 2, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+
+	first := makeFrames(testHeader, 3)
+	second := makeFrames(testHeader, 2)
+	// 第二个分段带有 ID3 标签，合并时应被去除
+	tagged := append([]byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, 4, 0, 0, 0, 0}, second...)
+	if err := store.Put(0, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(1, tagged); err != nil {
+		t.Fatal(err)
+	}
+
+	merger := NewFFmpegMerger(filepath.Join(t.TempDir(), "missing-ffmpeg"), zerolog.Nop())
+	merged, err := merger.MergeStore(context.Background(), store)
+	if err != nil {
+		t.Fatalf("MergeStore() error = %v", err)
+	}
+	data, err := io.ReadAll(merged)
+	if err != nil {
+		t.Fatalf("读取合并结果失败: %v", err)
+	}
+	if err := merged.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := append(append([]byte{}, first...), second...)
+	if !bytes.Equal(data, want) {
+		t.Errorf("合并结果长度 = %d, want %d", len(data), len(want))
+	}
+	if merged.Size() != int64(len(want)) {
+		t.Errorf("Size() = %d, want %d", merged.Size(), len(want))
+	}
+	if _, err := os.Stat(store.Dir()); !os.IsNotExist(err) {
+		t.Error("关闭合并结果后存储目录应被删除")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -78,9 +79,10 @@ func (s *cachingService) SynthesizeSpeech(ctx context.Context, req models.TTSReq
 	if resp, found := s.cache.Get(key); found {
 		atomic.AddInt64(&s.hits, 1)
 		s.logger.Debug().Str("key", key).Msg("Cache hit")
-		result := resp.(*models.TTSResponse)
+		// 返回副本，避免并发请求修改缓存中的共享对象
+		result := *resp.(*models.TTSResponse)
 		result.CacheHit = true
-		return result, nil
+		return &result, nil
 	}
 
 	atomic.AddInt64(&s.misses, 1)
@@ -254,6 +256,12 @@ func (s *cachingService) generateCacheKey(req models.TTSRequest) string {
 		hash.Write([]byte(normalizeValue(req.Pitch)))
 		hash.Write([]byte("|style:"))
 		hash.Write([]byte(normalizeValue(req.Style)))
+		hash.Write([]byte("|volume:"))
+		hash.Write([]byte(normalizeValue(req.Volume)))
+		hash.Write([]byte("|styledegree:"))
+		hash.Write([]byte(strconv.FormatFloat(req.StyleDegree, 'f', -1, 64)))
+		hash.Write([]byte("|role:"))
+		hash.Write([]byte(normalizeValue(req.Role)))
 		hash.Write([]byte("|format:"))
 		hash.Write([]byte(normalizeValue(format)))
 	}
@@ -0,0 +1,389 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"tts/internal/metrics"
+	"tts/internal/models"
+	"tts/internal/tts/audio"
+)
+
+// ErrInvalidScript 表示剧本无法解析（未知角色、没有台词、参数越界等）
+var ErrInvalidScript = errors.New("invalid dialogue script")
+
+// NarratorName 没有标注角色的行归属的角色
+const NarratorName = "narrator"
+
+// MaxLineGap 行间静音的上限
+const MaxLineGap = 10 * time.Second
+
+var (
+	// bracketLine 匹配 "[角色] 台词"，角色必须在角色表中
+	bracketLine = regexp.MustCompile(`^\[([^\[\]]{1,32})\]\s*(.*)$`)
+	// colonLine 匹配 "角色：台词"，只有角色在角色表中时才视为台词，避免误判 "注意：…" 之类的叙述
+	colonLine = regexp.MustCompile(`^([^\s:：“”"「」]{1,16})\s*[:：]\s*(.+)$`)
+
+	narratorAliases = map[string]bool{NarratorName: true, "旁白": true}
+)
+
+// ScriptLine 剧本中的一行台词
+type ScriptLine struct {
+	Speaker string
+	Text    string
+}
+
+// ParseScript 解析剧本，每个非空行为一行台词，未标注角色的行归属旁白
+func ParseScript(script string, cast map[string]models.CastMember) ([]ScriptLine, error) {
+	var lines []ScriptLine
+	for n, raw := range strings.Split(strings.ReplaceAll(script, "\r\n", "\n"), "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+
+		speaker, text := NarratorName, line
+		if m := bracketLine.FindStringSubmatch(line); m != nil {
+			name := strings.TrimSpace(m[1])
+			if _, ok := lookupCast(cast, name); !ok && !narratorAliases[strings.ToLower(name)] {
+				return nil, fmt.Errorf("%w: line %d: unknown speaker %q", ErrInvalidScript, n+1, name)
+			}
+			speaker, text = name, m[2]
+		} else if m := colonLine.FindStringSubmatch(line); m != nil {
+			if _, ok := lookupCast(cast, m[1]); ok || narratorAliases[strings.ToLower(m[1])] {
+				speaker, text = m[1], m[2]
+			}
+		}
+
+		text = stripEnclosingQuotes(strings.TrimSpace(text))
+		if text == "" {
+			continue
+		}
+		lines = append(lines, ScriptLine{Speaker: speaker, Text: text})
+	}
+	return lines, nil
+}
+
+// RewriteScript 解析剧本并用 rewrite 改写每行台词（voice 为该行使用的语音），
+// 返回以 "[角色] 台词" 形式重新拼接的剧本，角色名保持不变。没有台词被改写时返回原剧本
+func (s *LongTextTTSService) RewriteScript(req models.TTSRequest, rewrite func(text, voice string) string) (string, error) {
+	cast := mergeCast(s.cast, req.Cast)
+	lines, err := ParseScript(req.Script, cast)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	changed := false
+	for _, line := range lines {
+		member, _ := lookupCast(cast, line.Speaker)
+		text := rewrite(line.Text, voiceOr(member.Voice, req.Voice))
+		changed = changed || text != line.Text
+		fmt.Fprintf(&b, "[%s] %s\n", line.Speaker, text)
+	}
+	if !changed {
+		return req.Script, nil
+	}
+	return b.String(), nil
+}
+
+// lookupCast 按角色名查找（忽略大小写），旁白的别名互相通用
+func lookupCast(cast map[string]models.CastMember, name string) (models.CastMember, bool) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	for k, member := range cast {
+		if strings.ToLower(k) == key {
+			return member, true
+		}
+	}
+	if narratorAliases[key] {
+		for alias := range narratorAliases {
+			for k, member := range cast {
+				if strings.ToLower(k) == alias {
+					return member, true
+				}
+			}
+		}
+	}
+	return models.CastMember{}, false
+}
+
+// stripEnclosingQuotes 去除整句外层的引号，如 “你好” -> 你好
+func stripEnclosingQuotes(text string) string {
+	for _, pair := range [][2]string{{"“", "”"}, {`"`, `"`}, {"「", "」"}, {"『", "』"}} {
+		if len(text) > len(pair[0])+len(pair[1]) && strings.HasPrefix(text, pair[0]) && strings.HasSuffix(text, pair[1]) {
+			inner := text[len(pair[0]) : len(text)-len(pair[1])]
+			// 只去除成对的外层引号，"“甲”和“乙”" 这样的句子保持不变
+			if !strings.Contains(inner, pair[0]) && !strings.Contains(inner, pair[1]) {
+				return strings.TrimSpace(inner)
+			}
+		}
+	}
+	return text
+}
+
+// mergeCast 合并配置的角色表与请求的角色表，请求优先
+func mergeCast(configured, requested map[string]models.CastMember) map[string]models.CastMember {
+	cast := make(map[string]models.CastMember, len(configured)+len(requested))
+	for name, member := range configured {
+		cast[strings.ToLower(name)] = member
+	}
+	for name, member := range requested {
+		cast[strings.ToLower(name)] = member
+	}
+	return cast
+}
+
+// lineRequest 生成一行台词的合成请求，角色未指定的参数继承请求的默认值
+func lineRequest(req models.TTSRequest, member models.CastMember, text string) models.TTSRequest {
+	lineReq := models.TTSRequest{
+		Text:   text,
+		Voice:  req.Voice,
+		Rate:   req.Rate,
+		Pitch:  req.Pitch,
+		Style:  req.Style,
+		Format: req.Format,
+
+		Volume:      req.Volume,
+		StyleDegree: req.StyleDegree,
+		Role:        req.Role,
+
+		Lexicon: req.Lexicon,
+	}
+	if member.Voice != "" {
+		lineReq.Voice = member.Voice
+		// 默认风格和角色属于默认语音
+		lineReq.Style, lineReq.StyleDegree, lineReq.Role = "", 0, ""
+	}
+	if member.Style != "" {
+		lineReq.Style = member.Style
+	}
+	if member.Rate != "" {
+		lineReq.Rate = member.Rate
+	}
+	if member.Pitch != "" {
+		lineReq.Pitch = member.Pitch
+	}
+	return lineReq
+}
+
+// slotStore 将分段索引映射到底层存储的槽位，为行间静音预留位置
+type slotStore struct {
+	audio.SegmentStore
+	slots []int
+}
+
+// Put 保存分段到对应槽位
+func (s *slotStore) Put(index int, data []byte) error {
+	return s.SegmentStore.Put(s.slots[index], data)
+}
+
+// Open 打开分段对应槽位的音频
+func (s *slotStore) Open(index int) (io.ReadCloser, error) {
+	return s.SegmentStore.Open(s.slots[index])
+}
+
+// Has 判断分段是否已保存
+func (s *slotStore) Has(index int) bool {
+	return s.SegmentStore.Has(s.slots[index])
+}
+
+// Len 返回分段数（不含静音槽位）
+func (s *slotStore) Len() int {
+	return len(s.slots)
+}
+
+// scriptPart 一行台词及其合成请求
+type scriptPart struct {
+	line ScriptLine
+	req  models.TTSRequest
+}
+
+// synthesizeScript 合成多角色剧本
+//
+// 每行台词使用角色对应的语音，行与行之间插入静音，并返回每行的时间清单。
+func (s *LongTextTTSService) synthesizeScript(ctx context.Context, req models.TTSRequest, plan segmentPlan, startTime time.Time) (*models.TTSResponse, error) {
+	gap := s.lineGap
+	if req.LineGapMs != nil {
+		gap = time.Duration(*req.LineGapMs) * time.Millisecond
+		if gap < 0 || gap > MaxLineGap {
+			return nil, fmt.Errorf("%w: line_gap_ms must be between 0 and %d", ErrInvalidScript, MaxLineGap.Milliseconds())
+		}
+	}
+
+	cast := mergeCast(s.cast, req.Cast)
+	lines, err := ParseScript(req.Script, cast)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: script contains no lines", ErrInvalidScript)
+	}
+
+	parts := make([]scriptPart, len(lines))
+	for i, line := range lines {
+		member, _ := lookupCast(cast, line.Speaker)
+		parts[i] = scriptPart{line: line, req: lineRequest(req, member, line.Text)}
+	}
+
+	s.logger.Info().
+		Int("lines", len(lines)).
+		Int("speakers", len(cast)).
+		Dur("line_gap", gap).
+		Msg("Dialogue script parsed")
+
+	return s.synthesizeLines(ctx, req, parts, gap, plan, startTime)
+}
+
+// synthesizeLines 逐行合成并按顺序合并
+//
+// 每行按请求的分段方案（策略、长度上限和预算单位）继续切分；所有分段经工作池并发合成后按顺序合并，
+// gap 大于 0 时行与行之间插入静音，返回每行的时间清单。
+func (s *LongTextTTSService) synthesizeLines(ctx context.Context, req models.TTSRequest, parts []scriptPart, gap time.Duration, plan segmentPlan, startTime time.Time) (*models.TTSResponse, error) {
+	// 每行可能有多个分段；分段之后、下一行之前预留一个静音槽位
+	var (
+		requests []models.TTSRequest
+		lineOf   []int
+		slots    []int
+		gapSlots []int
+	)
+	slot := 0
+	for i, part := range parts {
+		for _, seg := range segmentWithBudget(plan.strategy, part.line.Text, plan.limit, plan.unit) {
+			segReq := part.req
+			segReq.Text = seg
+			requests = append(requests, segReq)
+			lineOf = append(lineOf, i)
+			slots = append(slots, slot)
+			slot++
+		}
+		if i < len(parts)-1 && gap > 0 {
+			gapSlots = append(gapSlots, slot)
+			slot++
+		}
+	}
+
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("%w: no text left after segmentation", ErrInvalidSegmentOptions)
+	}
+
+	store := s.newSegmentStore(slot)
+	merged := false
+	defer func() {
+		if !merged {
+			store.Close()
+		}
+	}()
+
+	jobID := fmt.Sprintf("lines_%d", time.Now().UnixNano())
+	outcome, err := s.synthesizeSegments(ctx, requests, req.BestEffort, jobID, &slotStore{SegmentStore: store, slots: slots})
+	if err != nil {
+		return nil, err
+	}
+	metrics.GlobalMetrics.RecordSegmentCache(int64(outcome.cacheHits), int64(len(requests)-outcome.cacheHits))
+
+	// 计算每行时长，并写入行间静音
+	durations := make([]time.Duration, len(parts))
+	for idx, line := range lineOf {
+		d, err := segmentDuration(store, slots[idx])
+		if err != nil {
+			return nil, fmt.Errorf("failed to measure segment %d: %w", idx, err)
+		}
+		durations[line] += d
+	}
+	var gapDuration time.Duration
+	if len(gapSlots) > 0 {
+		reference, err := readFirstSegment(store)
+		if err != nil {
+			return nil, err
+		}
+		silence, err := audio.GenerateSilence(reference, gap)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate line gap: %w", err)
+		}
+		if gapDuration, err = audio.Duration(silence); err != nil {
+			return nil, err
+		}
+		for _, gs := range gapSlots {
+			if err := store.Put(gs, silence); err != nil {
+				return nil, fmt.Errorf("failed to store line gap: %w", err)
+			}
+		}
+	}
+
+	substitutedLines := make(map[int]bool)
+	for _, idx := range outcome.substituted {
+		substitutedLines[lineOf[idx]] = true
+	}
+	manifest := make([]models.LineTiming, len(parts))
+	var cursor time.Duration
+	for i, part := range parts {
+		manifest[i] = models.LineTiming{
+			Index:       i,
+			Speaker:     part.line.Speaker,
+			Voice:       part.req.Voice,
+			Text:        part.line.Text,
+			StartMs:     cursor.Milliseconds(),
+			EndMs:       (cursor + durations[i]).Milliseconds(),
+			Substituted: substitutedLines[i],
+		}
+		cursor += durations[i]
+		if i < len(parts)-1 {
+			cursor += gapDuration
+		}
+	}
+
+	audioStream, err := s.merger.MergeStore(ctx, store)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge audio segments: %w", err)
+	}
+	merged = true
+
+	s.logger.Info().
+		Int("lines", len(parts)).
+		Int("segments", len(requests)).
+		Int("cache_hits", outcome.cacheHits).
+		Int("retries", outcome.retries).
+		Dur("audio_duration", cursor).
+		Dur("total_duration", time.Since(startTime)).
+		Int64("merged_bytes", audioStream.Size()).
+		Msg("Line synthesis completed")
+
+	substituted := make([]int, 0, len(substitutedLines))
+	for i := range parts {
+		if substitutedLines[i] {
+			substituted = append(substituted, i)
+		}
+	}
+	if len(substituted) == 0 {
+		substituted = nil
+	}
+
+	return &models.TTSResponse{
+		ContentType: "audio/mpeg",
+
+		SubstitutedSegments: substituted,
+		Lines:               manifest,
+
+		AudioStream: audioStream,
+		AudioSize:   audioStream.Size(),
+	}, nil
+}
+
+// segmentDuration 读取存储中的分段并计算其播放时长
+func segmentDuration(store audio.SegmentStore, index int) (time.Duration, error) {
+	r, err := store.Open(index)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	return audio.Duration(data)
+}
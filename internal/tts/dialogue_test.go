@@ -0,0 +1,204 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"tts/internal/models"
+)
+
+// mp3Frame 24kHz 48kbps 单声道 MP3 帧（144 字节，24ms）
+func mp3Frame() []byte {
+	frame := make([]byte, 144)
+	copy(frame, []byte{0xFF, 0xF3, 0x64, 0xC4})
+	return frame
+}
+
+// mockScriptService 每个请求按语音返回固定帧数的音频，并记录收到的请求
+type mockScriptService struct {
+	mu       sync.Mutex
+	frames   map[string]int
+	requests []models.TTSRequest
+}
+
+func (m *mockScriptService) SynthesizeSpeech(ctx context.Context, req models.TTSRequest) (*models.TTSResponse, error) {
+	m.mu.Lock()
+	m.requests = append(m.requests, req)
+	m.mu.Unlock()
+
+	var data []byte
+	for i := 0; i < m.frames[req.Voice]; i++ {
+		data = append(data, mp3Frame()...)
+	}
+	return &models.TTSResponse{AudioContent: data, ContentType: "audio/mpeg"}, nil
+}
+
+func (m *mockScriptService) ListVoices(ctx context.Context, locale string) ([]models.Voice, error) {
+	return []models.Voice{}, nil
+}
+
+// TestParseScript 测试剧本解析
+func TestParseScript(t *testing.T) {
+	cast := mergeCast(map[string]models.CastMember{
+		"张三":       {Voice: "zh-CN-YunxiNeural"},
+		"Narrator": {Voice: "zh-CN-YunjianNeural"},
+	}, nil)
+
+	script := "[narrator] 夜深了。\n\n张三：“谁呀？”\r\n旁白：门开了。\n注意：这是叙述。\n「无人应答」"
+	lines, err := ParseScript(script, cast)
+	if err != nil {
+		t.Fatalf("ParseScript() error = %v", err)
+	}
+
+	want := []ScriptLine{
+		{Speaker: "narrator", Text: "夜深了。"},
+		{Speaker: "张三", Text: "谁呀？"},
+		{Speaker: "旁白", Text: "门开了。"},
+		{Speaker: NarratorName, Text: "注意：这是叙述。"},
+		{Speaker: NarratorName, Text: "无人应答"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("ParseScript() = %+v, want %+v", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("第 %d 行 = %+v, want %+v", i, lines[i], want[i])
+		}
+	}
+
+	// 旁白别名共用同一个角色
+	if member, ok := lookupCast(cast, "旁白"); !ok || member.Voice != "zh-CN-YunjianNeural" {
+		t.Errorf("lookupCast(旁白) = %+v, %v", member, ok)
+	}
+
+	if _, err := ParseScript("[李四] 你好", cast); !errors.Is(err, ErrInvalidScript) {
+		t.Errorf("未知角色期望 ErrInvalidScript, got %v", err)
+	}
+}
+
+// TestStripEnclosingQuotes 测试只去除成对的外层引号
+func TestStripEnclosingQuotes(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"“你好”", "你好"},
+		{`"hello"`, "hello"},
+		{"『走吧』", "走吧"},
+		{"“甲”和“乙”", "“甲”和“乙”"},
+		{"“”", "“”"},
+		{"没有引号", "没有引号"},
+	}
+	for _, tt := range tests {
+		if got := stripEnclosingQuotes(tt.in); got != tt.want {
+			t.Errorf("stripEnclosingQuotes(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestLineRequest 测试角色参数覆盖请求默认值
+func TestLineRequest(t *testing.T) {
+	req := models.TTSRequest{Voice: "zh-CN-XiaoxiaoNeural", Style: "cheerful", Rate: "0", Pitch: "0", Format: "fmt"}
+
+	got := lineRequest(req, models.CastMember{}, "旁白")
+	if got.Voice != req.Voice || got.Style != "cheerful" || got.Text != "旁白" || got.Format != "fmt" {
+		t.Errorf("未配置的角色应继承默认值, got %+v", got)
+	}
+
+	got = lineRequest(req, models.CastMember{Voice: "zh-CN-YunxiNeural", Rate: "+10"}, "台词")
+	if got.Voice != "zh-CN-YunxiNeural" || got.Style != "" || got.Rate != "+10" || got.Pitch != "0" {
+		t.Errorf("角色参数应覆盖默认值, got %+v", got)
+	}
+}
+
+// TestRewriteScript 测试按每行角色的语音改写台词，角色名保持不变
+func TestRewriteScript(t *testing.T) {
+	service := &LongTextTTSService{cast: map[string]models.CastMember{"阿明": {Voice: "zh-TW-HsiaoChenNeural"}}}
+	req := models.TTSRequest{Voice: "zh-CN-XiaoxiaoNeural", Script: "阿明：“这里”\n旁白说话"}
+
+	mark := func(text, voice string) string {
+		if voice == "zh-TW-HsiaoChenNeural" {
+			return text + "!"
+		}
+		return text
+	}
+	got, err := service.RewriteScript(req, mark)
+	if err != nil {
+		t.Fatalf("RewriteScript() error = %v", err)
+	}
+	if want := "[阿明] 这里!\n[narrator] 旁白说话\n"; got != want {
+		t.Errorf("RewriteScript() = %q, want %q", got, want)
+	}
+
+	if got, _ := service.RewriteScript(req, func(text, _ string) string { return text }); got != req.Script {
+		t.Errorf("未改写时应返回原剧本, got %q", got)
+	}
+	req.Script = "[路人] 你好"
+	if _, err := service.RewriteScript(req, mark); !errors.Is(err, ErrInvalidScript) {
+		t.Errorf("未知角色期望 ErrInvalidScript, got %v", err)
+	}
+}
+
+// TestSynthesizeScript 测试剧本逐行合成，按顺序合并并返回时间清单
+func TestSynthesizeScript(t *testing.T) {
+	client := &mockScriptService{frames: map[string]int{"A": 10, "B": 5}}
+	service := NewLongTextTTSService(client, LongTextConfig{
+		WorkerCount:     2,
+		UseSmartSegment: true,
+		Cast:            map[string]models.CastMember{"甲": {Voice: "A"}},
+		LineGap:         96 * time.Millisecond,
+	}, zerolog.Nop())
+	defer service.Close()
+
+	req := models.TTSRequest{
+		Voice:  "B",
+		Script: "甲：第一句。\n第二句。\n[甲] 第三句。",
+		Format: "audio-24khz-48kbitrate-mono-mp3",
+	}
+	resp, err := service.SynthesizeSpeech(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SynthesizeSpeech() error = %v", err)
+	}
+	defer resp.AudioStream.Close()
+
+	// 10 帧 + 4 帧静音 + 5 帧 + 4 帧静音 + 10 帧，每帧 24ms
+	want := []struct {
+		voice      string
+		start, end int64
+	}{
+		{"A", 0, 240},
+		{"B", 336, 456},
+		{"A", 552, 792},
+	}
+	if len(resp.Lines) != len(want) {
+		t.Fatalf("时间清单 = %+v", resp.Lines)
+	}
+	for i, w := range want {
+		line := resp.Lines[i]
+		if line.Voice != w.voice || line.StartMs != w.start || line.EndMs != w.end {
+			t.Errorf("第 %d 行 = %+v, want voice=%s %d-%d", i, line, w.voice, w.start, w.end)
+		}
+	}
+
+	data, err := io.ReadAll(resp.AudioStream)
+	if err != nil {
+		t.Fatalf("读取音频失败: %v", err)
+	}
+	if len(data) != 33*144 {
+		t.Errorf("合并音频 %d 字节, want %d", len(data), 33*144)
+	}
+	if len(client.requests) != 3 {
+		t.Errorf("期望 3 次合成请求, got %d", len(client.requests))
+	}
+
+	negative := -1
+	req.LineGapMs = &negative
+	if _, err := service.SynthesizeSpeech(context.Background(), req); !errors.Is(err, ErrInvalidScript) {
+		t.Errorf("非法 line_gap_ms 期望 ErrInvalidScript, got %v", err)
+	}
+}
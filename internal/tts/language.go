@@ -0,0 +1,42 @@
+package tts
+
+import (
+	"tts/internal/lang"
+	"tts/internal/models"
+)
+
+// languageParts 将文本按语言切分为逐段合成的片段
+//
+// 配置了语音的语言切换到对应语音，其他语言沿用请求的语音；
+// 切分后只使用一个语音时返回 nil，由调用方按普通长文本处理。
+func (s *LongTextTTSService) languageParts(req models.TTSRequest) []scriptPart {
+	primary := lang.LocaleOf(req.Voice)
+	runs := lang.Detect(req.Text, primary, s.minLanguageRun)
+
+	var parts []scriptPart
+	for _, run := range runs {
+		member := models.CastMember{}
+		if run.Locale != primary {
+			member.Voice = lang.VoiceFor(s.localeVoices, run.Locale)
+		}
+		// 相邻的同一语音片段合并，减少分段数
+		if n := len(parts); n > 0 && parts[n-1].req.Voice == voiceOr(member.Voice, req.Voice) {
+			parts[n-1].line.Text += run.Text
+			parts[n-1].req.Text = parts[n-1].line.Text
+			continue
+		}
+		line := ScriptLine{Speaker: run.Locale, Text: run.Text}
+		parts = append(parts, scriptPart{line: line, req: lineRequest(req, member, run.Text)})
+	}
+	if len(parts) < 2 {
+		return nil
+	}
+	return parts
+}
+
+func voiceOr(voice, fallback string) string {
+	if voice != "" {
+		return voice
+	}
+	return fallback
+}
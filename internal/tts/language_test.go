@@ -0,0 +1,40 @@
+package tts
+
+import (
+	"testing"
+
+	"tts/internal/models"
+)
+
+// TestLanguageParts 测试长文本按语言切分为使用不同语音的片段
+func TestLanguageParts(t *testing.T) {
+	service := &LongTextTTSService{
+		minLanguageRun: 8,
+		localeVoices:   map[string]string{"en-us": "en-US-JennyNeural"},
+	}
+
+	req := models.TTSRequest{
+		Text:  "他说：The quick brown fox jumps over the lazy dog. 然后说了一句日语：これは日本語の文章です。",
+		Voice: "zh-CN-XiaoxiaoNeural",
+	}
+	parts := service.languageParts(req)
+
+	// 日语未配置语音，与前后的中文一起由默认语音朗读
+	wantVoices := []string{"zh-CN-XiaoxiaoNeural", "en-US-JennyNeural", "zh-CN-XiaoxiaoNeural"}
+	if len(parts) != len(wantVoices) {
+		t.Fatalf("languageParts() = %+v", parts)
+	}
+	for i, voice := range wantVoices {
+		if parts[i].req.Voice != voice || parts[i].req.Text != parts[i].line.Text {
+			t.Errorf("片段 %d = %+v, want voice %s", i, parts[i], voice)
+		}
+	}
+	if parts[2].line.Text != "然后说了一句日语：これは日本語の文章です。" {
+		t.Errorf("相邻的同一语音片段应合并, got %q", parts[2].line.Text)
+	}
+
+	req.Text = "只有中文，以及一个 iPhone。"
+	if parts := service.languageParts(req); parts != nil {
+		t.Errorf("单一语言期望 nil, got %+v", parts)
+	}
+}
@@ -0,0 +1,225 @@
+package limiter
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"tts/internal/metrics"
+)
+
+// ErrQueueTimeout 表示在排队等待上游并发槽位时超时
+var ErrQueueTimeout = errors.New("upstream concurrency queue timeout")
+
+// Priority 上游调用的优先级
+type Priority int
+
+const (
+	// PriorityBackground 后台任务（如长文本分段任务）
+	PriorityBackground Priority = iota
+	// PriorityInteractive 交互式请求（如直接的 /api/tts 调用），默认优先级
+	PriorityInteractive
+
+	numPriorities = int(PriorityInteractive) + 1
+)
+
+// String 返回优先级名称
+func (p Priority) String() string {
+	switch p {
+	case PriorityBackground:
+		return "background"
+	case PriorityInteractive:
+		return "interactive"
+	default:
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+}
+
+type priorityKey struct{}
+
+// WithPriority 返回携带指定优先级的 context
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext 从 context 中读取优先级，未设置时视为交互式请求
+func PriorityFromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok && p >= 0 && int(p) < numPriorities {
+		return p
+	}
+	return PriorityInteractive
+}
+
+// waiter 表示一个正在排队的请求
+type waiter struct {
+	n     int64
+	ready chan struct{} // 获得槽位时关闭
+}
+
+// Limiter 进程级的加权信号量，支持优先级和排队超时
+//
+// 高优先级的等待者总是先于低优先级的等待者获得槽位；
+// 同一优先级内按 FIFO 顺序分配。
+type Limiter struct {
+	size         int64
+	cur          int64
+	queueTimeout time.Duration
+	waiters      [numPriorities]list.List
+	queued       int64
+	mu           sync.Mutex
+}
+
+// New 创建一个新的限流器
+// size 为最大并发权重，queueTimeout 为最长排队时间（0 表示不限制）
+func New(size int64, queueTimeout time.Duration) *Limiter {
+	if size <= 0 {
+		size = 1
+	}
+	return &Limiter{
+		size:         size,
+		queueTimeout: queueTimeout,
+	}
+}
+
+// Acquire 以 context 中的优先级获取权重为 n 的槽位
+func (l *Limiter) Acquire(ctx context.Context, n int64) error {
+	return l.AcquireWithPriority(ctx, n, PriorityFromContext(ctx))
+}
+
+// AcquireWithPriority 以指定优先级获取权重为 n 的槽位
+// 排队超时返回 ErrQueueTimeout，context 取消时返回 ctx.Err()
+func (l *Limiter) AcquireWithPriority(ctx context.Context, n int64, p Priority) error {
+	if n > l.size {
+		return fmt.Errorf("requested weight %d exceeds limiter size %d", n, l.size)
+	}
+	if p < 0 || int(p) >= numPriorities {
+		p = PriorityInteractive
+	}
+
+	l.mu.Lock()
+	if l.size-l.cur >= n && !l.hasWaitersAtOrAbove(p) {
+		l.cur += n
+		l.publish()
+		l.mu.Unlock()
+		return nil
+	}
+
+	w := waiter{n: n, ready: make(chan struct{})}
+	elem := l.waiters[p].PushBack(w)
+	l.queued++
+	l.publish()
+	l.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if l.queueTimeout > 0 {
+		timer := time.NewTimer(l.queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var err error
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timeout:
+		err = ErrQueueTimeout
+	}
+
+	l.mu.Lock()
+	select {
+	case <-w.ready:
+		// 在放弃的同时恰好获得了槽位，视为未获得并归还
+		l.cur -= n
+		l.notifyWaiters()
+	default:
+		isFront := l.waiters[p].Front() == elem
+		l.waiters[p].Remove(elem)
+		l.queued--
+		// 如果排在队首的等待者离开，后面的等待者可能已经可以获得槽位
+		if isFront && l.size > l.cur {
+			l.notifyWaiters()
+		}
+	}
+	if errors.Is(err, ErrQueueTimeout) {
+		metrics.GlobalMetrics.RecordUpstreamQueueTimeout()
+	}
+	l.publish()
+	l.mu.Unlock()
+
+	return err
+}
+
+// Release 释放权重为 n 的槽位
+func (l *Limiter) Release(n int64) {
+	l.mu.Lock()
+	l.cur -= n
+	if l.cur < 0 {
+		l.mu.Unlock()
+		panic("limiter: released more than held")
+	}
+	l.notifyWaiters()
+	l.publish()
+	l.mu.Unlock()
+}
+
+// InFlight 返回当前已占用的权重
+func (l *Limiter) InFlight() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cur
+}
+
+// Queued 返回当前排队中的请求数
+func (l *Limiter) Queued() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.queued
+}
+
+// Size 返回最大并发权重
+func (l *Limiter) Size() int64 {
+	return l.size
+}
+
+// hasWaitersAtOrAbove 检查是否存在优先级不低于 p 的等待者（需持有锁）
+func (l *Limiter) hasWaitersAtOrAbove(p Priority) bool {
+	for i := int(p); i < numPriorities; i++ {
+		if l.waiters[i].Len() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyWaiters 按优先级从高到低唤醒可以获得槽位的等待者（需持有锁）
+func (l *Limiter) notifyWaiters() {
+	for i := numPriorities - 1; i >= 0; i-- {
+		queue := &l.waiters[i]
+		for {
+			next := queue.Front()
+			if next == nil {
+				break
+			}
+			w := next.Value.(waiter)
+			if l.size-l.cur < w.n {
+				// 队首等待者无法满足时停止，避免大权重请求被饿死，
+				// 也不允许低优先级的请求越过高优先级的队首
+				return
+			}
+			l.cur += w.n
+			l.queued--
+			queue.Remove(next)
+			close(w.ready)
+		}
+	}
+}
+
+// publish 将当前状态同步到全局指标（需持有锁）
+func (l *Limiter) publish() {
+	metrics.GlobalMetrics.SetUpstreamConcurrency(l.cur, l.queued)
+}
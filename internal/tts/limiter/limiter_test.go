@@ -0,0 +1,145 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestLimiter_AcquireRelease 测试基本的获取与释放
+func TestLimiter_AcquireRelease(t *testing.T) {
+	l := New(2, time.Second)
+	ctx := context.Background()
+
+	if err := l.Acquire(ctx, 1); err != nil {
+		t.Fatalf("第一次获取失败: %v", err)
+	}
+	if err := l.Acquire(ctx, 1); err != nil {
+		t.Fatalf("第二次获取失败: %v", err)
+	}
+	if got := l.InFlight(); got != 2 {
+		t.Errorf("InFlight() = %d, want 2", got)
+	}
+
+	l.Release(1)
+	l.Release(1)
+	if got := l.InFlight(); got != 0 {
+		t.Errorf("InFlight() = %d, want 0", got)
+	}
+}
+
+// TestLimiter_QueueTimeout 测试排队超时
+func TestLimiter_QueueTimeout(t *testing.T) {
+	l := New(1, 50*time.Millisecond)
+	ctx := context.Background()
+
+	if err := l.Acquire(ctx, 1); err != nil {
+		t.Fatalf("获取失败: %v", err)
+	}
+	defer l.Release(1)
+
+	err := l.Acquire(ctx, 1)
+	if !errors.Is(err, ErrQueueTimeout) {
+		t.Fatalf("期望 ErrQueueTimeout, 实际: %v", err)
+	}
+	if got := l.Queued(); got != 0 {
+		t.Errorf("超时后 Queued() = %d, want 0", got)
+	}
+}
+
+// TestLimiter_ContextCancel 测试排队期间 context 被取消
+func TestLimiter_ContextCancel(t *testing.T) {
+	l := New(1, 0)
+	if err := l.Acquire(context.Background(), 1); err != nil {
+		t.Fatalf("获取失败: %v", err)
+	}
+	defer l.Release(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := l.Acquire(ctx, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("期望 context.DeadlineExceeded, 实际: %v", err)
+	}
+}
+
+// TestLimiter_InteractiveFirst 测试交互式请求优先于后台任务获得槽位
+func TestLimiter_InteractiveFirst(t *testing.T) {
+	l := New(1, 0)
+	ctx := context.Background()
+
+	if err := l.Acquire(ctx, 1); err != nil {
+		t.Fatalf("获取失败: %v", err)
+	}
+
+	order := make(chan Priority, 2)
+	acquire := func(p Priority) {
+		if err := l.AcquireWithPriority(ctx, 1, p); err != nil {
+			t.Errorf("获取失败: %v", err)
+			return
+		}
+		order <- p
+		l.Release(1)
+	}
+
+	// 后台任务先排队
+	go acquire(PriorityBackground)
+	waitQueued(t, l, 1)
+
+	// 交互式请求后排队
+	go acquire(PriorityInteractive)
+	waitQueued(t, l, 2)
+
+	l.Release(1)
+
+	if first := <-order; first != PriorityInteractive {
+		t.Errorf("第一个获得槽位的是 %s, 期望 interactive", first)
+	}
+	if second := <-order; second != PriorityBackground {
+		t.Errorf("第二个获得槽位的是 %s, 期望 background", second)
+	}
+}
+
+// TestLimiter_Weighted 测试加权获取
+func TestLimiter_Weighted(t *testing.T) {
+	l := New(3, 50*time.Millisecond)
+	ctx := context.Background()
+
+	if err := l.Acquire(ctx, 2); err != nil {
+		t.Fatalf("获取失败: %v", err)
+	}
+	if err := l.Acquire(ctx, 2); !errors.Is(err, ErrQueueTimeout) {
+		t.Fatalf("期望 ErrQueueTimeout, 实际: %v", err)
+	}
+	if err := l.Acquire(ctx, 1); err != nil {
+		t.Fatalf("剩余权重足够时获取失败: %v", err)
+	}
+	if err := l.Acquire(ctx, 4); err == nil {
+		t.Fatal("超过总权重的请求应该直接失败")
+	}
+}
+
+// TestPriorityFromContext 测试默认优先级
+func TestPriorityFromContext(t *testing.T) {
+	if p := PriorityFromContext(context.Background()); p != PriorityInteractive {
+		t.Errorf("默认优先级 = %s, want interactive", p)
+	}
+	ctx := WithPriority(context.Background(), PriorityBackground)
+	if p := PriorityFromContext(ctx); p != PriorityBackground {
+		t.Errorf("优先级 = %s, want background", p)
+	}
+}
+
+// waitQueued 等待排队数量达到 n
+func waitQueued(t *testing.T, l *Limiter, n int64) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for l.Queued() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待排队数量达到 %d 超时", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
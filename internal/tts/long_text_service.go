@@ -3,24 +3,36 @@ package tts
 import (
 	"context"
 	"fmt"
-	"sync"
 	"time"
 	"unicode/utf8"
 
 	"github.com/rs/zerolog"
+	"tts/internal/metrics"
 	"tts/internal/models"
 	"tts/internal/tts/audio"
-	"tts/internal/tts/microsoft"
 )
 
+// staleSpillAge 超过该时间的分段临时目录视为残留
+const staleSpillAge = time.Hour
+
 // LongTextTTSService 长文本 TTS 服务
 type LongTextTTSService struct {
-	client          *microsoft.Client
-	segmenter       SegmentationStrategy
-	merger          audio.Merger
+	client          Service
+	segmenter       SegmentationStrategy // 默认分段策略
+	budgetUnit      BudgetUnit           // 默认分段长度单位
+	ssmlSegmenter   *SSMLSegmenter
+	merger          audio.StoreMerger
 	workerPool      *WorkerPool
 	maxSegmentLen   int
 	minTextForSplit int // 触发分段的最小文本长度
+	spillDir        string
+	maxSpillBytes   int64
+	recovery        recoveryConfig
+	cast            map[string]models.CastMember // 剧本模式的默认角色表
+	lineGap         time.Duration                // 剧本模式的默认行间静音
+	autoLanguage    bool                         // 默认启用自动语言检测
+	minLanguageRun  int                          // 切换语言所需的最少字母数
+	localeVoices    map[string]string            // 语言 -> 语音
 	logger          zerolog.Logger
 }
 
@@ -31,10 +43,30 @@ type LongTextConfig struct {
 	MinTextForSplit  int    // 触发分段的最小文本长度
 	FFmpegPath       string // FFmpeg 可执行文件路径
 	UseSmartSegment  bool   // 是否使用智能分段
+	SegmentStrategy  string // 默认分段策略名称（为空时由 UseSmartSegment 决定）
+	SegmentBudget    string // 分段长度单位：runes 或 ssml_bytes
+
+	AdaptiveConcurrency bool          // 是否启用 AIMD 自适应并发
+	TargetLatency       time.Duration // 自适应并发的目标分段延迟
+
+	SegmentRetries int           // 失败分段的最大重试轮数
+	RetryBackoff   time.Duration // 分段重试的初始退避时间
+	FallbackVoice  string        // 上游拒绝分段时使用的备用语音
+	BestEffort     bool          // 默认启用尽力模式（失败分段以静音替代）
+
+	SpillDir      string // 分段音频临时目录（为空时使用系统临时目录）
+	MaxSpillBytes int64  // 单个请求的分段音频总大小上限（0 表示不限制）
+
+	Cast    map[string]models.CastMember // 剧本模式的角色表
+	LineGap time.Duration                // 剧本模式的行间静音
+
+	AutoLanguage   bool              // 默认启用自动语言检测
+	MinLanguageRun int               // 切换语言所需的最少字母数
+	LocaleVoices   map[string]string // 语言 -> 语音
 }
 
 // NewLongTextTTSService 创建长文本 TTS 服务
-func NewLongTextTTSService(client *microsoft.Client, config LongTextConfig, logger zerolog.Logger) *LongTextTTSService {
+func NewLongTextTTSService(client Service, config LongTextConfig, logger zerolog.Logger) *LongTextTTSService {
 	// 设置默认值
 	if config.MaxSegmentLength <= 0 {
 		config.MaxSegmentLength = 500
@@ -46,31 +78,70 @@ func NewLongTextTTSService(client *microsoft.Client, config LongTextConfig, logg
 		config.MinTextForSplit = 1000 // 1000 字符以下不分段
 	}
 
-	// 选择分段策略
-	var segmenter SegmentationStrategy
-	if config.UseSmartSegment {
+	// 选择默认分段策略，请求可以单独指定
+	strategyName := config.SegmentStrategy
+	if strategyName == "" {
+		strategyName = StrategyFixed
+		if config.UseSmartSegment {
+			strategyName = StrategySmart
+		}
+	}
+	segmenter, err := NewSegmentationStrategy(strategyName, StrategyParams{})
+	if err != nil {
+		logger.Warn().Err(err).Msg("Invalid default segmentation strategy, using smart segmentation")
+		strategyName = StrategySmart
 		segmenter = NewSmartSegmenter()
-		logger.Info().Msg("Using smart segmentation strategy")
-	} else {
-		segmenter = NewFixedLengthSegmenter()
-		logger.Info().Msg("Using fixed-length segmentation strategy")
 	}
+	budgetUnit, err := ParseBudgetUnit(config.SegmentBudget)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Invalid segment budget, counting runes")
+		budgetUnit = BudgetRunes
+	}
+	logger.Info().
+		Str("strategy", strategyName).
+		Str("budget", string(budgetUnit)).
+		Msg("Using segmentation strategy")
 
 	// 创建音频合并器
 	merger := audio.NewFFmpegMerger(config.FFmpegPath, logger)
 
+	// 清理进程异常退出后残留的分段临时目录
+	if removed, err := audio.CleanupStaleStores(config.SpillDir, staleSpillAge); err != nil {
+		logger.Warn().Err(err).Msg("Failed to clean up stale segment stores")
+	} else if removed > 0 {
+		logger.Info().Int("removed", removed).Msg("Cleaned up stale segment stores")
+	}
+
 	// 创建并启动工作池
-	pool := NewWorkerPool(config.WorkerCount, client, logger)
+	pool := NewWorkerPool(config.WorkerCount, client, logger, AdaptiveConfig{
+		Enabled:       config.AdaptiveConcurrency,
+		TargetLatency: config.TargetLatency,
+	})
 	pool.Start()
 
 	return &LongTextTTSService{
 		client:          client,
 		segmenter:       segmenter,
+		budgetUnit:      budgetUnit,
+		ssmlSegmenter:   NewSSMLSegmenter(),
 		merger:          merger,
 		workerPool:      pool,
 		maxSegmentLen:   config.MaxSegmentLength,
 		minTextForSplit: config.MinTextForSplit,
-		logger:          logger,
+		spillDir:        config.SpillDir,
+		maxSpillBytes:   config.MaxSpillBytes,
+		recovery: recoveryConfig{
+			SegmentRetries: config.SegmentRetries,
+			RetryBackoff:   config.RetryBackoff,
+			FallbackVoice:  config.FallbackVoice,
+			BestEffort:     config.BestEffort,
+		},
+		cast:           mergeCast(config.Cast, nil),
+		lineGap:        config.LineGap,
+		autoLanguage:   config.AutoLanguage,
+		minLanguageRun: config.MinLanguageRun,
+		localeVoices:   config.LocaleVoices,
+		logger:         logger,
 	}
 }
 
@@ -83,13 +154,44 @@ func (s *LongTextTTSService) SynthesizeSpeech(ctx context.Context, req models.TT
 		return nil, fmt.Errorf("context cancelled before synthesis: %w", ctx.Err())
 	}
 
-	// 如果文本长度小于阈值，直接调用单次合成
+	// 分段方案同样用于剧本、引号对白和多语言的逐行合成
+	plan, err := s.planSegmentation(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 剧本模式：每行使用角色对应的语音
+	if req.Script != "" {
+		return s.synthesizeScript(ctx, req, plan, startTime)
+	}
+
+	// 引号对白模式：文本中有对白时旁白与对白分别使用不同的语音
+	if req.Text != "" && quoteDialogueEnabled(req) {
+		if parts := SplitDialogue(req.Text); hasDialogue(parts) {
+			return s.synthesizeQuotedDialogue(ctx, req, parts, plan, startTime)
+		}
+	}
+
+	// 自动语言检测：不同语言的片段使用对应语言的语音分别合成
+	if req.Text != "" && (s.autoLanguage || req.AutoLanguage) {
+		if parts := s.languageParts(req); parts != nil {
+			return s.synthesizeLines(ctx, req, parts, 0, plan, startTime)
+		}
+	}
+
+	// 如果文本长度小于阈值，直接调用单次合成；请求显式指定了分段策略时始终分段
 	textLen := utf8.RuneCountInString(req.Text)
-	if textLen <= s.minTextForSplit {
+	if req.SSML != "" {
+		textLen = utf8.RuneCountInString(req.SSML)
+	}
+	if textLen <= s.minTextForSplit && req.SegmentStrategy == "" {
 		s.logger.Info().
 			Int("text_length", textLen).
 			Int("threshold", s.minTextForSplit).
 			Msg("Text length below split threshold, using single synthesis")
+		if req.SSML == "" && req.Lexicon != nil {
+			req = annotateSegment(req, req.Lexicon)
+		}
 		return s.client.SynthesizeSpeech(ctx, req)
 	}
 
@@ -97,23 +199,80 @@ func (s *LongTextTTSService) SynthesizeSpeech(ctx context.Context, req models.TT
 	s.logger.Info().
 		Int("text_length", textLen).
 		Msg("Text length exceeds threshold, using segmented synthesis")
-	return s.synthesizeLongText(ctx, req, startTime)
+	return s.synthesizeLongText(ctx, req, plan, startTime)
+}
+
+// segmentPlan 单个请求的分段方案
+type segmentPlan struct {
+	strategy SegmentationStrategy
+	limit    int
+	unit     BudgetUnit
+}
+
+// planSegmentation 根据请求参数确定分段策略与长度预算，未指定的参数使用服务默认值
+func (s *LongTextTTSService) planSegmentation(req models.TTSRequest) (segmentPlan, error) {
+	plan := segmentPlan{strategy: s.segmenter, limit: s.maxSegmentLen, unit: s.budgetUnit}
+
+	if req.SegmentStrategy != "" {
+		strategy, err := NewSegmentationStrategy(req.SegmentStrategy, StrategyParams{Delimiter: req.SegmentDelimiter})
+		if err != nil {
+			return plan, err
+		}
+		plan.strategy = strategy
+	}
+	if req.SegmentBudget != "" {
+		unit, err := ParseBudgetUnit(req.SegmentBudget)
+		if err != nil {
+			return plan, err
+		}
+		plan.unit = unit
+	}
+	if req.SegmentLength != 0 {
+		if req.SegmentLength < MinRequestSegmentLength || req.SegmentLength > MaxRequestSegmentLength {
+			return plan, fmt.Errorf("%w: segment_length must be between %d and %d",
+				ErrInvalidSegmentOptions, MinRequestSegmentLength, MaxRequestSegmentLength)
+		}
+		plan.limit = req.SegmentLength
+	}
+	return plan, nil
 }
 
 // synthesizeLongText 长文本分段合成
-func (s *LongTextTTSService) synthesizeLongText(ctx context.Context, req models.TTSRequest, startTime time.Time) (*models.TTSResponse, error) {
-	// 1. 文本分段
+func (s *LongTextTTSService) synthesizeLongText(ctx context.Context, req models.TTSRequest, plan segmentPlan, startTime time.Time) (*models.TTSResponse, error) {
+	// 1. 文本分段（SSML 按元素和句子边界切分，每个片段保留原有的上下文元素）
 	segmentStart := time.Now()
-	segments := s.segmenter.Segment(req.Text, s.maxSegmentLen)
+	var segments []string
+	if req.SSML != "" {
+		var err error
+		segments, err = s.ssmlSegmenter.Segment(req.SSML, plan.limit, plan.unit)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		segments = segmentWithBudget(plan.strategy, req.Text, plan.limit, plan.unit)
+	}
 	segmentDuration := time.Since(segmentStart)
 
 	s.logger.Info().
 		Int("parts", len(segments)).
+		Bool("ssml", req.SSML != "").
+		Int("limit", plan.limit).
+		Str("budget", string(plan.unit)).
 		Dur("duration", segmentDuration).
 		Msg("Text segmented")
 
-	// 如果只有一个片段，直接合成
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("%w: no text left after segmentation", ErrInvalidSegmentOptions)
+	}
+
+	// 如果只有一个片段，直接合成（分隔符等策略会去除部分文本，使用分段后的文本）
 	if len(segments) == 1 {
+		if req.SSML == "" {
+			req.Text = segments[0]
+			if req.Lexicon != nil {
+				req = annotateSegment(req, req.Lexicon)
+			}
+		}
 		return s.client.SynthesizeSpeech(ctx, req)
 	}
 
@@ -125,196 +284,43 @@ func (s *LongTextTTSService) synthesizeLongText(ctx context.Context, req models.
 func (s *LongTextTTSService) processSegmentsConcurrently(ctx context.Context, req models.TTSRequest, segments []string, startTime time.Time, segmentDuration time.Duration) (*models.TTSResponse, error) {
 	segmentCount := len(segments)
 	jobID := fmt.Sprintf("job_%d", time.Now().UnixNano())
+	collectStart := time.Now()
 
-	// 创建通道用于任务提交和结果收集
-	submitDone := make(chan struct{})
-	resultChan := make(chan *SegmentResult, segmentCount) // 使用缓冲通道提高效率
-	errChan := make(chan error, 1)                        // 用于传递错误
-
-	// 使用 WaitGroup 来跟踪所有 goroutine 的完成
-	var wg sync.WaitGroup
-	wg.Add(2) // 一个用于提交任务，一个用于收集结果
-
-	// 初始化音频片段数组
-	audioSegments := make([][]byte, segmentCount)
-	var totalAudioSize int64
-	var mu sync.Mutex // 保护共享变量
-
-	// 启动任务提交 goroutine
-	go func() {
-		defer wg.Done()
-		defer close(submitDone)
-
-		for idx, segment := range segments {
-			// 检查 context
-			if ctx.Err() != nil {
-				s.logger.Warn().
-					Int("segment", idx).
-					Err(ctx.Err()).
-					Msg("Context cancelled during job submission")
-				errChan <- fmt.Errorf("context cancelled during job submission: %w", ctx.Err())
-				return
-			}
-
-			job := &SegmentJob{
-				ID:      fmt.Sprintf("%s_seg_%d", jobID, idx),
-				Index:   idx,
-				Context: ctx, // 传递请求上下文
-				Request: models.TTSRequest{
-					Text:   segment,
-					Voice:  req.Voice,
-					Rate:   req.Rate,
-					Pitch:  req.Pitch,
-					Style:  req.Style,
-					Format: req.Format, // 确保包含格式参数
-					SSML:   "",         // 分段时使用 Text，不使用 SSML
-				},
-			}
-
-			if err := s.workerPool.Submit(job); err != nil {
-				s.logger.Error().
-					Int("job", idx).
-					Err(err).
-					Msg("Failed to submit job")
-				errChan <- fmt.Errorf("failed to submit job %d: %w", idx, err)
-				return
-			}
-		}
-	}()
-
-	// 启动结果收集 goroutine
-	go func() {
-		defer wg.Done()
-		defer close(resultChan)
-
-		receivedCount := 0
-
-		// 使用结果收集循环，直到收到所有提交任务的结果或 context 取消
-		for receivedCount < segmentCount {
-			select {
-			case result, ok := <-s.workerPool.Results():
-				if !ok {
-					// 结果通道已关闭，但没有收到所有结果
-					errChan <- fmt.Errorf("result channel closed unexpectedly, received %d/%d results",
-						receivedCount, segmentCount)
-					return
-				}
-
-				// 将结果转发到结果通道
-				select {
-				case resultChan <- result:
-				case <-ctx.Done():
-					errChan <- fmt.Errorf("context cancelled during result forwarding: %w", ctx.Err())
-					return
-				}
-
-				receivedCount++
-
-			case <-ctx.Done():
-				errChan <- fmt.Errorf("context cancelled during result collection: %w", ctx.Err())
-				return
-			}
+	// 分段音频写入临时存储，避免整本书的音频同时驻留内存
+	store := s.newSegmentStore(segmentCount)
+	merged := false
+	defer func() {
+		// 合并成功后由返回的音频流负责清理
+		if !merged {
+			store.Close()
 		}
 	}()
 
-	// 主 goroutine 处理结果
-	collectStart := time.Now()
-	errorCount := 0
-	var firstError error
-	receivedCount := 0
-
-	// 等待任务提交完成
-	select {
-	case <-submitDone:
-		// 任务提交完成
-	case <-ctx.Done():
-		return nil, fmt.Errorf("context cancelled during job submission: %w", ctx.Err())
-	case err := <-errChan:
+	// 提交任务并收集结果，失败的分段会按配置重试
+	outcome, err := s.synthesizeSegments(ctx, splitRequests(req, segments), req.BestEffort, jobID, store)
+	if err != nil {
 		return nil, err
 	}
-
-	submitDuration := time.Since(startTime) - segmentDuration
-	s.logger.Info().
-		Int("jobs", segmentCount).
-		Dur("duration", submitDuration).
-		Msg("Submitted jobs")
-
-	// 处理结果
-	for receivedCount < segmentCount {
-		select {
-		case result, ok := <-resultChan:
-			if !ok {
-				// 结果通道已关闭，但没有收到所有结果
-				return nil, fmt.Errorf("result channel closed unexpectedly, received %d/%d results",
-					receivedCount, segmentCount)
-			}
-
-			if result.Error != nil {
-				errorCount++
-				if firstError == nil {
-					firstError = result.Error
-				}
-				s.logger.Error().
-					Int("segment", result.Index).
-					Err(result.Error).
-					Msg("Segment failed")
-			} else {
-				if result.Index < 0 || result.Index >= len(audioSegments) {
-					return nil, fmt.Errorf("invalid segment index: %d", result.Index)
-				}
-
-				// 使用互斥锁保护共享变量
-				mu.Lock()
-				audioSegments[result.Index] = result.AudioData
-				totalAudioSize += int64(len(result.AudioData))
-				mu.Unlock()
-
-				s.logger.Debug().
-					Int("segment", result.Index+1).
-					Int("total", segmentCount).
-					Int("bytes", len(result.AudioData)).
-					Dur("duration", result.Duration).
-					Msg("Received segment")
-			}
-			receivedCount++
-
-		case err := <-errChan:
-			return nil, err
-
-		case <-ctx.Done():
-			return nil, fmt.Errorf("context cancelled during result processing: %w", ctx.Err())
-		}
-	}
-
-	// 等待所有 goroutine 完成
-	wg.Wait()
+	metrics.GlobalMetrics.RecordSegmentCache(int64(outcome.cacheHits), int64(segmentCount-outcome.cacheHits))
 
 	collectDuration := time.Since(collectStart)
 	s.logger.Info().
 		Int("segments", segmentCount).
-		Int64("total_bytes", totalAudioSize).
+		Int("retries", outcome.retries).
+		Int("cache_hits", outcome.cacheHits).
+		Str("cache_hit_ratio", fmt.Sprintf("%.2f%%", float64(outcome.cacheHits)/float64(segmentCount)*100)).
+		Ints("substituted", outcome.substituted).
+		Int64("total_bytes", outcome.totalBytes).
 		Dur("duration", collectDuration).
 		Msg("Collected audio segments")
 
-	// 检查是否有错误
-	if errorCount > 0 {
-		return nil, fmt.Errorf("synthesis failed: %d/%d segments failed, first error: %w",
-			errorCount, segmentCount, firstError)
-	}
-
-	// 验证所有片段都已收集
-	for idx, segment := range audioSegments {
-		if segment == nil {
-			return nil, fmt.Errorf("missing audio segment at index %d", idx)
-		}
-	}
-
-	// 3. 合并音频
+	// 3. 合并音频（从存储读取，结果以流的形式返回）
 	mergeStart := time.Now()
-	merged, err := s.merger.Merge(audioSegments)
+	audioStream, err := s.merger.MergeStore(ctx, store)
 	if err != nil {
 		return nil, fmt.Errorf("failed to merge audio segments: %w", err)
 	}
+	merged = true
 
 	mergeDuration := time.Since(mergeStart)
 	totalDuration := time.Since(startTime)
@@ -322,9 +328,9 @@ func (s *LongTextTTSService) processSegmentsConcurrently(ctx context.Context, re
 	s.logger.Info().
 		Dur("total_duration", totalDuration).
 		Dur("segment_duration", segmentDuration).
-		Dur("submit_duration", submitDuration).
 		Dur("collect_duration", collectDuration).
 		Dur("merge_duration", mergeDuration).
+		Int64("merged_bytes", audioStream.Size()).
 		Msg("Long text synthesis completed")
 
 	// 4. 获取工作池统计
@@ -339,12 +345,27 @@ func (s *LongTextTTSService) processSegmentsConcurrently(ctx context.Context, re
 		Msg("Worker pool stats")
 
 	return &models.TTSResponse{
-		AudioContent: merged,
-		ContentType:  "audio/mpeg",
-		CacheHit:     false,
+		ContentType: "audio/mpeg",
+		CacheHit:    false,
+
+		SubstitutedSegments: outcome.substituted,
+
+		AudioStream: audioStream,
+		AudioSize:   audioStream.Size(),
 	}, nil
 }
 
+// newSegmentStore 创建分段音频存储
+// 优先使用临时目录；无法创建时回退到内存存储
+func (s *LongTextTTSService) newSegmentStore(count int) audio.SegmentStore {
+	store, err := audio.NewFileStore(s.spillDir, count, s.maxSpillBytes)
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("Failed to create segment spill directory, keeping segments in memory")
+		return audio.NewMemoryStore(count, s.maxSpillBytes)
+	}
+	return store
+}
+
 // GetStats 获取服务统计信息
 func (s *LongTextTTSService) GetStats() PoolStats {
 	return s.workerPool.Stats()
@@ -6,9 +6,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"io"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,7 +18,11 @@ import (
 	"github.com/rs/zerolog"
 	"tts/internal/config"
 	custom_errors "tts/internal/errors"
+	"tts/internal/lang"
 	"tts/internal/models"
+	"tts/internal/prosody"
+	"tts/internal/ssml"
+	"tts/internal/tts/limiter"
 	"tts/internal/utils"
 )
 
@@ -24,10 +30,10 @@ const (
 	userAgent      = "okhttp/4.5.0"
 	voicesEndpoint = "https://%s.tts.speech.microsoft.com/cognitiveservices/voices/list"
 	ttsEndpoint    = "https://%s.tts.speech.microsoft.com/cognitiveservices/v1"
-	ssmlTemplate   = `<speak version='1.0' xmlns='http://www.w3.org/2001/10/synthesis' xmlns:mstts="http://www.w3.org/2001/mstts" xml:lang='%s'>
-    <voice name='%s'>
-        <mstts:express-as style="%s" styledegree="1.0" role="default">
-            <prosody rate='%s%%' pitch='%s%%' volume="medium">
+	ssmlTemplate   = `<speak version='1.0' xmlns='http://www.w3.org/2001/10/synthesis' xmlns:mstts="http://www.w3.org/2001/mstts" xml:lang="%s">
+    <voice name="%s">
+        <mstts:express-as%s>
+            <prosody%s>
                 %s
             </prosody>
         </mstts:express-as>
@@ -51,18 +57,12 @@ type Client struct {
 	endpoint       map[string]interface{}
 	endpointMu     sync.RWMutex
 	endpointExpiry time.Time
-	ssmProcessor   *config.SSMLProcessor
+	limiter        *limiter.Limiter // 全局上游并发限流器
 	logger         zerolog.Logger
 }
 
 // NewClient 创建一个新的Microsoft TTS客户端
 func NewClient(cfg *config.Config, logger zerolog.Logger) *Client {
-	// 从Viper配置中创建SSML处理器
-	ssmProcessor, err := config.NewSSMLProcessor(&cfg.SSML)
-	if err != nil {
-		logger.Fatal().Err(err).Msg("创建SSML处理器失败")
-	}
-	
 	// 配置优化的 HTTP Transport
 	transport := &http.Transport{
 		// 连接池配置
@@ -87,6 +87,12 @@ func NewClient(cfg *config.Config, logger zerolog.Logger) *Client {
 		DisableCompression: true,
 	}
 	
+	// 全局上游并发限流：直接请求和长文本分段任务共享 max_concurrent 个槽位
+	upstreamLimiter := limiter.New(
+		int64(cfg.TTS.MaxConcurrent),
+		time.Duration(cfg.TTS.QueueTimeout)*time.Second,
+	)
+	
 	client := &Client{
 		defaultVoice:  cfg.TTS.DefaultVoice,
 		defaultRate:   cfg.TTS.DefaultRate,
@@ -99,7 +105,7 @@ func NewClient(cfg *config.Config, logger zerolog.Logger) *Client {
 		},
 		voicesCacheExpiry: time.Time{}, // 初始时缓存为空
 		endpointExpiry:    time.Time{}, // 初始时端点为空
-		ssmProcessor:      ssmProcessor,
+		limiter:           upstreamLimiter,
 		logger:            logger,
 	}
 
@@ -207,7 +213,14 @@ func (c *Client) ListVoices(ctx context.Context, locale string) ([]models.Voice,
 			Locale:          v.Locale,
 			LocaleName:      v.LocaleName,
 			StyleList:       v.StyleList,
+			RolePlayList:    v.RolePlayList,
 			SampleRateHertz: v.SampleRateHertz, // 直接使用字符串，无需转换
+
+			VoiceType:           v.VoiceType,
+			Status:              v.Status,
+			WordsPerMinute:      v.WordsPerMinute,
+			SecondaryLocaleList: v.SecondaryLocaleList,
+			VoiceTag:            voiceTags(v.VoiceTag),
 		}
 	}
 
@@ -232,7 +245,19 @@ func (c *Client) ListVoices(ctx context.Context, locale string) ([]models.Voice,
 }
 
 // SynthesizeSpeech 将文本转换为语音
+// 所有上游合成调用都受全局并发限流器约束，优先级从 context 中读取（见 limiter.WithPriority）
 func (c *Client) SynthesizeSpeech(ctx context.Context, req models.TTSRequest) (*models.TTSResponse, error) {
+	if err := c.limiter.Acquire(ctx, 1); err != nil {
+		c.logger.Warn().
+			Err(err).
+			Str("priority", limiter.PriorityFromContext(ctx).String()).
+			Int64("in_flight", c.limiter.InFlight()).
+			Int64("queued", c.limiter.Queued()).
+			Msg("等待上游并发槽位失败")
+		return nil, fmt.Errorf("等待上游并发槽位失败: %w", err)
+	}
+	defer c.limiter.Release(1)
+
 	resp, err := c.createTTSRequest(ctx, req)
 	if err != nil {
 		return nil, err
@@ -252,28 +277,11 @@ func (c *Client) SynthesizeSpeech(ctx context.Context, req models.TTSRequest) (*
 	}, nil
 }
 
-// preprocessText 移除文本中常见的Markdown标记。
-func preprocessText(text string) string {
-	// 使用NewReplacer比多次调用ReplaceAll更高效。
-	replacer := strings.NewReplacer(
-		"**", "",
-		"*", "",
-		"#", "",
-		">", "",
-		"~", "",
-	)
-	return replacer.Replace(text)
-}
-
-// createTTSRequest 创建并执行TTS请求，返回HTTP响应
-func (c *Client) createTTSRequest(ctx context.Context, req models.TTSRequest) (*http.Response, error) {
-	// 参数验证
-	if req.Text == "" && req.SSML == "" {
-		return nil, errors.New("文本或SSML不能为空")
-	}
-
-	if req.SSML == "" && len(req.Text) > c.maxTextLength {
-		return nil, fmt.Errorf("文本长度超过限制 (%d > %d)", len(req.Text), c.maxTextLength)
+// BuildSSML 返回发往上游的 SSML：SSML 请求原样返回，文本请求按语音、风格和韵律参数生成。
+// 文本中的 <、> 和 & 全部转义，按文字朗读，不能插入 SSML 元素
+func (c *Client) BuildSSML(req models.TTSRequest) (string, error) {
+	if req.SSML != "" {
+		return req.SSML, nil
 	}
 
 	// 使用默认值填充空白参数
@@ -297,24 +305,51 @@ func (c *Client) createTTSRequest(ctx context.Context, req models.TTSRequest) (*
 		pitch = c.defaultPitch
 	}
 
-	var ssml string
-	if req.SSML != "" {
-		ssml = req.SSML
-	} else {
-		// 提取语言
-		locale := "zh-CN" // 默认
-		parts := strings.Split(voice, "-")
-		if len(parts) >= 2 {
-			locale = parts[0] + "-" + parts[1]
-		}
+	// 提取语言（多语言文本由调用方按语言片段编译为 SSML）
+	locale := lang.LocaleOf(voice)
 
-		// 预处理文本以删除Markdown
-		processedText := preprocessText(req.Text)
-		// 对文本进行SSML转义，防止XML解析错误
-		escapedText := c.ssmProcessor.EscapeSSML(processedText)
+	// Markdown 由处理器按请求的处理方式预处理（见 textproc.RenderMarkdown）
+	// 与结构化片段相同，文本中的标记全部转义（见 ssml.EscapeText）
+	escapedText := ssml.EscapeText(req.Text)
 
-		// 准备SSML内容
-		ssml = fmt.Sprintf(ssmlTemplate, locale, voice, style, rate, pitch, escapedText)
+	// 韵律参数支持百分比、倍数、级别等写法，超出范围时截断
+	prosodyAttrs, err := prosody.Attrs(rate, pitch, req.Volume)
+	if err != nil {
+		return "", err
+	}
+	role, err := prosody.ParseRole(req.Role)
+	if err != nil {
+		return "", err
+	}
+	if err := prosody.CheckStyleDegree(req.StyleDegree); err != nil {
+		return "", err
+	}
+	expressAttrs := ` style="` + html.EscapeString(style) + `"`
+	if req.StyleDegree != 0 {
+		expressAttrs += ` styledegree="` + strconv.FormatFloat(req.StyleDegree, 'f', -1, 64) + `"`
+	}
+	if role != "" {
+		expressAttrs += ` role="` + role + `"`
+	}
+
+	// 准备SSML内容，语言和语音名称按属性值转义
+	return fmt.Sprintf(ssmlTemplate, ssml.EscapeAttr(locale), ssml.EscapeAttr(voice), expressAttrs, prosodyAttrs, escapedText), nil
+}
+
+// createTTSRequest 创建并执行TTS请求，返回HTTP响应
+func (c *Client) createTTSRequest(ctx context.Context, req models.TTSRequest) (*http.Response, error) {
+	// 参数验证
+	if req.Text == "" && req.SSML == "" {
+		return nil, errors.New("文本或SSML不能为空")
+	}
+
+	if req.SSML == "" && len(req.Text) > c.maxTextLength {
+		return nil, fmt.Errorf("文本长度超过限制 (%d > %d)", len(req.Text), c.maxTextLength)
+	}
+
+	document, err := c.BuildSSML(req)
+	if err != nil {
+		return nil, err
 	}
 
 	// 获取端点信息
@@ -322,10 +357,10 @@ func (c *Client) createTTSRequest(ctx context.Context, req models.TTSRequest) (*
 	if err != nil {
 		return nil, err
 	}
-
 	// 准备请求
 	url := fmt.Sprintf(ttsEndpoint, endpoint["r"])
-	reqBody := bytes.NewBufferString(ssml)
+	reqBody := bytes.NewBufferString(document)
+
 
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, reqBody)
 	if err != nil {
@@ -427,3 +462,18 @@ func isTemporaryError(err error) bool {
 
 	return false
 }
+
+// voiceTags 转换语音标签，忽略不是字符串数组的值
+func voiceTags(raw map[string]json.RawMessage) map[string][]string {
+	if len(raw) == 0 {
+		return nil
+	}
+	tags := make(map[string][]string, len(raw))
+	for name, value := range raw {
+		var list []string
+		if err := json.Unmarshal(value, &list); err == nil && len(list) > 0 {
+			tags[name] = list
+		}
+	}
+	return tags
+}
@@ -1,5 +1,7 @@
 package microsoft
 
+import "encoding/json"
+
 // MicrosoftVoice 表示Microsoft TTS服务中的一个语音
 type MicrosoftVoice struct {
 	Name            string   `json:"Name"`
@@ -10,9 +12,14 @@ type MicrosoftVoice struct {
 	Locale          string   `json:"Locale"`
 	LocaleName      string   `json:"LocaleName"`
 	StyleList       []string `json:"StyleList,omitempty"`
+	RolePlayList    []string `json:"RolePlayList,omitempty"`
 	SampleRateHertz string   `json:"SampleRateHertz"`
 	VoiceType       string   `json:"VoiceType"`
 	Status          string   `json:"Status"`
+
+	WordsPerMinute      string                     `json:"WordsPerMinute,omitempty"`
+	SecondaryLocaleList []string                   `json:"SecondaryLocaleList,omitempty"`
+	VoiceTag            map[string]json.RawMessage `json:"VoiceTag,omitempty"` // 值通常为字符串数组，其他类型的值被忽略
 }
 
 // SSMLRequest 表示发送给Microsoft TTS服务的SSML请求
@@ -0,0 +1,264 @@
+package tts
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	"tts/internal/models"
+)
+
+// Gender 对白说话人的性别（根据上下文推断）
+type Gender string
+
+const (
+	GenderUnknown Gender = ""
+	GenderMale    Gender = "male"
+	GenderFemale  Gender = "female"
+)
+
+// DialogueSpeaker 对白在时间清单中使用的角色名（未区分性别时）
+const DialogueSpeaker = "dialogue"
+
+// genderContext 推断性别时向前、向后查看的最大字符数
+const genderContext = 20
+
+// quotePairs 识别为对白的引号
+var quotePairs = map[rune]rune{'“': '”', '「': '」'}
+
+// QuotePart 文本中的一段旁白或引号内的对白
+type QuotePart struct {
+	Text     string
+	Dialogue bool
+	Gender   Gender // 仅对白有效，上下文中没有线索时为 GenderUnknown
+}
+
+// SplitDialogue 将文本切分为旁白和引号内的对白
+//
+// 只有像说话的引用才视为对白：由冒号引出、独占一段，或包含句读标点。
+// "所谓的“天才”" 这类用于强调的短引用保留在旁白中。未闭合的引号按旁白处理。
+func SplitDialogue(text string) []QuotePart {
+	runes := []rune(text)
+	var parts []QuotePart
+	start := 0 // 当前旁白的起始位置
+	for i := 0; i < len(runes); i++ {
+		closing, ok := quotePairs[runes[i]]
+		if !ok {
+			continue
+		}
+		end := matchQuote(runes, i, closing)
+		if end < 0 {
+			break
+		}
+		inner := strings.TrimSpace(string(runes[i+1 : end]))
+		if inner == "" || !isSpoken(runes[start:i], inner, runes[end+1:]) {
+			i = end
+			continue
+		}
+		parts = append(parts, QuotePart{Text: string(runes[start:i])})
+		parts = append(parts, QuotePart{Text: inner, Dialogue: true})
+		start = end + 1
+		i = end
+	}
+	parts = append(parts, QuotePart{Text: string(runes[start:])})
+
+	// 推断性别需要前后的旁白，之后再去除空白的旁白
+	for i := range parts {
+		if parts[i].Dialogue {
+			parts[i].Gender = genderCue(parts[i-1].Text, parts[i+1].Text)
+		}
+	}
+	result := parts[:0]
+	for _, part := range parts {
+		if !part.Dialogue {
+			part.Text = strings.TrimSpace(part.Text)
+			if part.Text == "" {
+				continue
+			}
+		}
+		result = append(result, part)
+	}
+	return result
+}
+
+// matchQuote 返回与 runes[open] 配对的闭引号位置，允许同种引号嵌套，找不到时返回 -1
+func matchQuote(runes []rune, open int, closing rune) int {
+	depth := 0
+	for j := open; j < len(runes); j++ {
+		switch runes[j] {
+		case runes[open]:
+			depth++
+		case closing:
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// isSpoken 判断引号内的内容是否为说话
+func isSpoken(before []rune, inner string, after []rune) bool {
+	prev := lastNonSpace(before)
+	if prev == '：' || prev == ':' {
+		return true
+	}
+	if strings.ContainsAny(inner, "。！？!?…，,~～—") {
+		return true
+	}
+	// 独占一段的引用
+	paragraphStart := prev == 0 || prev == '\n'
+	next := firstNonSpace(after)
+	return paragraphStart && (next == 0 || next == '\n')
+}
+
+// genderCue 从对白前后的旁白推断说话人性别
+//
+// 冒号引出的对白（他说：“……”）以前文为准，否则优先看后文（“……”她笑道）。
+// 只在同一段落、同一句内查找 "他"/"她"，"他们"/"她们" 不计入。
+func genderCue(before, after string) Gender {
+	if i := strings.LastIndex(before, "\n"); i >= 0 {
+		before = before[i+1:]
+	}
+	if i := strings.Index(after, "\n"); i >= 0 {
+		after = after[:i]
+	}
+	prevRunes := []rune(strings.TrimRightFunc(before, unicode.IsSpace))
+	if n := len(prevRunes); n > 0 && (prevRunes[n-1] == '：' || prevRunes[n-1] == ':') {
+		if g := lastPronoun(prevRunes); g != GenderUnknown {
+			return g
+		}
+	}
+	if g := firstPronoun([]rune(after)); g != GenderUnknown {
+		return g
+	}
+	return lastPronoun(prevRunes)
+}
+
+// firstPronoun 返回句子开头一段内第一个人称代词的性别
+func firstPronoun(runes []rune) Gender {
+	for i := 0; i < len(runes) && i < genderContext; i++ {
+		if isSentenceEnd(runes[i]) {
+			break
+		}
+		if g := pronounAt(runes, i); g != GenderUnknown {
+			return g
+		}
+	}
+	return GenderUnknown
+}
+
+// lastPronoun 返回句子末尾一段内最后一个人称代词的性别
+func lastPronoun(runes []rune) Gender {
+	for i := len(runes) - 1; i >= 0 && i >= len(runes)-genderContext; i-- {
+		if isSentenceEnd(runes[i]) {
+			break
+		}
+		if g := pronounAt(runes, i); g != GenderUnknown {
+			return g
+		}
+	}
+	return GenderUnknown
+}
+
+func pronounAt(runes []rune, i int) Gender {
+	if i+1 < len(runes) && runes[i+1] == '们' {
+		return GenderUnknown
+	}
+	switch runes[i] {
+	case '他':
+		return GenderMale
+	case '她':
+		return GenderFemale
+	}
+	return GenderUnknown
+}
+
+func isSentenceEnd(r rune) bool {
+	return r == '。' || r == '！' || r == '？' || r == '!' || r == '?'
+}
+
+func lastNonSpace(runes []rune) rune {
+	for i := len(runes) - 1; i >= 0; i-- {
+		if runes[i] == '\n' || !unicode.IsSpace(runes[i]) {
+			return runes[i]
+		}
+	}
+	return 0
+}
+
+func firstNonSpace(runes []rune) rune {
+	for _, r := range runes {
+		if r == '\n' || !unicode.IsSpace(r) {
+			return r
+		}
+	}
+	return 0
+}
+
+// quoteDialogueEnabled 判断请求是否启用了引号对白模式
+func quoteDialogueEnabled(req models.TTSRequest) bool {
+	return req.DialogueVoice != "" || req.DialogueMaleVoice != "" || req.DialogueFemaleVoice != ""
+}
+
+// assignDialogueVoices 为每段对白选择语音和时间清单中的角色名
+//
+// 同时指定男声和女声时启用性别交替：上下文能推断性别时使用对应的语音，
+// 否则假定与上一段对白的说话人交替；其他情况所有对白使用同一个语音。
+func assignDialogueVoices(req models.TTSRequest, parts []QuotePart) []scriptPart {
+	alternate := req.DialogueMaleVoice != "" && req.DialogueFemaleVoice != ""
+	single := req.DialogueVoice
+	if single == "" {
+		single = req.DialogueMaleVoice + req.DialogueFemaleVoice
+	}
+
+	result := make([]scriptPart, len(parts))
+	previous := GenderUnknown
+	for i, part := range parts {
+		line := ScriptLine{Speaker: NarratorName, Text: part.Text}
+		member := models.CastMember{}
+		if part.Dialogue {
+			line.Speaker = DialogueSpeaker
+			member.Voice = single
+			if alternate {
+				gender := part.Gender
+				if gender == GenderUnknown {
+					gender = GenderFemale
+					if previous == GenderFemale {
+						gender = GenderMale
+					}
+				}
+				previous = gender
+				line.Speaker = string(gender)
+				member.Voice = req.DialogueFemaleVoice
+				if gender == GenderMale {
+					member.Voice = req.DialogueMaleVoice
+				}
+			}
+		}
+		result[i] = scriptPart{line: line, req: lineRequest(req, member, part.Text)}
+	}
+	return result
+}
+
+// hasDialogue 判断切分结果中是否包含对白
+func hasDialogue(parts []QuotePart) bool {
+	for _, part := range parts {
+		if part.Dialogue {
+			return true
+		}
+	}
+	return false
+}
+
+// synthesizeQuotedDialogue 旁白使用主语音、引号内的对白使用对白语音，合并为一个音频
+func (s *LongTextTTSService) synthesizeQuotedDialogue(ctx context.Context, req models.TTSRequest, parts []QuotePart, plan segmentPlan, startTime time.Time) (*models.TTSResponse, error) {
+	s.logger.Info().
+		Int("parts", len(parts)).
+		Bool("alternate", req.DialogueMaleVoice != "" && req.DialogueFemaleVoice != "").
+		Msg("Quoted dialogue detected")
+
+	return s.synthesizeLines(ctx, req, assignDialogueVoices(req, parts), 0, plan, startTime)
+}
@@ -0,0 +1,143 @@
+package tts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"tts/internal/models"
+)
+
+// TestSplitDialogue 测试识别引号内的对白并推断说话人性别
+func TestSplitDialogue(t *testing.T) {
+	text := "夜深了。他推开门，低声问：“有人吗？”\n" +
+		"“我在这里。”她笑道。\n" +
+		"「快走吧。」\n" +
+		"所谓的“天才”不过如此。\n" +
+		"他们说：“走吧，来不及了。”"
+
+	want := []QuotePart{
+		{Text: "夜深了。他推开门，低声问："},
+		{Text: "有人吗？", Dialogue: true, Gender: GenderMale},
+		{Text: "我在这里。", Dialogue: true, Gender: GenderFemale},
+		{Text: "她笑道。"},
+		{Text: "快走吧。", Dialogue: true},
+		{Text: "所谓的“天才”不过如此。\n他们说："},
+		{Text: "走吧，来不及了。", Dialogue: true},
+	}
+
+	got := SplitDialogue(text)
+	if len(got) != len(want) {
+		t.Fatalf("SplitDialogue() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("第 %d 段 = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+// TestSplitDialogue_NoDialogue 测试未闭合的引号和强调用的引号不视为对白
+func TestSplitDialogue_NoDialogue(t *testing.T) {
+	for _, text := range []string{
+		"这是一段普通的旁白。",
+		"他称之为“奇迹”。",
+		"“未闭合的引号，后面都是旁白。",
+	} {
+		if parts := SplitDialogue(text); hasDialogue(parts) {
+			t.Errorf("SplitDialogue(%q) = %+v, 不应包含对白", text, parts)
+		}
+	}
+}
+
+// TestAssignDialogueVoices 测试对白语音选择与性别交替
+func TestAssignDialogueVoices(t *testing.T) {
+	parts := []QuotePart{
+		{Text: "旁白。"},
+		{Text: "甲。", Dialogue: true},
+		{Text: "乙。", Dialogue: true},
+		{Text: "丙。", Dialogue: true, Gender: GenderMale},
+		{Text: "丁。", Dialogue: true},
+	}
+
+	req := models.TTSRequest{Voice: "N", DialogueVoice: "D"}
+	for i, part := range assignDialogueVoices(req, parts) {
+		want := "D"
+		if i == 0 {
+			want = "N"
+		}
+		if part.req.Voice != want {
+			t.Errorf("单一对白语音: 第 %d 段语音 = %s, want %s", i, part.req.Voice, want)
+		}
+	}
+
+	req = models.TTSRequest{Voice: "N", DialogueMaleVoice: "M", DialogueFemaleVoice: "F"}
+	wantVoices := []string{"N", "F", "M", "M", "F"}
+	for i, part := range assignDialogueVoices(req, parts) {
+		if part.req.Voice != wantVoices[i] {
+			t.Errorf("性别交替: 第 %d 段语音 = %s, want %s", i, part.req.Voice, wantVoices[i])
+		}
+	}
+}
+
+// TestSynthesizeQuotedDialogue 测试旁白与对白分别合成后合并为一个音频
+func TestSynthesizeQuotedDialogue(t *testing.T) {
+	client := &mockScriptService{frames: map[string]int{"N": 4, "D": 6}}
+	service := NewLongTextTTSService(client, LongTextConfig{WorkerCount: 2, UseSmartSegment: true}, zerolog.Nop())
+	defer service.Close()
+
+	req := models.TTSRequest{
+		Text:          "他说：“你好。”然后离开了。",
+		Voice:         "N",
+		DialogueVoice: "D",
+	}
+	resp, err := service.SynthesizeSpeech(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SynthesizeSpeech() error = %v", err)
+	}
+	defer resp.AudioStream.Close()
+
+	if len(resp.Lines) != 3 {
+		t.Fatalf("时间清单 = %+v", resp.Lines)
+	}
+	if resp.Lines[1].Voice != "D" || resp.Lines[1].StartMs != 96 || resp.Lines[1].EndMs != 240 {
+		t.Errorf("对白时间 = %+v", resp.Lines[1])
+	}
+	if resp.AudioSize != 14*144 {
+		t.Errorf("合并音频 %d 字节, want %d", resp.AudioSize, 14*144)
+	}
+}
+
+// TestSynthesizeQuotedDialogue_Segmentation 测试旁白和对白按请求的分段策略切分
+func TestSynthesizeQuotedDialogue_Segmentation(t *testing.T) {
+	client := &mockScriptService{frames: map[string]int{"N": 4, "D": 6}}
+	service := NewLongTextTTSService(client, LongTextConfig{WorkerCount: 2, UseSmartSegment: true}, zerolog.Nop())
+	defer service.Close()
+
+	req := models.TTSRequest{
+		Text:             "第一段|第二段，他说：“你好|再见”",
+		Voice:            "N",
+		DialogueVoice:    "D",
+		SegmentStrategy:  "delimiter",
+		SegmentDelimiter: "|",
+	}
+	resp, err := service.SynthesizeSpeech(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SynthesizeSpeech() error = %v", err)
+	}
+	defer resp.AudioStream.Close()
+
+	texts := make(map[string]bool)
+	for _, r := range client.requests {
+		texts[r.Text] = true
+	}
+	for _, want := range []string{"第一段", "第二段，他说：", "你好", "再见"} {
+		if !texts[want] {
+			t.Errorf("缺少分段 %q, 收到 %d 个请求", want, len(client.requests))
+		}
+	}
+	if len(client.requests) != 4 || len(resp.Lines) != 2 {
+		t.Errorf("请求数 = %d, 行数 = %d, want 4 和 2", len(client.requests), len(resp.Lines))
+	}
+}
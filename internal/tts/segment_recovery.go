@@ -0,0 +1,346 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	custom_errors "tts/internal/errors"
+	"tts/internal/models"
+	"tts/internal/ssml"
+	"tts/internal/tts/audio"
+)
+
+const (
+	// maxRetryBackoff 分段重试退避时间的上限
+	maxRetryBackoff = 5 * time.Second
+	// silencePerRune 估算静音替代时长时每个字符对应的时长
+	silencePerRune = 200 * time.Millisecond
+	// minSilence 静音替代的最短时长
+	minSilence = 500 * time.Millisecond
+)
+
+// recoveryConfig 分段失败恢复配置
+type recoveryConfig struct {
+	SegmentRetries int           // 失败分段的最大重试轮数
+	RetryBackoff   time.Duration // 初始退避时间，每轮翻倍
+	FallbackVoice  string        // 上游拒绝分段时使用的备用语音
+	BestEffort     bool          // 默认启用尽力模式
+}
+
+// segmentOutcome 分段合成的汇总结果
+type segmentOutcome struct {
+	store       audio.SegmentStore // 已合成的分段音频
+	substituted []int              // 以静音替代的分段索引
+	totalBytes  int64
+	retries     int // 实际执行的重试轮数
+	cacheHits   int // 命中分段缓存的分段数
+}
+
+// synthesizeSegments 合成所有分段，失败的分段只重新提交自身
+//
+// requests 为每个分段的请求（文本或 SSML 即分段内容）。每轮只提交
+// 尚未成功的分段；被上游拒绝（400）的分段依次改用简化文本、备用语音
+// 重试。重试用尽后，尽力模式下以静音替代，否则返回错误。
+func (s *LongTextTTSService) synthesizeSegments(ctx context.Context, requests []models.TTSRequest, bestEffort bool, jobID string, store audio.SegmentStore) (*segmentOutcome, error) {
+	segmentCount := len(requests)
+	outcome := &segmentOutcome{store: store}
+
+	pending := make([]int, segmentCount)
+	for idx := range pending {
+		pending[idx] = idx
+	}
+	rejections := make([]int, segmentCount)
+	lastErrors := make([]error, segmentCount)
+
+	for attempt := 0; attempt <= s.recovery.SegmentRetries && len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			backoff := retryBackoff(s.recovery.RetryBackoff, attempt)
+			s.logger.Warn().
+				Int("attempt", attempt).
+				Int("failed_segments", len(pending)).
+				Dur("backoff", backoff).
+				Msg("Retrying failed segments")
+			if err := sleepContext(ctx, backoff); err != nil {
+				return nil, fmt.Errorf("context cancelled during segment retry: %w", err)
+			}
+			outcome.retries = attempt
+		}
+
+		jobs := make([]*SegmentJob, len(pending))
+		for i, idx := range pending {
+			jobs[i] = &SegmentJob{
+				ID:      fmt.Sprintf("%s_seg_%d_try_%d", jobID, idx, attempt),
+				Index:   idx,
+				Context: ctx, // 传递请求上下文
+				Request: segmentRequest(requests[idx], rejections[idx], s.recovery.FallbackVoice),
+			}
+		}
+
+		failed, err := s.runSegmentBatch(ctx, jobs, outcome)
+		if err != nil {
+			return nil, err
+		}
+
+		pending = pending[:0]
+		for idx, segErr := range failed {
+			lastErrors[idx] = segErr
+			if isUpstreamRejection(segErr) {
+				rejections[idx]++
+			}
+			pending = append(pending, idx)
+		}
+		sort.Ints(pending)
+	}
+
+	if len(pending) == 0 {
+		return outcome, nil
+	}
+
+	firstError := lastErrors[pending[0]]
+	if !(bestEffort || s.recovery.BestEffort) {
+		return nil, fmt.Errorf("synthesis failed: %d/%d segments failed, first error: %w",
+			len(pending), segmentCount, firstError)
+	}
+
+	// 尽力模式：以与成功分段相同编码参数的静音替代失败分段
+	reference, err := readFirstSegment(store)
+	if err != nil {
+		return nil, err
+	}
+	if reference == nil {
+		return nil, fmt.Errorf("synthesis failed: all %d segments failed, first error: %w", segmentCount, firstError)
+	}
+	for _, idx := range pending {
+		spoken := requests[idx].Text
+		if requests[idx].SSML != "" {
+			spoken = ssmlPlainText(requests[idx].SSML)
+		}
+		silence, err := audio.GenerateSilence(reference, estimateSpeechDuration(spoken))
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate silence for segment %d: %w", idx, err)
+		}
+		if err := store.Put(idx, silence); err != nil {
+			return nil, fmt.Errorf("failed to store segment %d: %w", idx, err)
+		}
+		outcome.substituted = append(outcome.substituted, idx)
+		s.logger.Warn().
+			Int("segment", idx).
+			Err(lastErrors[idx]).
+			Msg("Segment substituted with silence")
+	}
+	return outcome, nil
+}
+
+// runSegmentBatch 提交一轮任务并收集结果，返回本轮失败的分段及其错误
+func (s *LongTextTTSService) runSegmentBatch(ctx context.Context, jobs []*SegmentJob, outcome *segmentOutcome) (map[int]error, error) {
+	// 以批次方式提交，结果只会投递到本请求自己的通道
+	batch, err := s.workerPool.SubmitBatch(ctx, jobs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit jobs: %w", err)
+	}
+	// 无论成功与否都结束批次；提前返回时会清除尚未开始的任务
+	defer batch.Cancel()
+
+	failed := make(map[int]error)
+	for received := 0; received < len(jobs); received++ {
+		select {
+		case result := <-batch.Results():
+			if result.Index < 0 || result.Index >= outcome.store.Len() {
+				return nil, fmt.Errorf("invalid segment index: %d", result.Index)
+			}
+			if result.Error != nil {
+				failed[result.Index] = result.Error
+				s.logger.Error().
+					Int("segment", result.Index).
+					Err(result.Error).
+					Msg("Segment failed")
+				continue
+			}
+
+			// 写入存储后即可释放内存中的分段音频
+			if err := outcome.store.Put(result.Index, result.AudioData); err != nil {
+				return nil, fmt.Errorf("failed to store segment %d: %w", result.Index, err)
+			}
+			outcome.totalBytes += int64(len(result.AudioData))
+			if result.CacheHit {
+				outcome.cacheHits++
+			}
+
+			s.logger.Debug().
+				Int("segment", result.Index+1).
+				Int("total", outcome.store.Len()).
+				Int("bytes", len(result.AudioData)).
+				Bool("cache_hit", result.CacheHit).
+				Dur("duration", result.Duration).
+				Msg("Received segment")
+
+		case <-ctx.Done():
+			return nil, fmt.Errorf("context cancelled during result processing: %w", ctx.Err())
+		}
+	}
+	return failed, nil
+}
+
+// splitRequests 为每个分段生成请求，分段内容替换原请求的文本或 SSML
+func splitRequests(req models.TTSRequest, segments []string) []models.TTSRequest {
+	requests := make([]models.TTSRequest, len(segments))
+	for i, seg := range segments {
+		requests[i] = req
+		if req.SSML != "" {
+			requests[i].SSML = seg
+		} else {
+			requests[i].Text = seg
+		}
+	}
+	return requests
+}
+
+// segmentRequest 根据分段被上游拒绝的次数构造本轮请求
+// 文本分段按发音词典编译为 SSML；第一次拒绝后改用不带词典标注的简化文本，
+// 之后再改用备用语音（如已配置）；SSML 分段本身就是完整文档，语音由文档决定，原样重试
+func segmentRequest(req models.TTSRequest, rejections int, fallbackVoice string) models.TTSRequest {
+	if req.SSML != "" {
+		return models.TTSRequest{
+			SSML:   req.SSML,
+			Voice:  req.Voice,
+			Format: req.Format,
+		}
+	}
+
+	text := req.Text
+	segReq := models.TTSRequest{
+		Text:   text,
+		Voice:  req.Voice,
+		Rate:   req.Rate,
+		Pitch:  req.Pitch,
+		Style:  req.Style,
+		Format: req.Format, // 确保包含格式参数
+		SSML:   "",         // 分段时使用 Text，不使用 SSML
+
+		Volume:      req.Volume,
+		StyleDegree: req.StyleDegree,
+		Role:        req.Role,
+	}
+	if rejections >= 1 {
+		if simplified := simplifyText(text); simplified != "" {
+			segReq.Text = simplified
+		}
+	}
+	if rejections >= 2 && fallbackVoice != "" {
+		segReq.Voice = fallbackVoice
+		segReq.Style, segReq.StyleDegree, segReq.Role = "", 0, "" // 备用语音不一定支持原语音的风格和角色
+	}
+	if rejections == 0 && req.Lexicon != nil {
+		return annotateSegment(segReq, req.Lexicon)
+	}
+	return segReq
+}
+
+// annotateSegment 文本分段包含词典词条时编译为带 <phoneme>/<sub> 的 SSML
+func annotateSegment(req models.TTSRequest, lexicon models.Lexicon) models.TTSRequest {
+	spans, matches := lexicon.Apply([]models.Span{{Text: req.Text}})
+	if matches == 0 {
+		return req
+	}
+	annotated := req
+	annotated.Spans = spans
+	compiled, err := ssml.Compile(annotated)
+	if err != nil {
+		// 分段的韵律参数无法编译时按原文本合成
+		return req
+	}
+	return models.TTSRequest{SSML: compiled, Voice: req.Voice, Format: req.Format}
+}
+
+// simplifyText 去除可能导致上游拒绝的特殊字符，只保留文字、数字、空白和常用标点
+func simplifyText(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	lastSpace := false
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("，。！？、；：,.!?;:", r):
+			b.WriteRune(r)
+			lastSpace = false
+		case unicode.IsSpace(r):
+			if !lastSpace {
+				b.WriteByte(' ')
+				lastSpace = true
+			}
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// isUpstreamRejection 判断错误是否为上游拒绝请求内容（400）
+func isUpstreamRejection(err error) bool {
+	var upstreamErr *custom_errors.UpstreamError
+	return errors.As(err, &upstreamErr) && upstreamErr.StatusCode == http.StatusBadRequest
+}
+
+// retryBackoff 计算第 attempt 轮重试前的退避时间
+func retryBackoff(base time.Duration, attempt int) time.Duration {
+	if base <= 0 || attempt <= 0 {
+		return 0
+	}
+	backoff := base
+	for i := 1; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return backoff
+}
+
+// sleepContext 等待指定时间，context 取消时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// estimateSpeechDuration 按字符数粗略估算一段文本的朗读时长
+func estimateSpeechDuration(text string) time.Duration {
+	d := time.Duration(utf8.RuneCountInString(text)) * silencePerRune
+	if d < minSilence {
+		d = minSilence
+	}
+	return d
+}
+
+// readFirstSegment 读取存储中第一个已保存的分段，作为生成静音的编码参考
+func readFirstSegment(store audio.SegmentStore) ([]byte, error) {
+	for idx := 0; idx < store.Len(); idx++ {
+		if !store.Has(idx) {
+			continue
+		}
+		r, err := store.Open(idx)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read segment %d: %w", idx, err)
+		}
+		return data, nil
+	}
+	return nil, nil
+}
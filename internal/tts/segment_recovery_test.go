@@ -0,0 +1,137 @@
+package tts
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	custom_errors "tts/internal/errors"
+	"tts/internal/models"
+	"tts/internal/textproc"
+)
+
+// TestSimplifyText 测试简化文本只保留文字、数字和常用标点
+func TestSimplifyText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"普通文本不变", "你好，世界。", "你好，世界。"},
+		{"去除特殊符号", "价格<100>&{折扣}", "价格100折扣"},
+		{"合并空白", "hello \t\n  world", "hello world"},
+		{"去除表情", "好的👍！", "好的！"},
+		{"全部为符号", "###", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := simplifyText(tt.in); got != tt.want {
+				t.Errorf("simplifyText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSegmentRequest 测试按拒绝次数逐步降级的重试请求
+func TestSegmentRequest(t *testing.T) {
+	base := models.TTSRequest{
+		Voice:  "zh-CN-XiaoxiaoNeural",
+		Style:  "cheerful",
+		Format: "audio-24khz-48kbitrate-mono-mp3",
+	}
+	text := "测试<文本>"
+	base.Text = text
+
+	first := segmentRequest(base, 0, "zh-CN-YunxiNeural")
+	if first.Text != text || first.Voice != base.Voice || first.Format != base.Format {
+		t.Errorf("首次请求不应修改参数: %+v", first)
+	}
+
+	simplified := segmentRequest(base, 1, "zh-CN-YunxiNeural")
+	if simplified.Text != "测试文本" || simplified.Voice != base.Voice {
+		t.Errorf("第一次拒绝后应只简化文本: %+v", simplified)
+	}
+
+	fallback := segmentRequest(base, 2, "zh-CN-YunxiNeural")
+	if fallback.Text != "测试文本" || fallback.Voice != "zh-CN-YunxiNeural" || fallback.Style != "" {
+		t.Errorf("第二次拒绝后应改用备用语音: %+v", fallback)
+	}
+
+	noFallback := segmentRequest(base, 2, "")
+	if noFallback.Voice != base.Voice || noFallback.Style != base.Style {
+		t.Errorf("未配置备用语音时应保留原语音: %+v", noFallback)
+	}
+}
+
+// TestSegmentRequest_Lexicon 测试文本分段按发音词典编译为 SSML，被拒绝后不再标注
+func TestSegmentRequest_Lexicon(t *testing.T) {
+	dict, err := textproc.NewDictionary([]textproc.Entry{{Term: "重庆", Pinyin: "chong2 qing4"}})
+	if err != nil {
+		t.Fatalf("NewDictionary() error = %v", err)
+	}
+	base := models.TTSRequest{Text: "重庆火锅", Voice: "zh-CN-XiaoxiaoNeural", Rate: "0", Lexicon: dict}
+
+	first := segmentRequest(base, 0, "")
+	if first.Text != "" || !strings.Contains(first.SSML, `<phoneme alphabet="sapi" ph="chong 2 qing 4">重庆</phoneme>火锅`) {
+		t.Errorf("包含词条的分段应编译为 SSML: %+v", first)
+	}
+
+	plain := segmentRequest(models.TTSRequest{Text: "成都火锅", Voice: base.Voice, Lexicon: dict}, 0, "")
+	if plain.Text != "成都火锅" || plain.SSML != "" {
+		t.Errorf("不含词条的分段应保持文本: %+v", plain)
+	}
+
+	retried := segmentRequest(base, 1, "")
+	if retried.Text != "重庆火锅" || retried.SSML != "" {
+		t.Errorf("被拒绝后应按文本重试: %+v", retried)
+	}
+}
+
+// TestIsUpstreamRejection 测试上游拒绝的判断
+func TestIsUpstreamRejection(t *testing.T) {
+	rejected := fmt.Errorf("wrap: %w", custom_errors.NewUpstreamError(http.StatusBadRequest, "TTS API 错误", nil))
+	if !isUpstreamRejection(rejected) {
+		t.Error("400 应视为上游拒绝")
+	}
+	if isUpstreamRejection(custom_errors.NewUpstreamError(http.StatusTooManyRequests, "TTS API 错误", nil)) {
+		t.Error("429 不应视为上游拒绝")
+	}
+	if isUpstreamRejection(errors.New("network error")) {
+		t.Error("普通错误不应视为上游拒绝")
+	}
+}
+
+// TestRetryBackoff 测试指数退避及上限
+func TestRetryBackoff(t *testing.T) {
+	base := 500 * time.Millisecond
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 0},
+		{1, 500 * time.Millisecond},
+		{2, time.Second},
+		{3, 2 * time.Second},
+		{10, maxRetryBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := retryBackoff(base, tt.attempt); got != tt.want {
+			t.Errorf("retryBackoff(%v, %d) = %v, want %v", base, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+// TestEstimateSpeechDuration 测试静音时长估算
+func TestEstimateSpeechDuration(t *testing.T) {
+	if got := estimateSpeechDuration("好"); got != minSilence {
+		t.Errorf("短文本应使用最短静音时长, got %v", got)
+	}
+	if got := estimateSpeechDuration("一二三四五六七八九十"); got != 10*silencePerRune {
+		t.Errorf("estimateSpeechDuration() = %v, want %v", got, 10*silencePerRune)
+	}
+}
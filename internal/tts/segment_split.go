@@ -0,0 +1,234 @@
+package tts
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// splitLevels 超长文本的切分层级，从粗到细依次尝试：分句、空白、词元
+var splitLevels = []func(string) []string{
+	splitClauses,
+	splitWords,
+	splitTokens,
+}
+
+// splitHierarchically 将超长文本切分为不超过 maxLen 的片段
+// 只有当前层级切出的部分仍然超长时，才对该部分使用更细的层级
+func splitHierarchically(text string, maxLen, level int) []string {
+	if utf8.RuneCountInString(text) <= maxLen {
+		return []string{text}
+	}
+	if level >= len(splitLevels) {
+		return splitRunes(text, maxLen)
+	}
+
+	pieces := splitLevels[level](text)
+	if len(pieces) <= 1 {
+		return splitHierarchically(text, maxLen, level+1)
+	}
+
+	units := make([]string, 0, len(pieces))
+	for _, piece := range pieces {
+		units = append(units, splitHierarchically(piece, maxLen, level+1)...)
+	}
+	return balanceUnits(units, maxLen)
+}
+
+// splitClauses 在分句标点（，、：,: 以及破折号）之后切分，标点与空白归属前一部分
+func splitClauses(text string) []string {
+	runes := []rune(text)
+	var pieces []string
+	start := 0
+
+	for i := 0; i < len(runes); i++ {
+		if !isClauseBreak(runes, i) {
+			continue
+		}
+		end := i + 1
+		// 连续的破折号视为一个整体
+		for end < len(runes) && runes[end] == runes[i] && (runes[i] == '—' || runes[i] == '－') {
+			end++
+		}
+		end = skipClosers(runes, end)
+		for end < len(runes) && unicode.IsSpace(runes[end]) {
+			end++
+		}
+		pieces = append(pieces, string(runes[start:end]))
+		start = end
+		i = end - 1
+	}
+
+	if start < len(runes) {
+		pieces = append(pieces, string(runes[start:]))
+	}
+	return pieces
+}
+
+// isClauseBreak 判断位置 i 是否为分句点
+func isClauseBreak(runes []rune, i int) bool {
+	switch runes[i] {
+	case '，', '、', '：', '—', '－':
+		return true
+	case ',', ':':
+		// 数字中的千分位和时间（如 1,000、12:30）不切分
+		if i > 0 && i+1 < len(runes) && unicode.IsDigit(runes[i-1]) && unicode.IsDigit(runes[i+1]) {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// splitWords 在空白之后切分，空白归属前一个词
+func splitWords(text string) []string {
+	runes := []rune(text)
+	var pieces []string
+	start := 0
+
+	for i := 0; i < len(runes); i++ {
+		if !unicode.IsSpace(runes[i]) {
+			continue
+		}
+		end := i
+		for end < len(runes) && unicode.IsSpace(runes[end]) {
+			end++
+		}
+		if start < i {
+			pieces = append(pieces, string(runes[start:end]))
+			start = end
+		}
+		i = end - 1
+	}
+
+	if start < len(runes) {
+		pieces = append(pieces, string(runes[start:]))
+	}
+	return pieces
+}
+
+// splitTokens 切分为词元：连续的拉丁字母/数字（含数字中的小数点、千分位）为一个词元，
+// 其他字符（如汉字）各自为一个词元，保证不会从单词或数字中间切开
+func splitTokens(text string) []string {
+	runes := []rune(text)
+	var pieces []string
+
+	for i := 0; i < len(runes); {
+		end := i + 1
+		if isWordRune(runes[i]) {
+			for end < len(runes) {
+				if isWordRune(runes[end]) {
+					end++
+					continue
+				}
+				// 数字中间的 . 和 , 属于数字本身
+				if (runes[end] == '.' || runes[end] == ',') && end+1 < len(runes) &&
+					unicode.IsDigit(runes[end-1]) && unicode.IsDigit(runes[end+1]) {
+					end++
+					continue
+				}
+				break
+			}
+		}
+		pieces = append(pieces, string(runes[i:end]))
+		i = end
+	}
+	return pieces
+}
+
+// isWordRune 判断是否为构成拉丁单词或数字的字符
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isCJK(r)
+}
+
+// splitRunes 按字符数切分（最后的保底策略，仅用于单个超长词元）
+func splitRunes(text string, maxLen int) []string {
+	runes := []rune(text)
+	var pieces []string
+	for i := 0; i < len(runes); i += maxLen {
+		end := i + maxLen
+		if end > len(runes) {
+			end = len(runes)
+		}
+		pieces = append(pieces, string(runes[i:end]))
+	}
+	return pieces
+}
+
+// balanceUnits 将有序单元分组拼接为不超过 maxLen 的片段
+//
+// 先用贪心算法求出所需的最少片段数 k，再二分查找在 k 个片段内
+// 可行的最小容量，使各片段长度尽量均匀，避免很短的尾段。
+// 单元本身超过 maxLen 时单独成段。
+func balanceUnits(units []string, maxLen int) []string {
+	if len(units) == 0 {
+		return []string{}
+	}
+
+	lengths := make([]int, len(units))
+	total, longest := 0, 0
+	for i, unit := range units {
+		lengths[i] = utf8.RuneCountInString(unit)
+		total += lengths[i]
+		if lengths[i] > longest {
+			longest = lengths[i]
+		}
+	}
+	capacity := balancedCapacity(lengths, total, longest, maxLen)
+
+	var segments []string
+	var current strings.Builder
+	currentLen := 0
+	for i, unit := range units {
+		if currentLen > 0 && currentLen+lengths[i] > capacity {
+			segments = append(segments, current.String())
+			current.Reset()
+			currentLen = 0
+		}
+		current.WriteString(unit)
+		currentLen += lengths[i]
+	}
+	if current.Len() > 0 {
+		segments = append(segments, current.String())
+	}
+	return segments
+}
+
+// balancedCapacity 返回在最少分组数不变的前提下可行的最小分组容量
+func balancedCapacity(lengths []int, total, longest, maxLen int) int {
+	if longest > maxLen {
+		maxLen = longest
+	}
+
+	k := countGroups(lengths, maxLen)
+	lo := (total + k - 1) / k
+	if lo < longest {
+		lo = longest
+	}
+	hi := maxLen
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if countGroups(lengths, mid) <= k {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo
+}
+
+// countGroups 计算按容量 capacity 贪心分组所需的组数
+func countGroups(lengths []int, capacity int) int {
+	groups, current := 0, 0
+	for _, n := range lengths {
+		if current > 0 && current+n > capacity {
+			groups++
+			current = 0
+		}
+		current += n
+	}
+	if current > 0 {
+		groups++
+	}
+	return groups
+}
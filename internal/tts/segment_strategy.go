@@ -0,0 +1,244 @@
+package tts
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// 内置的分段策略名称
+const (
+	StrategySmart     = "smart"     // 按句子边界分段
+	StrategyFixed     = "fixed"     // 按固定长度分段
+	StrategyParagraph = "paragraph" // 按段落（空行）分段
+	StrategyLine      = "line"      // 按行分段
+	StrategyDelimiter = "delimiter" // 按请求指定的分隔符分段
+)
+
+// BudgetUnit 分段长度预算的计量单位
+type BudgetUnit string
+
+const (
+	// BudgetRunes 按字符数计算
+	BudgetRunes BudgetUnit = "runes"
+	// BudgetSSMLBytes 按 XML 转义后的字节数计算，与上游的请求大小限制一致
+	BudgetSSMLBytes BudgetUnit = "ssml_bytes"
+)
+
+// 单次请求可指定的分段长度范围
+const (
+	MinRequestSegmentLength = 20
+	MaxRequestSegmentLength = 5000
+)
+
+// ErrInvalidSegmentOptions 表示分段参数无效（未知策略、缺少分隔符、长度越界等）
+var ErrInvalidSegmentOptions = errors.New("invalid segmentation options")
+
+// StrategyParams 创建分段策略时的参数
+type StrategyParams struct {
+	Delimiter string // 分隔符（仅 delimiter 策略使用）
+}
+
+// StrategyFactory 分段策略的构造函数
+type StrategyFactory func(params StrategyParams) (SegmentationStrategy, error)
+
+var (
+	strategyMu sync.RWMutex
+	strategies = map[string]StrategyFactory{
+		StrategySmart: func(StrategyParams) (SegmentationStrategy, error) {
+			return NewSmartSegmenter(), nil
+		},
+		StrategyFixed: func(StrategyParams) (SegmentationStrategy, error) {
+			return NewFixedLengthSegmenter(), nil
+		},
+		StrategyParagraph: func(StrategyParams) (SegmentationStrategy, error) {
+			return NewParagraphSegmenter(), nil
+		},
+		StrategyLine: func(StrategyParams) (SegmentationStrategy, error) {
+			return NewLineSegmenter(), nil
+		},
+		StrategyDelimiter: func(params StrategyParams) (SegmentationStrategy, error) {
+			if params.Delimiter == "" {
+				return nil, fmt.Errorf("%w: delimiter strategy requires a delimiter", ErrInvalidSegmentOptions)
+			}
+			return NewDelimiterSegmenter(params.Delimiter), nil
+		},
+	}
+)
+
+// RegisterSegmentationStrategy 注册（或替换）一个分段策略
+func RegisterSegmentationStrategy(name string, factory StrategyFactory) {
+	strategyMu.Lock()
+	defer strategyMu.Unlock()
+	strategies[strings.ToLower(name)] = factory
+}
+
+// NewSegmentationStrategy 按名称创建分段策略
+func NewSegmentationStrategy(name string, params StrategyParams) (SegmentationStrategy, error) {
+	strategyMu.RLock()
+	factory, ok := strategies[strings.ToLower(name)]
+	strategyMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown strategy %q (available: %s)", ErrInvalidSegmentOptions, name, strings.Join(SegmentationStrategies(), ", "))
+	}
+	return factory(params)
+}
+
+// SegmentationStrategies 返回已注册的分段策略名称
+func SegmentationStrategies() []string {
+	strategyMu.RLock()
+	defer strategyMu.RUnlock()
+
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseBudgetUnit 解析长度预算单位，空字符串表示按字符数计算
+func ParseBudgetUnit(s string) (BudgetUnit, error) {
+	switch BudgetUnit(strings.ToLower(strings.TrimSpace(s))) {
+	case "", BudgetRunes:
+		return BudgetRunes, nil
+	case BudgetSSMLBytes:
+		return BudgetSSMLBytes, nil
+	default:
+		return "", fmt.Errorf("%w: unknown segment budget %q (supported: %s, %s)", ErrInvalidSegmentOptions, s, BudgetRunes, BudgetSSMLBytes)
+	}
+}
+
+// segmentWithBudget 使用指定策略分段，并保证每个片段满足长度预算
+//
+// 策略按字符数分段；预算为转义后字节数时，对超出预算的片段按
+// 实际的字节/字符比例缩小字符上限后重新分段，直到全部满足预算。
+func segmentWithBudget(strategy SegmentationStrategy, text string, limit int, unit BudgetUnit) []string {
+	segments := strategy.Segment(text, limit)
+	if unit != BudgetSSMLBytes {
+		return segments
+	}
+
+	result := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		result = append(result, fitSSMLBytes(strategy, seg, limit)...)
+	}
+	return result
+}
+
+// fitSSMLBytes 将片段切分到转义后字节数不超过 limit
+func fitSSMLBytes(strategy SegmentationStrategy, seg string, limit int) []string {
+	size := escapedSSMLLen(seg)
+	if size <= limit {
+		return []string{seg}
+	}
+
+	runes := utf8.RuneCountInString(seg)
+	runeLimit := runes * limit / size
+	if runeLimit >= runes {
+		runeLimit = runes - 1
+	}
+	if runeLimit < 1 {
+		runeLimit = 1
+	}
+
+	parts := strategy.Segment(seg, runeLimit)
+	if len(parts) <= 1 {
+		// 策略无法继续切分（如单个字符转义后仍超出预算），按字符切分兜底
+		parts = splitRunes(seg, runeLimit)
+		if len(parts) <= 1 {
+			return parts
+		}
+	}
+
+	var result []string
+	for _, part := range parts {
+		result = append(result, fitSSMLBytes(strategy, part, limit)...)
+	}
+	return result
+}
+
+// escapedSSMLLen 返回文本经 XML 转义后的字节数
+func escapedSSMLLen(text string) int {
+	var counter byteCounter
+	_ = xml.EscapeText(&counter, []byte(text))
+	return int(counter)
+}
+
+// byteCounter 只计数不保存内容的 io.Writer
+type byteCounter int
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// ParagraphSegmenter 按段落分段，过长的段落再按句子切分
+type ParagraphSegmenter struct {
+	fallback *SmartSegmenter
+}
+
+// NewParagraphSegmenter 创建段落分段器
+func NewParagraphSegmenter() *ParagraphSegmenter {
+	return &ParagraphSegmenter{fallback: NewSmartSegmenter()}
+}
+
+// Segment 每个段落（以空行分隔）单独成段
+func (p *ParagraphSegmenter) Segment(text string, maxLen int) []string {
+	return splitByDelimiter(text, "\n\n", maxLen, p.fallback)
+}
+
+// LineSegmenter 按行分段，过长的行再按句子切分
+type LineSegmenter struct {
+	fallback *SmartSegmenter
+}
+
+// NewLineSegmenter 创建按行分段器
+func NewLineSegmenter() *LineSegmenter {
+	return &LineSegmenter{fallback: NewSmartSegmenter()}
+}
+
+// Segment 每个非空行单独成段
+func (l *LineSegmenter) Segment(text string, maxLen int) []string {
+	return splitByDelimiter(strings.ReplaceAll(text, "\r\n", "\n"), "\n", maxLen, l.fallback)
+}
+
+// DelimiterSegmenter 按指定的分隔符分段，分隔符本身不会被朗读
+type DelimiterSegmenter struct {
+	delimiter string
+	fallback  *SmartSegmenter
+}
+
+// NewDelimiterSegmenter 创建分隔符分段器
+func NewDelimiterSegmenter(delimiter string) *DelimiterSegmenter {
+	return &DelimiterSegmenter{delimiter: delimiter, fallback: NewSmartSegmenter()}
+}
+
+// Segment 在每个分隔符处切分
+func (d *DelimiterSegmenter) Segment(text string, maxLen int) []string {
+	return splitByDelimiter(text, d.delimiter, maxLen, d.fallback)
+}
+
+// splitByDelimiter 按分隔符切分文本，去除空白部分，超长部分交给 fallback 继续切分
+func splitByDelimiter(text, delimiter string, maxLen int, fallback SegmentationStrategy) []string {
+	var segments []string
+	for _, part := range strings.Split(text, delimiter) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if utf8.RuneCountInString(part) > maxLen {
+			segments = append(segments, fallback.Segment(part, maxLen)...)
+			continue
+		}
+		segments = append(segments, part)
+	}
+	if segments == nil {
+		return []string{}
+	}
+	return segments
+}
@@ -0,0 +1,222 @@
+package tts
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"tts/internal/models"
+)
+
+// TestNewSegmentationStrategy 测试按名称创建分段策略
+func TestNewSegmentationStrategy(t *testing.T) {
+	for _, name := range []string{"smart", "fixed", "paragraph", "line", "SMART"} {
+		if _, err := NewSegmentationStrategy(name, StrategyParams{}); err != nil {
+			t.Errorf("NewSegmentationStrategy(%q) error = %v", name, err)
+		}
+	}
+
+	if _, err := NewSegmentationStrategy("unknown", StrategyParams{}); !errors.Is(err, ErrInvalidSegmentOptions) {
+		t.Errorf("未知策略期望 ErrInvalidSegmentOptions, got %v", err)
+	}
+	if _, err := NewSegmentationStrategy(StrategyDelimiter, StrategyParams{}); !errors.Is(err, ErrInvalidSegmentOptions) {
+		t.Errorf("缺少分隔符期望 ErrInvalidSegmentOptions, got %v", err)
+	}
+	if _, err := NewSegmentationStrategy(StrategyDelimiter, StrategyParams{Delimiter: "|"}); err != nil {
+		t.Errorf("NewSegmentationStrategy(delimiter) error = %v", err)
+	}
+}
+
+// TestRegisterSegmentationStrategy 测试注册自定义分段策略
+func TestRegisterSegmentationStrategy(t *testing.T) {
+	RegisterSegmentationStrategy("test-whole", func(StrategyParams) (SegmentationStrategy, error) {
+		return NewDelimiterSegmenter("\x00"), nil
+	})
+	defer func() {
+		strategyMu.Lock()
+		delete(strategies, "test-whole")
+		strategyMu.Unlock()
+	}()
+
+	strategy, err := NewSegmentationStrategy("test-whole", StrategyParams{})
+	if err != nil {
+		t.Fatalf("NewSegmentationStrategy() error = %v", err)
+	}
+	if got := strategy.Segment("一。二。", 100); !reflect.DeepEqual(got, []string{"一。二。"}) {
+		t.Errorf("Segment() = %q", got)
+	}
+
+	found := false
+	for _, name := range SegmentationStrategies() {
+		found = found || name == "test-whole"
+	}
+	if !found {
+		t.Error("SegmentationStrategies() 应包含已注册的策略")
+	}
+}
+
+// TestUnitSegmenters 测试段落、按行与分隔符分段
+func TestUnitSegmenters(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy SegmentationStrategy
+		text     string
+		want     []string
+	}{
+		{
+			"段落", NewParagraphSegmenter(),
+			"第一段第一行\n第一段第二行\n\n\n第二段",
+			[]string{"第一段第一行\n第一段第二行", "第二段"},
+		},
+		{
+			"按行", NewLineSegmenter(),
+			"第一行\r\n\r\n第二行\n  第三行  ",
+			[]string{"第一行", "第二行", "第三行"},
+		},
+		{
+			"分隔符被去除", NewDelimiterSegmenter("==="),
+			"第一章内容===第二章内容=== ",
+			[]string{"第一章内容", "第二章内容"},
+		},
+		{
+			"没有分隔符", NewDelimiterSegmenter("|"),
+			"完整的一句话。",
+			[]string{"完整的一句话。"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.strategy.Segment(tt.text, 100); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Segment() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestUnitSegmenters_OversizedUnit 测试超长的段落按句子继续切分
+func TestUnitSegmenters_OversizedUnit(t *testing.T) {
+	long := strings.Repeat("这是一个句子。", 10)
+	segments := NewParagraphSegmenter().Segment("短段落\n\n"+long, 30)
+
+	if segments[0] != "短段落" {
+		t.Errorf("第一个片段 = %q, want 短段落", segments[0])
+	}
+	if len(segments) < 3 {
+		t.Fatalf("超长段落应被切分, got %d segments", len(segments))
+	}
+	for i, seg := range segments {
+		if n := utf8.RuneCountInString(seg); n > 30 {
+			t.Errorf("片段 %d 长度 %d 超过 30", i, n)
+		}
+	}
+}
+
+// TestParseBudgetUnit 测试长度单位解析
+func TestParseBudgetUnit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    BudgetUnit
+		wantErr bool
+	}{
+		{"", BudgetRunes, false},
+		{"runes", BudgetRunes, false},
+		{"SSML_BYTES", BudgetSSMLBytes, false},
+		{"words", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBudgetUnit(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseBudgetUnit(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
+
+// TestEscapedSSMLLen 测试转义后字节数的计算
+func TestEscapedSSMLLen(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"abc", 3},
+		{"中文", 6},
+		{"a&b", 7},
+		{"<>", 8},
+		{`"'`, 10},
+	}
+	for _, tt := range tests {
+		if got := escapedSSMLLen(tt.in); got != tt.want {
+			t.Errorf("escapedSSMLLen(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestSegmentWithBudget_SSMLBytes 测试转义后字节预算：按字符数满足上限的片段在转义后仍会被继续切分
+func TestSegmentWithBudget_SSMLBytes(t *testing.T) {
+	text := strings.Repeat("A&B<C> ", 30) + "结束。"
+	limit := 100
+
+	byRunes := segmentWithBudget(NewSmartSegmenter(), text, limit, BudgetRunes)
+	overflow := false
+	for _, seg := range byRunes {
+		overflow = overflow || escapedSSMLLen(seg) > limit
+	}
+	if !overflow {
+		t.Fatal("测试文本按字符数分段后应有片段转义后超出预算")
+	}
+
+	byBytes := segmentWithBudget(NewSmartSegmenter(), text, limit, BudgetSSMLBytes)
+	for i, seg := range byBytes {
+		if n := escapedSSMLLen(seg); n > limit {
+			t.Errorf("片段 %d 转义后 %d 字节，超过 %d", i, n, limit)
+		}
+	}
+	if joined := strings.Join(byBytes, ""); strings.ReplaceAll(joined, " ", "") != strings.ReplaceAll(text, " ", "") {
+		t.Error("按字节预算分段后文本内容丢失")
+	}
+}
+
+// TestLongTextTTSService_PlanSegmentation 测试请求级分段参数
+func TestLongTextTTSService_PlanSegmentation(t *testing.T) {
+	service := &LongTextTTSService{
+		segmenter:     NewSmartSegmenter(),
+		maxSegmentLen: 500,
+		budgetUnit:    BudgetRunes,
+	}
+
+	plan, err := service.planSegmentation(models.TTSRequest{})
+	if err != nil {
+		t.Fatalf("planSegmentation() error = %v", err)
+	}
+	if plan.strategy != service.segmenter || plan.limit != 500 || plan.unit != BudgetRunes {
+		t.Errorf("未指定参数时应使用服务默认值, got %+v", plan)
+	}
+
+	plan, err = service.planSegmentation(models.TTSRequest{
+		SegmentStrategy:  "delimiter",
+		SegmentDelimiter: "#",
+		SegmentLength:    200,
+		SegmentBudget:    "ssml_bytes",
+	})
+	if err != nil {
+		t.Fatalf("planSegmentation() error = %v", err)
+	}
+	if _, ok := plan.strategy.(*DelimiterSegmenter); !ok || plan.limit != 200 || plan.unit != BudgetSSMLBytes {
+		t.Errorf("请求参数未生效, got %+v", plan)
+	}
+
+	invalid := []models.TTSRequest{
+		{SegmentStrategy: "chapter"},
+		{SegmentStrategy: "delimiter"},
+		{SegmentLength: 5},
+		{SegmentLength: MaxRequestSegmentLength + 1},
+		{SegmentBudget: "tokens"},
+	}
+	for _, req := range invalid {
+		if _, err := service.planSegmentation(req); !errors.Is(err, ErrInvalidSegmentOptions) {
+			t.Errorf("planSegmentation(%+v) 期望 ErrInvalidSegmentOptions, got %v", req, err)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package tts
 
 import (
-	"regexp"
 	"strings"
 	"unicode/utf8"
 )
@@ -13,18 +12,17 @@ type SegmentationStrategy interface {
 
 // SmartSegmenter 智能分段器，基于句法边界进行分段
 type SmartSegmenter struct {
-	// 中英文句子结束标点的正则表达式
-	sentenceRegex *regexp.Regexp
+	// 缩写表，用于判断英文句点是否结束句子
+	abbreviations map[string]abbrevKind
 	// 最小段落长度（避免过小的片段）
 	minSegmentLen int
 }
 
 // NewSmartSegmenter 创建智能分段器
-func NewSmartSegmenter() *SmartSegmenter {
-	// 匹配中英文句子结束标点：。！？；.!?;
-	// 同时处理省略号等特殊情况
+// languages 指定使用哪些语言的缩写表（如 "en"、"de"），不指定时使用全部
+func NewSmartSegmenter(languages ...string) *SmartSegmenter {
 	return &SmartSegmenter{
-		sentenceRegex: regexp.MustCompile(`([。！？；\.!\?;]+)`),
+		abbreviations: buildAbbreviations(languages),
 		minSegmentLen: 50, // 最小 50 字符
 	}
 }
@@ -66,118 +64,64 @@ func (s *SmartSegmenter) Segment(text string, maxLen int) []string {
 		return []string{text}
 	}
 
-	return segments
+	// 4. 将过短的片段（如章节标题）并入相邻片段
+	return s.mergeShortSegments(segments, maxLen)
 }
 
-// splitBySentence 按句子分割文本
-func (s *SmartSegmenter) splitBySentence(text string) []string {
-	if text == "" {
-		return []string{}
-	}
-
-	// 使用正则表达式查找所有句子结束标点的位置
-	matches := s.sentenceRegex.FindAllStringIndex(text, -1)
-	
-	if len(matches) == 0 {
-		// 没有找到句子结束符，返回整个文本
-		return []string{text}
+// mergeShortSegments 将短于 minSegmentLen 的片段并入相邻片段（合并后不超过 maxLen）
+func (s *SmartSegmenter) mergeShortSegments(segments []string, maxLen int) []string {
+	if len(segments) < 2 || s.minSegmentLen <= 0 {
+		return segments
 	}
 
-	var sentences []string
-	lastEnd := 0
-	
-	for _, match := range matches {
-		// match[0] 是标点开始位置，match[1] 是标点结束位置
-		sentenceEnd := match[1]
-		
-		// 提取句子（包含结束标点）
-		sentence := text[lastEnd:sentenceEnd]
-		sentence = strings.TrimSpace(sentence)
-		
-		if sentence != "" {
-			sentences = append(sentences, sentence)
-		}
-		
-		lastEnd = sentenceEnd
-	}
-	
-	// 处理最后一个句子之后的文本（如果有）
-	if lastEnd < len(text) {
-		remaining := strings.TrimSpace(text[lastEnd:])
-		if remaining != "" {
-			sentences = append(sentences, remaining)
+	merged := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		if len(merged) > 0 {
+			prev := merged[len(merged)-1]
+			prevLen := utf8.RuneCountInString(prev)
+			segLen := utf8.RuneCountInString(seg)
+			if (prevLen < s.minSegmentLen || segLen < s.minSegmentLen) && prevLen+1+segLen <= maxLen {
+				merged[len(merged)-1] = prev + "\n" + seg
+				continue
+			}
 		}
+		merged = append(merged, seg)
 	}
-	
-	return sentences
+	return merged
 }
 
-// mergeSentences 贪心合并句子，最大化利用长度限制
+// mergeSentences 合并句子为片段
+// 超长句子先按层级切分为不超过 maxLen 的单元，再整体做均衡分组：
+// 在片段数量最少的前提下，使最长片段尽可能短，避免出现很短的尾段
 func (s *SmartSegmenter) mergeSentences(sentences []string, maxLen int) []string {
 	if len(sentences) == 0 {
 		return []string{}
 	}
 
-	var segments []string
-	var currentSegment strings.Builder
-	currentLen := 0
-
+	units := make([]string, 0, len(sentences))
 	for _, sentence := range sentences {
-		sentenceLen := utf8.RuneCountInString(sentence)
-		
-		// 如果单个句子超过最大长度，需要进一步切割
-		if sentenceLen > maxLen {
-			// 先保存当前累积的片段
-			if currentSegment.Len() > 0 {
-				segments = append(segments, currentSegment.String())
-				currentSegment.Reset()
-				currentLen = 0
-			}
-			
-			// 对超长句子进行字符级切割
-			splitLong := s.splitLongSentence(sentence, maxLen)
-			segments = append(segments, splitLong...)
+		if utf8.RuneCountInString(sentence) > maxLen {
+			units = append(units, s.splitLongSentence(sentence, maxLen)...)
 			continue
 		}
-		
-		// 检查添加这个句子后是否超过限制
-		testLen := currentLen + sentenceLen
-		
-		if currentLen > 0 && testLen > maxLen {
-			// 添加会超过限制，保存当前片段
-			segments = append(segments, currentSegment.String())
-			currentSegment.Reset()
-			currentSegment.WriteString(sentence)
-			currentLen = sentenceLen
-		} else {
-			// 可以添加到当前片段
-			currentSegment.WriteString(sentence)
-			currentLen = testLen
-		}
+		units = append(units, sentence)
 	}
 
-	// 保存最后一个片段
-	if currentSegment.Len() > 0 {
-		segments = append(segments, currentSegment.String())
+	// 句子保留了句后空白以便还原原文，片段首尾的空白没有意义
+	segments := balanceUnits(units, maxLen)
+	result := segments[:0]
+	for _, seg := range segments {
+		if seg = strings.TrimSpace(seg); seg != "" {
+			result = append(result, seg)
+		}
 	}
-
-	return segments
+	return result
 }
 
-// splitLongSentence 对超长句子进行字符级切割（保底策略）
+// splitLongSentence 切分超长句子（保底策略）
+// 依次尝试在分句标点、空白、词元边界处切分，只有单个词元仍然超长时才按字符切分
 func (s *SmartSegmenter) splitLongSentence(sentence string, maxLen int) []string {
-	var segments []string
-	runes := []rune(sentence)
-	
-	for i := 0; i < len(runes); i += maxLen {
-		end := i + maxLen
-		if end > len(runes) {
-			end = len(runes)
-		}
-		segments = append(segments, string(runes[i:end]))
-	}
-	
-	return segments
+	return splitHierarchically(sentence, maxLen, 0)
 }
 
 // FixedLengthSegmenter 固定长度分段器（简单实现，用于对比）
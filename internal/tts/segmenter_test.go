@@ -1,6 +1,8 @@
 package tts
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 	"unicode/utf8"
 )
@@ -145,6 +147,388 @@ func TestSmartSegmenter_SplitBySentence(t *testing.T) {
 	}
 }
 
+// TestSmartSegmenter_SentenceBoundaries 测试句子边界识别
+func TestSmartSegmenter_SentenceBoundaries(t *testing.T) {
+	segmenter := NewSmartSegmenter()
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "中文句号",
+			text: "第一句。第二句。",
+			want: []string{"第一句。", "第二句。"},
+		},
+		{
+			name: "小数不切分",
+			text: "圆周率约为3.14。它是无理数。",
+			want: []string{"圆周率约为3.14。", "它是无理数。"},
+		},
+		{
+			name: "英文小数",
+			text: "Pi is about 3.14159. It never ends.",
+			want: []string{"Pi is about 3.14159.", "It never ends."},
+		},
+		{
+			name: "版本号",
+			text: "请升级到 v1.2.3 版本。然后重启。",
+			want: []string{"请升级到 v1.2.3 版本。", "然后重启。"},
+		},
+		{
+			name: "英文称谓缩写",
+			text: "Mr. Smith met Dr. Brown. They talked.",
+			want: []string{"Mr. Smith met Dr. Brown.", "They talked."},
+		},
+		{
+			name: "e.g. 与 i.e.",
+			text: "Use a tool, e.g. a hammer. It works, i.e. it helps.",
+			want: []string{"Use a tool, e.g. a hammer.", "It works, i.e. it helps."},
+		},
+		{
+			name: "句末的 etc.",
+			text: "Bring pens, paper, etc. We start at noon.",
+			want: []string{"Bring pens, paper, etc.", "We start at noon."},
+		},
+		{
+			name: "句中的 etc.",
+			text: "Pens, paper, etc. are provided. Thanks.",
+			want: []string{"Pens, paper, etc. are provided.", "Thanks."},
+		},
+		{
+			name: "姓名首字母",
+			text: "J. K. Rowling wrote it. Read it.",
+			want: []string{"J. K. Rowling wrote it.", "Read it."},
+		},
+		{
+			name: "点分缩写",
+			text: "He moved to the U.S. Then he worked.",
+			want: []string{"He moved to the U.S.", "Then he worked."},
+		},
+		{
+			name: "网址",
+			text: "访问 https://example.com/a.html 获取详情。谢谢！",
+			want: []string{"访问 https://example.com/a.html 获取详情。", "谢谢！"},
+		},
+		{
+			name: "邮箱",
+			text: "Mail me at a.b@example.org. Bye.",
+			want: []string{"Mail me at a.b@example.org.", "Bye."},
+		},
+		{
+			name: "中文右引号归属句子",
+			text: "他说：“你好。”我点点头。",
+			want: []string{"他说：“你好。”", "我点点头。"},
+		},
+		{
+			name: "直角引号归属句子",
+			text: "「走吧！」她说。",
+			want: []string{"「走吧！」", "她说。"},
+		},
+		{
+			name: "右括号归属句子",
+			text: "这是注释（见附录。）正文继续。",
+			want: []string{"这是注释（见附录。）", "正文继续。"},
+		},
+		{
+			name: "英文引号归属句子",
+			text: `She said "Go now." He left.`,
+			want: []string{`She said "Go now."`, "He left."},
+		},
+		{
+			name: "连续结束标点",
+			text: "真的吗？！太好了！！！",
+			want: []string{"真的吗？！", "太好了！！！"},
+		},
+		{
+			name: "句末中文省略号",
+			text: "他走了……”她低声说。",
+			want: []string{"他走了……”", "她低声说。"},
+		},
+		{
+			name: "句中中文省略号",
+			text: "我……我不知道。",
+			want: []string{"我……我不知道。"},
+		},
+		{
+			name: "省略号后接句号",
+			text: "就这样吧……。明天见。",
+			want: []string{"就这样吧……。", "明天见。"},
+		},
+		{
+			name: "英文省略号句中",
+			text: "Wait... let me think. Okay.",
+			want: []string{"Wait... let me think.", "Okay."},
+		},
+		{
+			name: "英文省略号句末",
+			text: "It was over... Nobody spoke.",
+			want: []string{"It was over...", "Nobody spoke."},
+		},
+		{
+			name: "破折号不切分",
+			text: "他来了——带着礼物。大家很高兴。",
+			want: []string{"他来了——带着礼物。", "大家很高兴。"},
+		},
+		{
+			name: "中英文混排",
+			text: "我用 Python 3.12 写代码. 然后运行测试。OK! 通过了。",
+			want: []string{"我用 Python 3.12 写代码.", "然后运行测试。", "OK!", "通过了。"},
+		},
+		{
+			name: "行首编号",
+			text: "1. Introduction to the topic.\n2. Details follow.",
+			want: []string{"1. Introduction to the topic.", "2. Details follow."},
+		},
+		{
+			name: "年份后的句点",
+			text: "I was born in 1990. Then I moved.",
+			want: []string{"I was born in 1990.", "Then I moved."},
+		},
+		{
+			name: "换行结束句子",
+			text: "第一章\n正文开始。",
+			want: []string{"第一章", "正文开始。"},
+		},
+		{
+			name: "分号",
+			text: "前半句；后半句。",
+			want: []string{"前半句；", "后半句。"},
+		},
+		{
+			name: "与缩写同形的普通单词",
+			text: "I said no. Then I left. It is John's. Call me. Okay.",
+			want: []string{"I said no.", "Then I left.", "It is John's.", "Call me.", "Okay."},
+		},
+		{
+			name: "编号缩写",
+			text: "See No. 5 and p. 12 for details.",
+			want: []string{"See No. 5 and p. 12 for details."},
+		},
+		{
+			name: "德语缩写",
+			text: "Das ist z.B. ein Test. Gut.",
+			want: []string{"Das ist z.B. ein Test.", "Gut."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sentences := segmenter.splitBySentence(tt.text)
+
+			// 句子拼接后必须与原文完全一致
+			if got := strings.Join(sentences, ""); got != tt.text {
+				t.Errorf("拼接结果 %q 与原文 %q 不一致", got, tt.text)
+			}
+
+			got := make([]string, len(sentences))
+			for i, sentence := range sentences {
+				got[i] = strings.TrimSpace(sentence)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitBySentence() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSmartSegmenter_AbbreviationLanguages 测试按语言选择缩写表
+func TestSmartSegmenter_AbbreviationLanguages(t *testing.T) {
+	text := "Das ist z.B. ein Test."
+
+	if got := NewSmartSegmenter("de").splitBySentence(text); len(got) != 1 {
+		t.Errorf("使用德语缩写表时不应切分, got %q", got)
+	}
+	if got := NewSmartSegmenter("en").splitBySentence("Mr. Smith left."); len(got) != 1 {
+		t.Errorf("使用英语缩写表时不应切分, got %q", got)
+	}
+	if got := NewSmartSegmenter("de").splitBySentence("Usw. Ende."); len(got) != 2 {
+		t.Errorf("句末缩写后接大写字母时应切分, got %q", got)
+	}
+}
+
+// TestSmartSegmenter_KeepsSpacesBetweenSentences 测试英文句子合并时保留空格
+func TestSmartSegmenter_KeepsSpacesBetweenSentences(t *testing.T) {
+	segmenter := NewSmartSegmenter()
+	text := "First sentence here. Second sentence here. Third sentence here."
+
+	segments := segmenter.Segment(text, 45)
+	if len(segments) != 2 {
+		t.Fatalf("Segment() got %d segments, want 2: %q", len(segments), segments)
+	}
+	if segments[1] != "Second sentence here. Third sentence here." {
+		t.Errorf("句子之间的空格丢失: %q", segments[1])
+	}
+}
+
+// TestSmartSegmenter_MinSegmentLen 测试过短的片段并入相邻片段
+func TestSmartSegmenter_MinSegmentLen(t *testing.T) {
+	segmenter := &SmartSegmenter{abbreviations: buildAbbreviations(nil), minSegmentLen: 5}
+	text := "第一章\n\n这是正文的第一句话。这是正文的第二句话。"
+
+	segments := segmenter.Segment(text, 15)
+	if len(segments) != 2 {
+		t.Fatalf("Segment() got %d segments, want 2: %q", len(segments), segments)
+	}
+	if segments[0] != "第一章\n这是正文的第一句话。" {
+		t.Errorf("标题应并入下一片段, got %q", segments[0])
+	}
+	for i, seg := range segments {
+		if n := utf8.RuneCountInString(seg); n > 15 {
+			t.Errorf("Segment %d length %d exceeds maxLen 15", i, n)
+		}
+	}
+}
+
+// TestSmartSegmenter_SplitLongSentence 测试超长句子的层级切分
+func TestSmartSegmenter_SplitLongSentence(t *testing.T) {
+	segmenter := NewSmartSegmenter()
+
+	tests := []struct {
+		name     string
+		sentence string
+		maxLen   int
+		want     []string
+	}{
+		{
+			name:     "优先在分句标点处切分",
+			sentence: "清晨的阳光洒在湖面上，微风轻轻吹过，远处传来鸟儿的歌声，孩子们在岸边奔跑嬉戏",
+			maxLen:   20,
+			want:     []string{"清晨的阳光洒在湖面上，微风轻轻吹过，", "远处传来鸟儿的歌声，孩子们在岸边奔跑嬉戏"},
+		},
+		{
+			name:     "顿号与冒号",
+			sentence: "需要准备：苹果、香蕉、橘子、葡萄、西瓜、草莓",
+			maxLen:   12,
+			want:     []string{"需要准备：苹果、香蕉、", "橘子、葡萄、西瓜、草莓"},
+		},
+		{
+			name:     "破折号作为分句点",
+			sentence: "他终于明白了一切——原来那封信是母亲写的",
+			maxLen:   12,
+			want:     []string{"他终于明白了一切——", "原来那封信是母亲写的"},
+		},
+		{
+			name:     "无标点时在空白处切分",
+			sentence: "the quick brown fox jumps over the lazy dog",
+			maxLen:   25,
+			want:     []string{"the quick brown fox ", "jumps over the lazy dog"},
+		},
+		{
+			name:     "千分位数字不切分",
+			sentence: "共计1,234,567元和890,123元",
+			maxLen:   12,
+			want:     []string{"共计1,234,567", "元和890,123元"},
+		},
+		{
+			name:     "不从单词和数字中间切开",
+			sentence: "版本号为3.14159的Python解释器",
+			maxLen:   12,
+			want:     []string{"版本号为3.14159", "的Python解释器"},
+		},
+		{
+			name:     "均衡长度避免短尾段",
+			sentence: "一二三四五六七八九十一二三四五六七八九十一二三四五",
+			maxLen:   10,
+			want:     []string{"一二三四五六七八九", "十一二三四五六七八", "九十一二三四五"},
+		},
+		{
+			name:     "单个超长词元按字符切分",
+			sentence: "abcdefghijklmnopqrstuvwxyz",
+			maxLen:   10,
+			want:     []string{"abcdefghij", "klmnopqrst", "uvwxyz"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := segmenter.splitLongSentence(tt.sentence, tt.maxLen)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitLongSentence() = %q, want %q", got, tt.want)
+			}
+			if strings.Join(got, "") != tt.sentence {
+				t.Errorf("拼接结果与原句不一致: %q", got)
+			}
+			for i, seg := range got {
+				if n := utf8.RuneCountInString(seg); n > tt.maxLen {
+					t.Errorf("Segment %d length %d exceeds maxLen %d", i, n, tt.maxLen)
+				}
+			}
+		})
+	}
+}
+
+// TestBalanceUnits 测试均衡分组
+func TestBalanceUnits(t *testing.T) {
+	tests := []struct {
+		name   string
+		units  []string
+		maxLen int
+		want   []string
+	}{
+		{
+			name:   "贪心会产生短尾段",
+			units:  []string{"aaaa", "bbbb", "cccc", "dd"},
+			maxLen: 10,
+			want:   []string{"aaaabbbb", "ccccdd"},
+		},
+		{
+			name:   "全部可以放入一段",
+			units:  []string{"ab", "cd"},
+			maxLen: 10,
+			want:   []string{"abcd"},
+		},
+		{
+			name:   "超长单元单独成段",
+			units:  []string{"abcdefghijkl", "mn"},
+			maxLen: 10,
+			want:   []string{"abcdefghijkl", "mn"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := balanceUnits(tt.units, tt.maxLen); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("balanceUnits() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSmartSegmenter_RunOnText 测试没有句号的长段落
+func TestSmartSegmenter_RunOnText(t *testing.T) {
+	segmenter := NewSmartSegmenter()
+	text := strings.Repeat("他沿着小路一直往前走，", 30) + "终于看到了村庄"
+	maxLen := 100
+
+	segments := segmenter.Segment(text, maxLen)
+	if got := strings.Join(segments, ""); got != text {
+		t.Fatalf("拼接结果与原文不一致")
+	}
+
+	shortest, longest := maxLen, 0
+	for i, seg := range segments {
+		n := utf8.RuneCountInString(seg)
+		if n > maxLen {
+			t.Errorf("Segment %d length %d exceeds maxLen %d", i, n, maxLen)
+		}
+		if i < len(segments)-1 && !strings.HasSuffix(seg, "，") {
+			t.Errorf("Segment %d 没有在分句标点处切分: %q", i, seg)
+		}
+		if n < shortest {
+			shortest = n
+		}
+		if n > longest {
+			longest = n
+		}
+	}
+	// 均衡后不应出现很短的尾段（单纯贪心切分时尾段只有 40 字）
+	if longest-shortest > 20 {
+		t.Errorf("片段长度不均衡: shortest=%d longest=%d", shortest, longest)
+	}
+}
+
 func TestFixedLengthSegmenter_Segment(t *testing.T) {
 	segmenter := NewFixedLengthSegmenter()
 
@@ -0,0 +1,278 @@
+package tts
+
+import (
+	"strings"
+	"unicode"
+)
+
+// abbrevKind 缩写的类别，决定其后的句点能否作为句子结尾
+type abbrevKind int
+
+const (
+	// abbrevInternal 只出现在句中的缩写（称谓、e.g. 等），其后的句点从不结束句子
+	abbrevInternal abbrevKind = iota + 1
+	// abbrevTerminal 可能出现在句末的缩写（etc. 等），仅当后文以大写字母开头时结束句子
+	abbrevTerminal
+)
+
+// abbreviationsByLanguage 各语言的常见缩写（小写，不含末尾句点）
+var abbreviationsByLanguage = map[string]map[string]abbrevKind{
+	"en": {
+		"mr": abbrevInternal, "mrs": abbrevInternal, "ms": abbrevInternal, "dr": abbrevInternal,
+		"prof": abbrevInternal, "st": abbrevInternal, "mt": abbrevInternal, "sr": abbrevInternal,
+		"gen": abbrevInternal, "col": abbrevInternal, "capt": abbrevInternal, "lt": abbrevInternal,
+		"rev": abbrevInternal, "hon": abbrevInternal, "gov": abbrevInternal, "sen": abbrevInternal,
+		"e.g": abbrevInternal, "i.e": abbrevInternal, "vs": abbrevInternal, "cf": abbrevInternal,
+		"no": abbrevTerminal, "nos": abbrevInternal, "fig": abbrevInternal, "figs": abbrevInternal,
+		"vol": abbrevInternal, "pp": abbrevInternal, "p": abbrevTerminal, "approx": abbrevInternal,
+		"ca": abbrevInternal, "est": abbrevTerminal, "dept": abbrevInternal, "ed": abbrevInternal,
+		"jan": abbrevInternal, "feb": abbrevInternal, "mar": abbrevInternal, "apr": abbrevInternal,
+		"jun": abbrevInternal, "jul": abbrevInternal, "aug": abbrevInternal, "sep": abbrevInternal,
+		"sept": abbrevInternal, "oct": abbrevInternal, "nov": abbrevInternal, "dec": abbrevInternal,
+		"etc": abbrevTerminal, "inc": abbrevTerminal, "ltd": abbrevTerminal, "co": abbrevTerminal,
+		"corp": abbrevTerminal, "jr": abbrevTerminal, "a.m": abbrevTerminal, "p.m": abbrevTerminal,
+		"u.s": abbrevTerminal, "u.k": abbrevTerminal, "u.s.a": abbrevTerminal, "ph.d": abbrevTerminal,
+	},
+	"de": {
+		"z.b": abbrevInternal, "d.h": abbrevInternal, "u.a": abbrevInternal, "vgl": abbrevInternal,
+		"bzw": abbrevInternal, "nr": abbrevInternal, "hr": abbrevInternal, "fr": abbrevInternal,
+		"str": abbrevInternal, "evtl": abbrevInternal, "ggf": abbrevInternal, "s": abbrevTerminal,
+		"usw": abbrevTerminal, "usf": abbrevTerminal, "etc": abbrevTerminal,
+	},
+	"fr": {
+		"mme": abbrevInternal, "mlle": abbrevInternal, "mm": abbrevInternal, "me": abbrevTerminal,
+		"p.ex": abbrevInternal, "env": abbrevInternal, "cf": abbrevInternal, "av": abbrevTerminal,
+		"etc": abbrevTerminal,
+	},
+	"es": {
+		"sr": abbrevInternal, "sra": abbrevInternal, "srta": abbrevInternal, "dra": abbrevInternal,
+		"ud": abbrevInternal, "uds": abbrevInternal, "p.ej": abbrevInternal, "núm": abbrevInternal,
+		"pág": abbrevInternal, "etc": abbrevTerminal,
+	},
+}
+
+// buildAbbreviations 合并指定语言的缩写表，未指定时使用全部语言
+func buildAbbreviations(languages []string) map[string]abbrevKind {
+	if len(languages) == 0 {
+		for lang := range abbreviationsByLanguage {
+			languages = append(languages, lang)
+		}
+	}
+
+	abbrevs := make(map[string]abbrevKind)
+	for _, lang := range languages {
+		for word, kind := range abbreviationsByLanguage[strings.ToLower(lang)] {
+			// 多种语言冲突时，以"句中"优先，避免误切
+			if existing, ok := abbrevs[word]; !ok || existing == abbrevTerminal {
+				abbrevs[word] = kind
+			}
+		}
+	}
+	return abbrevs
+}
+
+// splitBySentence 按句子边界切分文本
+//
+// 返回的句子包含结尾标点、紧随其后的右引号/右括号以及句后空白，
+// 按顺序拼接即可还原原文。处理的情况包括：小数与版本号、网址、
+// 缩写与姓名首字母、中英文省略号、引号归属以及中英文混排。
+func (s *SmartSegmenter) splitBySentence(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+
+	runes := []rune(text)
+	var sentences []string
+	start := 0
+
+	for i := 0; i < len(runes); i++ {
+		end, ok := s.boundaryAt(runes, i)
+		if !ok {
+			continue
+		}
+		// 句后空白归属当前句子
+		for end < len(runes) && unicode.IsSpace(runes[end]) {
+			end++
+		}
+		if strings.TrimSpace(string(runes[start:end])) != "" {
+			sentences = append(sentences, string(runes[start:end]))
+			start = end
+		}
+		i = end - 1
+	}
+
+	if start < len(runes) {
+		rest := string(runes[start:])
+		if strings.TrimSpace(rest) != "" {
+			sentences = append(sentences, rest)
+		} else if len(sentences) > 0 {
+			sentences[len(sentences)-1] += rest
+		}
+	}
+
+	return sentences
+}
+
+// boundaryAt 判断位置 i 是否为句子结尾，返回句子结束位置（不含）
+func (s *SmartSegmenter) boundaryAt(runes []rune, i int) (int, bool) {
+	r := runes[i]
+	switch {
+	case r == '\n':
+		return i + 1, true
+
+	case r == '。' || r == '！' || r == '？' || r == '｡' || r == '；' || r == ';':
+		return skipClosers(runes, i+1), true
+
+	case r == '!' || r == '?':
+		return skipClosers(runes, i+1), true
+
+	case r == '…' || r == '⋯':
+		if i > 0 && (runes[i-1] == '…' || runes[i-1] == '⋯') {
+			return 0, false
+		}
+		end := i
+		for end < len(runes) && (runes[end] == '…' || runes[end] == '⋯') {
+			end++
+		}
+		return ellipsisBoundary(runes, end)
+
+	case r == '.':
+		if i > 0 && runes[i-1] == '.' {
+			return 0, false
+		}
+		end := i
+		for end < len(runes) && runes[end] == '.' {
+			end++
+		}
+		if end-i >= 2 {
+			return ellipsisBoundary(runes, end)
+		}
+		if s.isPeriodBoundary(runes, i) {
+			return skipClosers(runes, i+1), true
+		}
+	}
+	return 0, false
+}
+
+// isPeriodBoundary 判断单个英文句点是否结束句子
+func (s *SmartSegmenter) isPeriodBoundary(runes []rune, i int) bool {
+	next := i + 1
+	if next < len(runes) {
+		c := runes[next]
+		// 句点后紧跟字母或数字：小数、版本号、网址、e.g 中间的点等
+		if !unicode.IsSpace(c) && !isCloser(c) && !isOpener(c) && !isCJK(c) && !isClosingASCIIQuote(runes, next) {
+			return false
+		}
+	}
+
+	// 取句点前的单词（字母和句点组成，如 "Mr"、"e.g"、"U.S"）
+	wordStart := i
+	for wordStart > 0 && (unicode.IsLetter(runes[wordStart-1]) || runes[wordStart-1] == '.') && !isCJK(runes[wordStart-1]) {
+		wordStart--
+	}
+	word := string(runes[wordStart:i])
+	following := nextNonSpace(runes, next)
+
+	if word == "" {
+		// 行首的编号（如 "1. 简介"）不结束句子
+		digitStart := i
+		for digitStart > 0 && unicode.IsDigit(runes[digitStart-1]) {
+			digitStart--
+		}
+		if digitStart < i && (digitStart == 0 || runes[digitStart-1] == '\n') && following != 0 {
+			return false
+		}
+	} else {
+		switch s.abbreviations[strings.ToLower(word)] {
+		case abbrevInternal:
+			return false
+		case abbrevTerminal:
+			return following == 0 || unicode.IsUpper(following) || isCJK(following) || isOpener(following)
+		}
+
+		// 大写单字母视为姓名首字母（如 "J. K. Rowling"），代词 "I" 除外
+		letters := []rune(word)
+		if len(letters) == 1 && unicode.IsUpper(letters[0]) && letters[0] != 'I' {
+			return false
+		}
+	}
+
+	// 后文以小写字母开头，说明句子尚未结束
+	if following != 0 && unicode.IsLower(following) {
+		return false
+	}
+	return true
+}
+
+// ellipsisBoundary 判断省略号（end 为省略号之后的位置）是否结束句子
+// 省略号后跟右引号、文本结尾，或空白后接大写字母/中文时视为句末；
+// 句中的停顿（如"我……我不知道"）不切分
+func ellipsisBoundary(runes []rune, end int) (int, bool) {
+	closed := skipClosers(runes, end)
+	if closed >= len(runes) || closed > end {
+		return closed, true
+	}
+	if !unicode.IsSpace(runes[closed]) {
+		return 0, false
+	}
+	following := nextNonSpace(runes, closed)
+	if following == 0 || unicode.IsUpper(following) || isCJK(following) || isOpener(following) {
+		return closed, true
+	}
+	return 0, false
+}
+
+// skipClosers 跳过句末标点之后的右引号、右括号以及连续的结束标点
+func skipClosers(runes []rune, j int) int {
+	for j < len(runes) {
+		c := runes[j]
+		switch {
+		case isCloser(c), isTerminalMark(c):
+			j++
+		case isClosingASCIIQuote(runes, j):
+			j++
+		default:
+			return j
+		}
+	}
+	return j
+}
+
+// isClosingASCIIQuote 判断位置 j 的 ASCII 引号是否为右引号
+// ASCII 引号无法区分左右，只有其后不是字母数字时才视为右引号
+func isClosingASCIIQuote(runes []rune, j int) bool {
+	if runes[j] != '"' && runes[j] != '\'' {
+		return false
+	}
+	return j+1 >= len(runes) || !(unicode.IsLetter(runes[j+1]) || unicode.IsDigit(runes[j+1]))
+}
+
+// nextNonSpace 返回从 j 开始的第一个非空白字符，不存在时返回 0
+func nextNonSpace(runes []rune, j int) rune {
+	for ; j < len(runes); j++ {
+		if !unicode.IsSpace(runes[j]) {
+			return runes[j]
+		}
+	}
+	return 0
+}
+
+// isTerminalMark 判断是否为句末标点
+func isTerminalMark(r rune) bool {
+	return strings.ContainsRune("。！？｡!?…⋯", r)
+}
+
+// isCloser 判断是否为右引号或右括号
+func isCloser(r rune) bool {
+	return strings.ContainsRune("”’」』）)】]》〉}›»", r)
+}
+
+// isOpener 判断是否为左引号或左括号
+func isOpener(r rune) bool {
+	return strings.ContainsRune("“‘「『（(【[《〈{‹«", r)
+}
+
+// isCJK 判断是否为中日韩文字
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
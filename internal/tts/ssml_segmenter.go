@@ -0,0 +1,437 @@
+package tts
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidSSML 表示 SSML 文档无法解析或结构不合法
+var ErrInvalidSSML = errors.New("invalid SSML document")
+
+// ssmlContainers 可以跨分段拆开的容器元素
+// 分段时在每个片段中重新打开这些元素，保持语音、语速、风格等上下文；
+// 其他元素（say-as、phoneme、sub、break 等）作为整体，不会被拆开
+var ssmlContainers = map[string]bool{
+	"speak":            true,
+	"voice":            true,
+	"prosody":          true,
+	"mstts:express-as": true,
+	"lang":             true,
+	"p":                true,
+	"s":                true,
+}
+
+// ssmlTextEscaper 转义 SSML 文本内容
+var ssmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// ssmlAttrEscaper 转义 SSML 属性值（属性统一使用双引号）
+var ssmlAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// ssmlElement 文档中一个已打开的容器元素
+type ssmlElement struct {
+	id    int // 元素在文档中的序号，用于区分同名的相邻元素
+	start xml.StartElement
+}
+
+// ssmlUnit 分段的最小单位：一个句子或一个不可拆分的元素
+type ssmlUnit struct {
+	context []*ssmlElement // 所在的容器元素，由外到内
+	markup  string         // 序列化后的内容
+	size    int
+	blank   bool // 仅包含空白
+	endsRun bool // 之后可以切分（句末、停顿或超长句子的切分点）
+}
+
+// SSMLSegmenter 按元素和句子边界切分 SSML 文档
+//
+// 每个片段都是完整的 SSML 文档：片段开头重新打开所在的 <speak>、<voice>、
+// <prosody>、<mstts:express-as> 等元素（保留原有属性），结尾依次关闭。
+// 长度预算只计算片段内容，不包括重新打开的上下文元素。
+type SSMLSegmenter struct {
+	sentences *SmartSegmenter
+}
+
+// NewSSMLSegmenter 创建 SSML 分段器
+func NewSSMLSegmenter() *SSMLSegmenter {
+	return &SSMLSegmenter{sentences: NewSmartSegmenter()}
+}
+
+// Segment 将 SSML 文档切分为不超过 limit 的完整文档
+func (s *SSMLSegmenter) Segment(doc string, limit int, unit BudgetUnit) ([]string, error) {
+	measure := utf8.RuneCountInString
+	if unit == BudgetSSMLBytes {
+		measure = func(markup string) int { return len(markup) }
+	}
+
+	units, err := s.parseUnits(doc, limit, measure)
+	if err != nil {
+		return nil, err
+	}
+	return groupSSMLUnits(units, limit), nil
+}
+
+// parseUnits 解析文档，生成按顺序排列的分段单位
+func (s *SSMLSegmenter) parseUnits(doc string, limit int, measure func(string) int) ([]*ssmlUnit, error) {
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	var (
+		units  []*ssmlUnit
+		stack  []*ssmlElement
+		nextID int
+		seen   bool
+	)
+
+	addUnit := func(markup string, blank, endsRun bool) {
+		context := make([]*ssmlElement, len(stack))
+		copy(context, stack)
+		units = append(units, &ssmlUnit{
+			context: context,
+			markup:  markup,
+			size:    measure(markup),
+			blank:   blank,
+			endsRun: endsRun,
+		})
+	}
+
+	for {
+		// 使用 RawToken 保留命名空间前缀（如 mstts:express-as）
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSSML, err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := qualifiedName(t.Name)
+			if len(stack) == 0 {
+				if seen || name != "speak" {
+					return nil, fmt.Errorf("%w: root element must be a single <speak>", ErrInvalidSSML)
+				}
+				seen = true
+			}
+			if ssmlContainers[name] {
+				nextID++
+				stack = append(stack, &ssmlElement{id: nextID, start: t.Copy()})
+				continue
+			}
+			markup, err := serializeSSMLElement(dec, t)
+			if err != nil {
+				return nil, err
+			}
+			addUnit(markup, false, ssmlPauses[name])
+
+		case xml.EndElement:
+			if len(stack) == 0 || qualifiedName(stack[len(stack)-1].start.Name) != qualifiedName(t.Name) {
+				return nil, fmt.Errorf("%w: unexpected </%s>", ErrInvalidSSML, qualifiedName(t.Name))
+			}
+			stack = stack[:len(stack)-1]
+
+		case xml.CharData:
+			text := string(t)
+			if len(stack) == 0 {
+				if strings.TrimSpace(text) != "" {
+					return nil, fmt.Errorf("%w: text outside <speak>", ErrInvalidSSML)
+				}
+				continue
+			}
+			if strings.TrimSpace(text) == "" {
+				addUnit(" ", true, false)
+				continue
+			}
+			sentences := s.sentences.splitBySentence(text)
+			for i, sentence := range sentences {
+				pieces := fitSSMLText(sentence, limit, measure)
+				for j, piece := range pieces {
+					// 文本末尾的句子可能与后面的行内元素同属一句（如"电话是<say-as>…</say-as>。"）
+					endsRun := j < len(pieces)-1 || i < len(sentences)-1 || endsSentence(piece)
+					addUnit(ssmlTextEscaper.Replace(piece), false, endsRun)
+				}
+			}
+		}
+	}
+
+	if !seen {
+		return nil, fmt.Errorf("%w: missing <speak> element", ErrInvalidSSML)
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("%w: unclosed <%s>", ErrInvalidSSML, qualifiedName(stack[len(stack)-1].start.Name))
+	}
+	return dropLayoutWhitespace(units), nil
+}
+
+// ssmlPauses 表示停顿的元素，其后可以切分
+var ssmlPauses = map[string]bool{
+	"break":         true,
+	"mstts:silence": true,
+}
+
+// endsSentence 判断文本是否以句末标点或换行结束
+func endsSentence(text string) bool {
+	trimmed := strings.TrimRightFunc(text, unicode.IsSpace)
+	if strings.ContainsRune(text[len(trimmed):], '\n') {
+		return true
+	}
+	trimmed = strings.TrimRightFunc(trimmed, isCloser)
+	last, _ := utf8.DecodeLastRuneInString(trimmed)
+	return isTerminalMark(last) || strings.ContainsRune(".;；", last)
+}
+
+// dropLayoutWhitespace 去除容器标签之间用于排版的空白
+// 只保留两侧都是同一容器内内容的空白（如两个 say-as 之间的空格）
+func dropLayoutWhitespace(units []*ssmlUnit) []*ssmlUnit {
+	kept := units[:0]
+	for i, u := range units {
+		if u.blank && (i == 0 || i == len(units)-1 ||
+			!sameContext(u.context, units[i-1].context) || !sameContext(u.context, units[i+1].context)) {
+			continue
+		}
+		kept = append(kept, u)
+	}
+	return kept
+}
+
+// sameContext 判断两个单位是否位于同一组容器元素中
+func sameContext(a, b []*ssmlElement) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].id != b[i].id {
+			return false
+		}
+	}
+	return true
+}
+
+// ssmlRuns 将分段单位合并为不可再分的段落：同一容器内、同一句子中的
+// 文本和行内元素必须位于同一片段；超出预算的段落退化为逐个单位切分
+func ssmlRuns(units []*ssmlUnit, limit int) [][]*ssmlUnit {
+	var runs [][]*ssmlUnit
+	var current []*ssmlUnit
+	size := 0
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		if size > limit {
+			for _, u := range current {
+				runs = append(runs, []*ssmlUnit{u})
+			}
+		} else {
+			runs = append(runs, current)
+		}
+		current, size = nil, 0
+	}
+
+	for i, u := range units {
+		if i > 0 {
+			prev := units[i-1]
+			if prev.endsRun || prev.blank || u.blank || !sameContext(prev.context, u.context) {
+				flush()
+			}
+		}
+		current = append(current, u)
+		size += u.size
+	}
+	flush()
+	return runs
+}
+
+// fitSSMLText 将超出预算的句子按分句、词切分，使转义后的长度不超过 limit
+func fitSSMLText(text string, limit int, measure func(string) int) []string {
+	size := measure(ssmlTextEscaper.Replace(text))
+	runes := utf8.RuneCountInString(text)
+	if size <= limit || runes <= 1 {
+		return []string{text}
+	}
+
+	runeLimit := runes * limit / size
+	if runeLimit >= runes {
+		runeLimit = runes - 1
+	}
+	if runeLimit < 1 {
+		runeLimit = 1
+	}
+
+	var pieces []string
+	for _, piece := range splitHierarchically(text, runeLimit, 0) {
+		pieces = append(pieces, fitSSMLText(piece, limit, measure)...)
+	}
+	return pieces
+}
+
+// groupSSMLUnits 将分段单位分组，每组渲染为一个完整的 SSML 文档
+// 分组方式与纯文本相同：在最少片段数不变的前提下使各片段长度尽量均匀
+func groupSSMLUnits(units []*ssmlUnit, limit int) []string {
+	runs := ssmlRuns(units, limit)
+	lengths := make([]int, len(runs))
+	total, longest := 0, 0
+	for i, run := range runs {
+		for _, u := range run {
+			lengths[i] += u.size
+		}
+		total += lengths[i]
+		if lengths[i] > longest {
+			longest = lengths[i]
+		}
+	}
+	if total == 0 {
+		return []string{}
+	}
+	capacity := balancedCapacity(lengths, total, longest, limit)
+
+	var chunks []string
+	var group []*ssmlUnit
+	size := 0
+	flush := func() {
+		if chunk, ok := renderSSMLChunk(group); ok {
+			chunks = append(chunks, chunk)
+		}
+		group, size = nil, 0
+	}
+	for i, run := range runs {
+		if size > 0 && size+lengths[i] > capacity {
+			flush()
+		}
+		group = append(group, run...)
+		size += lengths[i]
+	}
+	flush()
+	return chunks
+}
+
+// renderSSMLChunk 渲染一组分段单位，按需打开和关闭容器元素
+// 只包含空白的分组返回 false
+func renderSSMLChunk(units []*ssmlUnit) (string, bool) {
+	blank := true
+	for _, u := range units {
+		blank = blank && u.blank
+	}
+	if blank {
+		return "", false
+	}
+
+	var b strings.Builder
+	var open []*ssmlElement
+	for _, u := range units {
+		shared := 0
+		for shared < len(open) && shared < len(u.context) && open[shared].id == u.context[shared].id {
+			shared++
+		}
+		if u.blank && shared < len(open) {
+			continue // 片段边界处的空白没有意义
+		}
+		for i := len(open) - 1; i >= shared; i-- {
+			writeSSMLEnd(&b, open[i].start.Name)
+		}
+		for _, el := range u.context[shared:] {
+			writeSSMLStart(&b, el.start)
+			b.WriteByte('>')
+		}
+		open = u.context
+		b.WriteString(u.markup)
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		writeSSMLEnd(&b, open[i].start.Name)
+	}
+	return b.String(), true
+}
+
+// serializeSSMLElement 将不可拆分的元素（含其内容）序列化
+func serializeSSMLElement(dec *xml.Decoder, start xml.StartElement) (string, error) {
+	var b strings.Builder
+	names := []string{qualifiedName(start.Name)}
+	writeSSMLStart(&b, start)
+	pending := true // 开始标签尚未闭合，没有内容时输出为自闭合标签
+
+	for len(names) > 0 {
+		tok, err := dec.RawToken()
+		if err != nil {
+			if err == io.EOF {
+				return "", fmt.Errorf("%w: unclosed <%s>", ErrInvalidSSML, names[len(names)-1])
+			}
+			return "", fmt.Errorf("%w: %v", ErrInvalidSSML, err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if pending {
+				b.WriteByte('>')
+			}
+			names = append(names, qualifiedName(t.Name))
+			writeSSMLStart(&b, t)
+			pending = true
+		case xml.EndElement:
+			if names[len(names)-1] != qualifiedName(t.Name) {
+				return "", fmt.Errorf("%w: unexpected </%s>", ErrInvalidSSML, qualifiedName(t.Name))
+			}
+			names = names[:len(names)-1]
+			if pending {
+				b.WriteString("/>")
+				pending = false
+			} else {
+				writeSSMLEnd(&b, t.Name)
+			}
+		case xml.CharData:
+			if pending {
+				b.WriteByte('>')
+				pending = false
+			}
+			b.WriteString(ssmlTextEscaper.Replace(string(t)))
+		}
+	}
+	return b.String(), nil
+}
+
+// writeSSMLStart 写入开始标签（不含结尾的 '>'）
+func writeSSMLStart(b *strings.Builder, start xml.StartElement) {
+	b.WriteByte('<')
+	b.WriteString(qualifiedName(start.Name))
+	for _, attr := range start.Attr {
+		b.WriteByte(' ')
+		b.WriteString(qualifiedName(attr.Name))
+		b.WriteString(`="`)
+		b.WriteString(ssmlAttrEscaper.Replace(attr.Value))
+		b.WriteByte('"')
+	}
+}
+
+// writeSSMLEnd 写入结束标签
+func writeSSMLEnd(b *strings.Builder, name xml.Name) {
+	b.WriteString("</")
+	b.WriteString(qualifiedName(name))
+	b.WriteByte('>')
+}
+
+// qualifiedName 返回带命名空间前缀的元素名（RawToken 中 Space 为原始前缀）
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// ssmlPlainText 提取 SSML 文档中的文本内容，无法解析时返回原文
+func ssmlPlainText(doc string) string {
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	var b strings.Builder
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			return b.String()
+		}
+		if err != nil {
+			return doc
+		}
+		if text, ok := tok.(xml.CharData); ok {
+			b.Write(text)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package tts
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"tts/internal/models"
+)
+
+const testSSMLHeader = `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xmlns:mstts="http://www.w3.org/2001/mstts" xml:lang="zh-CN">`
+
+// TestSSMLSegmenter_KeepsContext 测试每个片段都重新打开所在的上下文元素
+func TestSSMLSegmenter_KeepsContext(t *testing.T) {
+	doc := testSSMLHeader +
+		`<voice name="zh-CN-XiaoxiaoNeural"><mstts:express-as style="cheerful"><prosody rate="+10%">` +
+		strings.Repeat("今天天气很好。", 12) +
+		`</prosody></mstts:express-as></voice></speak>`
+
+	chunks, err := NewSSMLSegmenter().Segment(doc, 30, BudgetRunes)
+	if err != nil {
+		t.Fatalf("Segment() error = %v", err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("期望多个片段, got %d", len(chunks))
+	}
+
+	var spoken strings.Builder
+	for i, chunk := range chunks {
+		if !strings.HasPrefix(chunk, testSSMLHeader+`<voice name="zh-CN-XiaoxiaoNeural"><mstts:express-as style="cheerful"><prosody rate="+10%">`) {
+			t.Errorf("片段 %d 缺少上下文: %s", i, chunk)
+		}
+		if !strings.HasSuffix(chunk, `</prosody></mstts:express-as></voice></speak>`) {
+			t.Errorf("片段 %d 未正确关闭: %s", i, chunk)
+		}
+		assertWellFormed(t, chunk)
+		text := ssmlPlainText(chunk)
+		if n := utf8.RuneCountInString(text); n > 30 {
+			t.Errorf("片段 %d 长度 %d 超过 30", i, n)
+		}
+		spoken.WriteString(text)
+	}
+	if spoken.String() != strings.Repeat("今天天气很好。", 12) {
+		t.Errorf("片段拼接后的文本与原文不一致: %q", spoken.String())
+	}
+}
+
+// TestSSMLSegmenter_ElementBoundaries 测试在句子和元素边界切分，不拆开 say-as 等元素，并区分相邻的同名元素
+func TestSSMLSegmenter_ElementBoundaries(t *testing.T) {
+	doc := testSSMLHeader +
+		`<voice name="A">第一位说话人说了一句比较长的话。<break time="500ms"/>` +
+		`电话是<say-as interpret-as="telephone">010-12345678</say-as>。</voice>` +
+		`<voice name="B">第二位说话人 &amp; 他的回答。</voice></speak>`
+
+	chunks, err := NewSSMLSegmenter().Segment(doc, 90, BudgetRunes)
+	if err != nil {
+		t.Fatalf("Segment() error = %v", err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("期望多个片段, got %d", len(chunks))
+	}
+
+	joined := strings.Join(chunks, "\n")
+	if !strings.Contains(joined, `<break time="500ms"/>`) {
+		t.Error("自闭合元素应原样保留")
+	}
+	if !strings.Contains(joined, `<say-as interpret-as="telephone">010-12345678</say-as>`) {
+		t.Error("say-as 元素不应被拆开")
+	}
+	if !strings.Contains(joined, "&amp;") {
+		t.Error("文本应重新转义")
+	}
+	for i, chunk := range chunks {
+		assertWellFormed(t, chunk)
+		if strings.Contains(chunk, "电话是") && !strings.Contains(chunk, "电话是<say-as") {
+			t.Errorf("同一句中的文本与行内元素不应拆开: %s", chunk)
+		}
+		if strings.Contains(chunk, "第二位") && !strings.Contains(chunk, `<voice name="B">`) {
+			t.Errorf("片段 %d 的语音上下文错误: %s", i, chunk)
+		}
+		if strings.Contains(chunk, "第一位") && strings.Contains(chunk, `<voice name="B">`) && !strings.Contains(chunk, `</voice><voice name="B">`) {
+			t.Errorf("相邻的 voice 元素不应合并: %s", chunk)
+		}
+	}
+}
+
+// TestSSMLSegmenter_SSMLBytes 测试按字节预算切分
+func TestSSMLSegmenter_SSMLBytes(t *testing.T) {
+	doc := testSSMLHeader + `<voice name="A">` + strings.Repeat("中文句子。", 20) + `</voice></speak>`
+
+	chunks, err := NewSSMLSegmenter().Segment(doc, 60, BudgetSSMLBytes)
+	if err != nil {
+		t.Fatalf("Segment() error = %v", err)
+	}
+	overhead := len(testSSMLHeader + `<voice name="A"></voice></speak>`)
+	for i, chunk := range chunks {
+		if n := len(chunk) - overhead; n > 60 {
+			t.Errorf("片段 %d 内容 %d 字节，超过 60", i, n)
+		}
+	}
+}
+
+// TestSSMLSegmenter_Invalid 测试非法 SSML 返回 ErrInvalidSSML
+func TestSSMLSegmenter_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{"不是 XML", "<speak>未闭合"},
+		{"根元素不是 speak", "<voice>文本</voice>"},
+		{"标签不匹配", "<speak><voice>文本</prosody></speak>"},
+		{"多个根元素", "<speak>一</speak><speak>二</speak>"},
+		{"纯文本", "只有文本"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSSMLSegmenter().Segment(tt.doc, 100, BudgetRunes); !errors.Is(err, ErrInvalidSSML) {
+				t.Errorf("Segment() error = %v, want ErrInvalidSSML", err)
+			}
+		})
+	}
+}
+
+// TestSegmentRequest_SSML 测试 SSML 分段原样提交
+func TestSegmentRequest_SSML(t *testing.T) {
+	base := models.TTSRequest{SSML: "<speak>原文</speak>", Voice: "zh-CN-XiaoxiaoNeural", Format: "audio-24khz-48kbitrate-mono-mp3"}
+	chunk := "<speak>片段&lt;</speak>"
+
+	base.SSML = chunk
+	got := segmentRequest(base, 2, "zh-CN-YunxiNeural")
+	if got.SSML != chunk || got.Text != "" || got.Voice != base.Voice || got.Format != base.Format {
+		t.Errorf("SSML 分段请求 = %+v", got)
+	}
+}
+
+// assertWellFormed 校验片段是格式正确的 XML
+func assertWellFormed(t *testing.T, doc string) {
+	t.Helper()
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err != io.EOF {
+				t.Errorf("片段不是合法的 XML: %v\n%s", err, doc)
+			}
+			return
+		}
+	}
+}
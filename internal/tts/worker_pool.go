@@ -9,7 +9,7 @@ import (
 
 	"github.com/rs/zerolog"
 	"tts/internal/models"
-	"tts/internal/tts/microsoft"
+	"tts/internal/tts/limiter"
 )
 
 // SegmentJob 表示一个分段合成任务
@@ -22,24 +22,43 @@ type SegmentJob struct {
 
 // SegmentResult 表示分段合成结果
 type SegmentResult struct {
-	ID        string  // 任务 ID
-	Index     int     // 片段索引
-	AudioData []byte  // 音频数据
-	Error     error   // 错误信息
+	ID        string        // 任务 ID
+	Index     int           // 片段索引
+	AudioData []byte        // 音频数据
+	Error     error         // 错误信息
 	Duration  time.Duration // 处理耗时
+	CacheHit  bool          // 是否命中分段缓存
+}
+
+// Batch 表示一次请求提交到工作池的一组任务
+// 每个 Batch 拥有独立的结果通道，不同请求之间的结果互不干扰
+type Batch struct {
+	id      string
+	pool    *WorkerPool
+	pending []*SegmentJob       // 尚未被 worker 取走的任务（受 pool.mu 保护）
+	results chan *SegmentResult // 本批次的结果通道
+	done    chan struct{}       // 批次结束时关闭
+	once    sync.Once
 }
 
 // WorkerPool 音频处理工作池
+// 各请求的任务以 Batch 为单位排队，worker 在活跃的 Batch 之间轮询取任务，
+// 避免单个超长文本独占所有 worker
 type WorkerPool struct {
 	workers     int                    // worker 数量
-	jobs        chan *SegmentJob       // 任务队列
-	results     chan *SegmentResult    // 结果队列
+	mu          sync.Mutex             // 保护调度队列
+	batches     []*Batch               // 有待处理任务的批次（轮询顺序）
+	next        int                    // 下一个轮询位置
+	queued      int                    // 排队中的任务总数
+	notify      chan struct{}          // 有新任务时关闭以唤醒空闲 worker
+	batchSeq    int64                  // 批次序号(原子操作)
 	wg          sync.WaitGroup         // 等待 goroutine 完成
-	client      *microsoft.Client      // TTS 客户端
+	client      Service                // TTS 服务（通常为带缓存的服务）
 	ctx         context.Context        // 上下文
 	cancel      context.CancelFunc     // 取消函数
 	metrics     *PoolMetrics           // 性能指标
 	closed      int32                  // 关闭状态标志(原子操作)
+	limiter     *AdaptiveLimiter       // 自适应并发限制器
 	logger      zerolog.Logger         // 日志记录器
 }
 
@@ -54,7 +73,8 @@ type PoolMetrics struct {
 }
 
 // NewWorkerPool 创建新的工作池
-func NewWorkerPool(workers int, client *microsoft.Client, logger zerolog.Logger) *WorkerPool {
+// adaptive 参数是可选的，启用后实际并发数在 [1, workers] 之间按 AIMD 自动调整
+func NewWorkerPool(workers int, client Service, logger zerolog.Logger, adaptive ...AdaptiveConfig) *WorkerPool {
 	if workers <= 0 {
 		workers = 5 // 默认 5 个 worker
 	}
@@ -62,13 +82,18 @@ func NewWorkerPool(workers int, client *microsoft.Client, logger zerolog.Logger)
 		workers = 50 // 最大限制 50 个 worker
 	}
 	
+	var adaptiveCfg AdaptiveConfig
+	if len(adaptive) > 0 {
+		adaptiveCfg = adaptive[0]
+	}
+	
 	return &WorkerPool{
 		workers:  workers,
-		jobs:     make(chan *SegmentJob, workers*2), // 缓冲区为 worker 数量的 2 倍
-		results:  make(chan *SegmentResult, workers*2), // 增大结果缓冲区
+		notify:   make(chan struct{}),
 		client:   client,
 		metrics:  &PoolMetrics{},
 		closed:   0,
+		limiter:  NewAdaptiveLimiter(1, workers, adaptiveCfg),
 		logger:   logger,
 	}
 }
@@ -78,7 +103,10 @@ func (p *WorkerPool) Start() {
 	// 使用后台 context 创建一个可取消的 context,用于控制整个池的生命周期
 	p.ctx, p.cancel = context.WithCancel(context.Background())
 
-	p.logger.Info().Int("workers", p.workers).Msg("Starting worker pool")
+	p.logger.Info().
+		Int("workers", p.workers).
+		Int("concurrency_limit", p.limiter.Limit()).
+		Msg("Starting worker pool")
 	
 	// 启动 worker goroutines
 	for i := 0; i < p.workers; i++ {
@@ -99,30 +127,77 @@ func (p *WorkerPool) worker(id int) {
 	p.logger.Debug().Int("worker_id", id).Msg("Worker started")
 	
 	for {
-		select {
-		case <-p.ctx.Done():
+		// 先获取并发槽位再取任务，使实际并发数受自适应上限约束
+		if err := p.limiter.Acquire(p.ctx); err != nil {
 			p.logger.Debug().Int("worker_id", id).Msg("Worker stopped")
 			return
-			
-		case job, ok := <-p.jobs:
-			if !ok {
-				p.logger.Debug().Int("worker_id", id).Msg("Worker: job channel closed")
-				return
+		}
+		
+		job, batch, ok := p.nextJob()
+		if !ok {
+			p.limiter.Release(OutcomeIgnored, 0)
+			p.logger.Debug().Int("worker_id", id).Msg("Worker stopped")
+			return
+		}
+		
+		// 处理任务
+		result := p.processJob(job, id)
+		p.releaseSlot(job, result)
+		
+		// 发送结果到所属批次
+		batch.deliver(result)
+	}
+}
+
+// nextJob 以轮询方式从活跃批次中取出下一个任务，没有任务时阻塞
+// 工作池关闭时返回 ok=false
+func (p *WorkerPool) nextJob() (*SegmentJob, *Batch, bool) {
+	for {
+		p.mu.Lock()
+		if len(p.batches) > 0 {
+			if p.next >= len(p.batches) {
+				p.next = 0
 			}
+			batch := p.batches[p.next]
+			job := batch.pending[0]
+			batch.pending[0] = nil
+			batch.pending = batch.pending[1:]
+			p.queued--
 			
-			// 处理任务
-			result := p.processJob(job, id)
-			
-			// 发送结果
-			select {
-			case p.results <- result:
-			case <-p.ctx.Done():
-				return
+			if len(batch.pending) == 0 {
+				// 该批次已无待处理任务，移出轮询队列（next 自然指向下一个批次）
+				p.removeBatchLocked(batch)
+			} else {
+				p.next++
 			}
+			p.mu.Unlock()
+			return job, batch, true
+		}
+		wait := p.notify
+		p.mu.Unlock()
+		
+		select {
+		case <-wait:
+		case <-p.ctx.Done():
+			return nil, nil, false
 		}
 	}
 }
 
+// removeBatchLocked 将批次移出轮询队列（需持有 p.mu）
+func (p *WorkerPool) removeBatchLocked(batch *Batch) {
+	for i, b := range p.batches {
+		if b != batch {
+			continue
+		}
+		p.batches = append(p.batches[:i], p.batches[i+1:]...)
+		if i < p.next {
+			p.next--
+		}
+		return
+	}
+}
+
 // processJob 处理单个任务
 func (p *WorkerPool) processJob(job *SegmentJob, workerID int) *SegmentResult {
 	startTime := time.Now()
@@ -147,9 +222,12 @@ func (p *WorkerPool) processJob(job *SegmentJob, workerID int) *SegmentResult {
 	}
 
 	// 执行 TTS 合成, 使用 job 自己的 context
-	resp, err := p.client.SynthesizeSpeech(job.Context, job.Request)
+	// 分段任务以后台优先级占用全局上游并发槽位，交互式请求优先
+	jobCtx := limiter.WithPriority(job.Context, limiter.PriorityBackground)
+	resp, err := p.client.SynthesizeSpeech(jobCtx, job.Request)
 	if err != nil {
 		result.Error = fmt.Errorf("worker %d failed to synthesize segment %d: %w", workerID, job.Index, err)
+		result.Duration = time.Since(startTime)
 		p.logger.Error().Err(result.Error).Msg("Worker failed to synthesize segment")
 
 		// 更新失败指标
@@ -159,6 +237,7 @@ func (p *WorkerPool) processJob(job *SegmentJob, workerID int) *SegmentResult {
 	}
 	
 	result.AudioData = resp.AudioContent
+	result.CacheHit = resp.CacheHit
 	result.Duration = time.Since(startTime)
 	
 	// 更新完成指标和延迟统计
@@ -175,46 +254,128 @@ func (p *WorkerPool) processJob(job *SegmentJob, workerID int) *SegmentResult {
 	return result
 }
 
-// Submit 提交任务到工作池
-func (p *WorkerPool) Submit(job *SegmentJob) error {
+// releaseSlot 释放并发槽位，并将任务结果反馈给自适应限制器
+func (p *WorkerPool) releaseSlot(job *SegmentJob, result *SegmentResult) {
+	before := p.limiter.Limit()
+	outcome := classifyOutcome(job.Context, result.Error)
+	if result.CacheHit {
+		// 缓存命中没有访问上游，其延迟不能反映上游状况
+		outcome = OutcomeIgnored
+	}
+	p.limiter.Release(outcome, result.Duration)
+	
+	if after := p.limiter.Limit(); after != before {
+		p.logger.Info().
+			Int("from", before).
+			Int("to", after).
+			Bool("error", result.Error != nil).
+			Msg("Adjusted worker pool concurrency limit")
+	}
+}
+
+// SubmitBatch 将一组任务作为一个批次提交到工作池
+// 返回的 Batch 提供独立的结果通道；ctx 取消或调用 Batch.Cancel 时，尚未开始的任务会被立即清除
+func (p *WorkerPool) SubmitBatch(ctx context.Context, jobs []*SegmentJob) (*Batch, error) {
 	// 检查是否已关闭
 	if atomic.LoadInt32(&p.closed) == 1 {
-		return fmt.Errorf("worker pool is closed")
+		return nil, fmt.Errorf("worker pool is closed")
 	}
 
-	// 检查 job 的 context 是否已经取消
-	if job.Context.Err() != nil {
-		return fmt.Errorf("job context cancelled before submission: %w", job.Context.Err())
+	// 检查请求的 context 是否已经取消
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("context cancelled before submission: %w", ctx.Err())
+	}
+
+	batch := &Batch{
+		id:      fmt.Sprintf("batch_%d", atomic.AddInt64(&p.batchSeq, 1)),
+		pool:    p,
+		pending: make([]*SegmentJob, 0, len(jobs)),
+		// 每个任务恰好产生一个结果，缓冲区足够时 worker 永远不会阻塞
+		results: make(chan *SegmentResult, len(jobs)),
+		done:    make(chan struct{}),
+	}
+	for _, job := range jobs {
+		if job.Context == nil {
+			job.Context = ctx
+		}
+		batch.pending = append(batch.pending, job)
 	}
 
 	// 更新提交指标
-	atomic.AddInt64(&p.metrics.TotalJobs, 1)
+	atomic.AddInt64(&p.metrics.TotalJobs, int64(len(jobs)))
 
-	// 尝试非阻塞提交
-	select {
-	case p.jobs <- job:
-		return nil
-	case <-p.ctx.Done(): // 检查池是否已关闭
-		return fmt.Errorf("worker pool context cancelled: %w", p.ctx.Err())
-	case <-job.Context.Done(): // 检查请求是否已取消
-		return fmt.Errorf("job context cancelled during submission: %w", job.Context.Err())
-	default:
-		// 队列满时记录警告并阻塞等待
-		p.logger.Warn().Int("capacity", cap(p.jobs)).Msg("Job queue is full, blocking submission")
+	if len(jobs) > 0 {
+		p.mu.Lock()
+		p.batches = append(p.batches, batch)
+		p.queued += len(jobs)
+		close(p.notify)
+		p.notify = make(chan struct{})
+		p.mu.Unlock()
+	}
+
+	// 请求被取消时（如客户端断开）立即清除排队中的任务
+	go func() {
 		select {
-		case p.jobs <- job:
-			return nil
-		case <-p.ctx.Done(): // 检查池是否已关闭
-			return fmt.Errorf("worker pool context cancelled while waiting: %w", p.ctx.Err())
-		case <-job.Context.Done(): // 检查请求是否已取消
-			return fmt.Errorf("job context cancelled while waiting for submission: %w", job.Context.Err())
+		case <-ctx.Done():
+			if purged := batch.purge(); purged > 0 {
+				p.logger.Info().
+					Str("batch_id", batch.id).
+					Int("purged_jobs", purged).
+					Msg("Request cancelled, purged queued jobs")
+			}
+		case <-batch.done:
+		case <-p.ctx.Done():
 		}
-	}
+	}()
+
+	p.logger.Debug().
+		Str("batch_id", batch.id).
+		Int("jobs", len(jobs)).
+		Msg("Submitted job batch")
+
+	return batch, nil
+}
+
+// Results 获取本批次的结果通道
+func (b *Batch) Results() <-chan *SegmentResult {
+	return b.results
 }
 
-// Results 获取结果通道
-func (p *WorkerPool) Results() <-chan *SegmentResult {
-	return p.results
+// Cancel 结束本批次，清除尚未开始的任务
+// 已经在执行的任务会正常完成，但其结果将被丢弃
+func (b *Batch) Cancel() {
+	b.purge()
+}
+
+// purge 清除本批次排队中的任务，返回被清除的数量
+func (b *Batch) purge() int {
+	purged := 0
+	b.once.Do(func() {
+		p := b.pool
+		p.mu.Lock()
+		purged = len(b.pending)
+		if purged > 0 {
+			p.queued -= purged
+			b.pending = nil
+			p.removeBatchLocked(b)
+		}
+		p.mu.Unlock()
+		close(b.done)
+	})
+	return purged
+}
+
+// deliver 投递任务结果
+func (b *Batch) deliver(result *SegmentResult) {
+	select {
+	case b.results <- result:
+	default:
+		// 缓冲区按任务数分配，不应发生；防御性丢弃以免阻塞 worker
+		b.pool.logger.Warn().
+			Str("batch_id", b.id).
+			Int("index", result.Index).
+			Msg("Dropped segment result: batch result buffer full")
+	}
 }
 
 // Close 关闭工作池
@@ -232,9 +393,6 @@ func (p *WorkerPool) Close() {
 		p.cancel()
 	}
 	
-	// 关闭任务通道
-	close(p.jobs)
-	
 	// 等待所有 worker 完成(带超时)
 	done := make(chan struct{})
 	go func() {
@@ -249,9 +407,6 @@ func (p *WorkerPool) Close() {
 		p.logger.Warn().Msg("Timeout waiting for workers to stop")
 	}
 	
-	// 关闭结果通道
-	close(p.results)
-	
 	// 更新活跃 worker 数
 	p.metrics.mu.Lock()
 	p.metrics.ActiveWorkers = 0
@@ -284,12 +439,14 @@ func (p *WorkerPool) GetMetrics() PoolMetrics {
 
 // PoolStats 工作池统计信息
 type PoolStats struct {
-	TotalJobs      int64         `json:"total_jobs"`
-	CompletedJobs  int64         `json:"completed_jobs"`
-	FailedJobs     int64         `json:"failed_jobs"`
-	ActiveWorkers  int           `json:"active_workers"`
-	QueueLength    int           `json:"queue_length"`
-	SuccessRate    float64       `json:"success_rate"`
+	TotalJobs        int64   `json:"total_jobs"`
+	CompletedJobs    int64   `json:"completed_jobs"`
+	FailedJobs       int64   `json:"failed_jobs"`
+	ActiveWorkers    int     `json:"active_workers"`
+	ConcurrencyLimit int     `json:"concurrency_limit"`
+	ActiveBatches    int     `json:"active_batches"`
+	QueueLength      int     `json:"queue_length"`
+	SuccessRate      float64 `json:"success_rate"`
 }
 
 // Stats 获取详细统计信息
@@ -301,13 +458,20 @@ func (p *WorkerPool) Stats() PoolStats {
 		successRate = float64(metrics.CompletedJobs) / float64(metrics.TotalJobs) * 100
 	}
 	
+	p.mu.Lock()
+	activeBatches := len(p.batches)
+	queued := p.queued
+	p.mu.Unlock()
+	
 	return PoolStats{
-		TotalJobs:     metrics.TotalJobs,
-		CompletedJobs: metrics.CompletedJobs,
-		FailedJobs:    metrics.FailedJobs,
-		ActiveWorkers: metrics.ActiveWorkers,
-		QueueLength:   len(p.jobs),
-		SuccessRate:   successRate,
+		TotalJobs:        metrics.TotalJobs,
+		CompletedJobs:    metrics.CompletedJobs,
+		FailedJobs:       metrics.FailedJobs,
+		ActiveWorkers:    metrics.ActiveWorkers,
+		ConcurrencyLimit: p.limiter.Limit(),
+		ActiveBatches:    activeBatches,
+		QueueLength:      queued,
+		SuccessRate:      successRate,
 	}
 }
 
@@ -0,0 +1,149 @@
+package tts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"tts/internal/models"
+)
+
+// newTestPool 创建一个不启动 worker 的工作池，用于直接测试调度逻辑
+func newTestPool(t *testing.T) *WorkerPool {
+	t.Helper()
+	pool := NewWorkerPool(2, nil, zerolog.Nop())
+	pool.ctx, pool.cancel = context.WithCancel(context.Background())
+	t.Cleanup(pool.cancel)
+	return pool
+}
+
+func makeJobs(prefix string, n int) []*SegmentJob {
+	jobs := make([]*SegmentJob, n)
+	for i := range jobs {
+		jobs[i] = &SegmentJob{ID: prefix, Index: i}
+	}
+	return jobs
+}
+
+// TestWorkerPool_RoundRobin 测试多个请求之间轮询调度
+func TestWorkerPool_RoundRobin(t *testing.T) {
+	pool := newTestPool(t)
+	ctx := context.Background()
+
+	bookA, err := pool.SubmitBatch(ctx, makeJobs("A", 4))
+	if err != nil {
+		t.Fatalf("SubmitBatch() error = %v", err)
+	}
+	defer bookA.Cancel()
+	bookB, err := pool.SubmitBatch(ctx, makeJobs("B", 2))
+	if err != nil {
+		t.Fatalf("SubmitBatch() error = %v", err)
+	}
+	defer bookB.Cancel()
+
+	var order string
+	for i := 0; i < 6; i++ {
+		job, _, ok := pool.nextJob()
+		if !ok {
+			t.Fatal("nextJob() returned ok=false")
+		}
+		order += job.ID
+	}
+
+	if want := "ABABAA"; order != want {
+		t.Errorf("调度顺序 = %s, want %s", order, want)
+	}
+	if stats := pool.Stats(); stats.QueueLength != 0 || stats.ActiveBatches != 0 {
+		t.Errorf("队列应为空, got queue=%d batches=%d", stats.QueueLength, stats.ActiveBatches)
+	}
+}
+
+// TestWorkerPool_ResultIsolation 测试结果只投递到所属批次
+func TestWorkerPool_ResultIsolation(t *testing.T) {
+	pool := newTestPool(t)
+	ctx := context.Background()
+
+	batchA, _ := pool.SubmitBatch(ctx, makeJobs("A", 1))
+	defer batchA.Cancel()
+	batchB, _ := pool.SubmitBatch(ctx, makeJobs("B", 1))
+	defer batchB.Cancel()
+
+	for i := 0; i < 2; i++ {
+		job, batch, _ := pool.nextJob()
+		batch.deliver(&SegmentResult{ID: job.ID, Index: job.Index})
+	}
+
+	if r := <-batchA.Results(); r.ID != "A" {
+		t.Errorf("批次 A 收到了 %s 的结果", r.ID)
+	}
+	if r := <-batchB.Results(); r.ID != "B" {
+		t.Errorf("批次 B 收到了 %s 的结果", r.ID)
+	}
+}
+
+// TestWorkerPool_PurgeOnCancel 测试请求取消时立即清除排队任务
+func TestWorkerPool_PurgeOnCancel(t *testing.T) {
+	pool := newTestPool(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if _, err := pool.SubmitBatch(ctx, makeJobs("A", 10)); err != nil {
+		t.Fatalf("SubmitBatch() error = %v", err)
+	}
+	other, _ := pool.SubmitBatch(context.Background(), makeJobs("B", 1))
+	defer other.Cancel()
+
+	cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for pool.Stats().QueueLength != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("取消后队列长度 = %d, want 1", pool.Stats().QueueLength)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	job, _, ok := pool.nextJob()
+	if !ok || job.ID != "B" {
+		t.Errorf("取消后应只剩下其他请求的任务")
+	}
+}
+
+// TestWorkerPool_SubmitAfterClose 测试关闭后拒绝提交
+func TestWorkerPool_SubmitAfterClose(t *testing.T) {
+	pool := newTestPool(t)
+	pool.Close()
+
+	if _, err := pool.SubmitBatch(context.Background(), makeJobs("A", 1)); err == nil {
+		t.Error("关闭后提交应返回错误")
+	}
+}
+
+// TestWorkerPool_SegmentCache 测试分段经过缓存服务时，重复分段复用缓存
+func TestWorkerPool_SegmentCache(t *testing.T) {
+	upstream := &mockTTSServiceForEviction{}
+	cached := NewCachingService(upstream, time.Minute, time.Minute, zerolog.Nop())
+	pool := NewWorkerPool(1, cached, zerolog.Nop())
+
+	job := &SegmentJob{
+		ID:      "seg",
+		Context: context.Background(),
+		Request: models.TTSRequest{Text: "第一句。", Voice: "zh-CN-XiaoxiaoNeural"},
+	}
+
+	first := pool.processJob(job, 0)
+	if first.Error != nil || first.CacheHit {
+		t.Fatalf("首次合成应未命中缓存: %+v", first)
+	}
+
+	second := pool.processJob(job, 0)
+	if second.Error != nil || !second.CacheHit {
+		t.Fatalf("相同分段应命中缓存: %+v", second)
+	}
+	if upstream.callCount != 1 {
+		t.Errorf("上游调用次数 = %d, want 1", upstream.callCount)
+	}
+	if len(second.AudioData) != len(first.AudioData) {
+		t.Errorf("缓存音频长度 = %d, want %d", len(second.AudioData), len(first.AudioData))
+	}
+}
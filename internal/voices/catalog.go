@@ -0,0 +1,370 @@
+// Package voices 提供语音目录：筛选、排序、分页和分组语音列表，并按语音目录检查请求中的语音、风格、角色和音频格式
+//
+// 语音目录来自上游的语音列表（ListVoices），在请求发往上游之前检查：
+// 未知的语音和格式直接拒绝；语音不支持的风格和角色按模式处理，
+// fallback 模式改用默认风格（general）并返回警告，strict 模式拒绝请求并列出可用的值。
+package voices
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tts/internal/lang"
+	"tts/internal/models"
+)
+
+var (
+	// ErrUnsupported 表示语音目录中没有请求的语音、风格、角色或格式
+	ErrUnsupported = errors.New("unsupported by voice catalogue")
+	// ErrCatalogUnavailable 表示无法获取语音目录，此时跳过检查
+	ErrCatalogUnavailable = errors.New("voice catalogue unavailable")
+)
+
+// 检查模式
+const (
+	ModeOff      = "off"      // 不检查
+	ModeFallback = "fallback" // 不支持的风格和角色改用默认值，并返回警告
+	ModeStrict   = "strict"   // 不支持的风格和角色拒绝请求
+)
+
+// DefaultStyle 所有语音都支持的默认风格
+const DefaultStyle = "general"
+
+// catalogTTL 语音目录索引的有效期，上游客户端另有自己的缓存
+const catalogTTL = 10 * time.Minute
+
+// catalogRetry 获取语音目录失败后，在这段时间内不再请求上游
+const catalogRetry = 30 * time.Second
+
+// maxSuggestions 未知语音时最多列出的同语言语音数
+const maxSuggestions = 5
+
+// Catalog 按名称索引的语音目录
+type Catalog struct {
+	byName   map[string]*models.Voice // 小写的 ShortName 和 Name
+	byLocale map[string][]string      // 小写的语言 -> ShortName
+}
+
+// NewCatalog 根据语音列表创建目录
+func NewCatalog(list []models.Voice) *Catalog {
+	c := &Catalog{
+		byName:   make(map[string]*models.Voice, len(list)*2),
+		byLocale: make(map[string][]string),
+	}
+	for i := range list {
+		v := &list[i]
+		c.byName[strings.ToLower(v.ShortName)] = v
+		if v.Name != "" {
+			c.byName[strings.ToLower(v.Name)] = v
+		}
+		locale := strings.ToLower(v.Locale)
+		c.byLocale[locale] = append(c.byLocale[locale], v.ShortName)
+	}
+	for _, names := range c.byLocale {
+		sort.Strings(names)
+	}
+	return c
+}
+
+// Lookup 按 ShortName 或完整名称查找语音（忽略大小写）
+func (c *Catalog) Lookup(name string) (*models.Voice, bool) {
+	v, ok := c.byName[strings.ToLower(strings.TrimSpace(name))]
+	return v, ok
+}
+
+// checkVoice 检查语音是否存在，不存在时列出同语言的语音
+func (c *Catalog) checkVoice(name string) (*models.Voice, error) {
+	if v, ok := c.Lookup(name); ok {
+		return v, nil
+	}
+	similar := c.byLocale[strings.ToLower(lang.LocaleOf(name))]
+	if len(similar) == 0 {
+		return nil, fmt.Errorf("%w: unknown voice %q, see /api/voices", ErrUnsupported, name)
+	}
+	if len(similar) > maxSuggestions {
+		similar = similar[:maxSuggestions]
+	}
+	return nil, fmt.Errorf("%w: unknown voice %q, voices for the same locale include: %s",
+		ErrUnsupported, name, strings.Join(similar, ", "))
+}
+
+// Checker 检查请求的语音能力，语音目录在首次使用时获取并定期刷新
+type Checker struct {
+	mode    string
+	list    func(ctx context.Context) ([]models.Voice, error)
+	formats map[string]bool
+	names   []string // 排序后的格式，用于错误信息
+
+	mu       sync.Mutex
+	catalog  *Catalog
+	err      error         // 最近一次获取失败的原因，没有可用的目录时返回
+	expiry   time.Time     // 在此之前直接返回 catalog 或 err
+	fetching chan struct{} // 正在获取语音目录时不为 nil，获取结束时关闭
+}
+
+// NewChecker 创建检查器：list 返回完整的语音列表，formats 为上游支持的音频格式
+func NewChecker(mode string, list func(ctx context.Context) ([]models.Voice, error), formats []string) (*Checker, error) {
+	switch mode {
+	case "":
+		mode = ModeFallback
+	case ModeOff, ModeFallback, ModeStrict:
+	default:
+		return nil, fmt.Errorf("unknown voice check mode %q", mode)
+	}
+	k := &Checker{mode: mode, list: list, formats: make(map[string]bool, len(formats))}
+	for _, f := range formats {
+		k.formats[strings.ToLower(f)] = true
+	}
+	k.names = append(k.names, formats...)
+	sort.Strings(k.names)
+	return k, nil
+}
+
+// Mode 返回检查模式
+func (k *Checker) Mode() string {
+	return k.mode
+}
+
+// Catalog 返回语音目录，获取失败时返回最近一次成功获取的目录。
+// 同一时间只有一个请求获取语音目录（不持有锁），其他请求返回旧的目录或等待获取结束；
+// 获取失败后 catalogRetry 内不再请求上游。
+func (k *Checker) Catalog(ctx context.Context) (*Catalog, error) {
+	for {
+		k.mu.Lock()
+		catalog, err, wait := k.catalog, k.err, k.fetching
+		if time.Now().Before(k.expiry) || (wait != nil && catalog != nil) {
+			k.mu.Unlock()
+			if catalog != nil {
+				return catalog, nil
+			}
+			return nil, err
+		}
+		if wait == nil {
+			break
+		}
+		k.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %v", ErrCatalogUnavailable, ctx.Err())
+		}
+	}
+	done := make(chan struct{})
+	k.fetching = done
+	k.mu.Unlock()
+
+	list, err := k.list(ctx)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.fetching = nil
+	close(done)
+	if err == nil && len(list) > 0 {
+		k.catalog, k.err = NewCatalog(list), nil
+		k.expiry = time.Now().Add(catalogTTL)
+		return k.catalog, nil
+	}
+	if err == nil {
+		err = errors.New("empty voice list")
+	}
+	err = fmt.Errorf("%w: %v", ErrCatalogUnavailable, err)
+	// 请求被取消不代表上游不可用，由下一个请求重新获取
+	if ctx.Err() == nil {
+		k.err = err
+		k.expiry = time.Now().Add(catalogRetry)
+	}
+	if k.catalog != nil {
+		return k.catalog, nil
+	}
+	return nil, err
+}
+
+// Check 检查请求的格式、语音、风格和角色（包括片段、剧本角色表、对白语音和 SSML 中的 <voice>），
+// fallback 模式下不支持的风格和角色在请求中改为默认值，返回说明改动的警告。
+// 无法获取语音目录时只检查格式，返回 ErrCatalogUnavailable。
+func (k *Checker) Check(ctx context.Context, req *models.TTSRequest) ([]string, error) {
+	if k == nil || k.mode == ModeOff {
+		return nil, nil
+	}
+	if req.Format != "" && !k.formats[strings.ToLower(req.Format)] {
+		return nil, fmt.Errorf("%w: unknown format %q, supported formats: %s",
+			ErrUnsupported, req.Format, strings.Join(k.names, ", "))
+	}
+
+	catalog, err := k.Catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r := &requestCheck{catalog: catalog, strict: k.mode == ModeStrict}
+	r.request(req)
+	return r.warnings, r.err
+}
+
+// requestCheck 一次请求的检查状态，记录第一个错误和全部警告
+type requestCheck struct {
+	catalog  *Catalog
+	strict   bool
+	warnings []string
+	err      error
+}
+
+func (r *requestCheck) request(req *models.TTSRequest) {
+	// SSML 请求的语音由 <voice> 元素指定
+	if req.SSML != "" {
+		r.ssml(req.SSML)
+		return
+	}
+
+	voice := r.voice(req.Voice)
+	if r.expression(voice, &req.Style, &req.Role, true) {
+		req.StyleDegree = 0
+	}
+
+	for i := range req.Spans {
+		span := &req.Spans[i]
+		spanVoice := voice
+		if span.Voice != "" {
+			spanVoice = r.voice(span.Voice)
+		}
+		if r.expression(spanVoice, &span.Style, &span.Role, true) {
+			span.StyleDegree = 0
+		}
+	}
+
+	names := make([]string, 0, len(req.Cast))
+	for name := range req.Cast {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		member := req.Cast[name]
+		memberVoice := voice
+		if member.Voice != "" {
+			memberVoice = r.voice(member.Voice)
+		}
+		role := ""
+		if r.expression(memberVoice, &member.Style, &role, true) {
+			req.Cast[name] = member
+		}
+	}
+
+	for _, name := range []string{req.DialogueVoice, req.DialogueMaleVoice, req.DialogueFemaleVoice} {
+		if name != "" {
+			r.voice(name)
+		}
+	}
+}
+
+// voice 检查语音，不存在时记录错误并返回 nil
+func (r *requestCheck) voice(name string) *models.Voice {
+	if r.err != nil {
+		return nil
+	}
+	v, err := r.catalog.checkVoice(name)
+	if err != nil {
+		r.err = err
+	}
+	return v
+}
+
+// expression 检查语音是否支持风格和角色。fix 为 true 时，fallback 模式下把不支持的值改为默认值，
+// 否则只记录警告。返回是否改动了风格。
+func (r *requestCheck) expression(voice *models.Voice, style, role *string, fix bool) bool {
+	if voice == nil || r.err != nil {
+		return false
+	}
+	styleChanged := false
+	if *style != "" && !strings.EqualFold(*style, DefaultStyle) && !contains(voice.StyleList, *style) {
+		if r.unsupported(voice, "style", *style, append([]string{DefaultStyle}, voice.StyleList...)) && fix {
+			*style = ""
+			styleChanged = true
+		}
+	}
+	if *role != "" && !contains(voice.RolePlayList, *role) {
+		if r.unsupported(voice, "role", *role, voice.RolePlayList) && fix {
+			*role = ""
+		}
+	}
+	return styleChanged
+}
+
+// unsupported 记录不支持的值：strict 模式下为错误，fallback 模式下为警告。返回是否可以改用默认值。
+func (r *requestCheck) unsupported(voice *models.Voice, kind, value string, valid []string) bool {
+	if r.strict {
+		options := "none"
+		if len(valid) > 0 {
+			options = strings.Join(valid, ", ")
+		}
+		r.err = fmt.Errorf("%w: voice %s does not support %s %q, supported: %s",
+			ErrUnsupported, voice.ShortName, kind, value, options)
+		return false
+	}
+	fallback := DefaultStyle
+	if kind == "role" {
+		fallback = "default"
+	}
+	r.warnings = append(r.warnings, fmt.Sprintf("%s %+q not supported by %s, using %s", kind, value, voice.ShortName, fallback))
+	return true
+}
+
+// ssml 检查 SSML 中 <voice name> 的语音和 <mstts:express-as> 的风格与角色。
+// SSML 不会被改写，fallback 模式下不支持的风格只返回警告（上游按默认风格朗读）；
+// 无法解析的 SSML 不在这里报错。
+func (r *requestCheck) ssml(content string) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = false
+	var stack []*models.Voice
+	for r.err == nil {
+		token, err := decoder.Token()
+		if err != nil {
+			return
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "voice":
+				var current *models.Voice
+				if len(stack) > 0 {
+					current = stack[len(stack)-1]
+				}
+				if name := attr(t, "name"); name != "" {
+					current = r.voice(name)
+				}
+				stack = append(stack, current)
+			case "express-as":
+				if len(stack) > 0 {
+					style, role := attr(t, "style"), attr(t, "role")
+					r.expression(stack[len(stack)-1], &style, &role, false)
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == "voice" && len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+}
+
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return strings.TrimSpace(a.Value)
+		}
+	}
+	return ""
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package voices
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tts/internal/models"
+)
+
+var testVoices = []models.Voice{
+	{
+		Name:         "Microsoft Server Speech Text to Speech Voice (zh-CN, XiaoxiaoNeural)",
+		ShortName:    "zh-CN-XiaoxiaoNeural",
+		Locale:       "zh-CN",
+		StyleList:    []string{"cheerful", "sad"},
+		RolePlayList: []string{"Girl", "YoungAdultFemale"},
+	},
+	{ShortName: "zh-CN-YunxiNeural", Locale: "zh-CN", StyleList: []string{"narration-relaxed"}},
+	{ShortName: "en-US-JennyNeural", Locale: "en-US"},
+}
+
+func newTestChecker(t *testing.T, mode string, list func(context.Context) ([]models.Voice, error)) *Checker {
+	t.Helper()
+	if list == nil {
+		list = func(context.Context) ([]models.Voice, error) { return testVoices, nil }
+	}
+	k, err := NewChecker(mode, list, []string{"audio-24khz-48kbitrate-mono-mp3", "riff-24khz-16bit-mono-pcm"})
+	if err != nil {
+		t.Fatalf("NewChecker() error = %v", err)
+	}
+	return k
+}
+
+// TestChecker_Fallback 测试 fallback 模式：不支持的风格和角色改为默认值并返回警告
+func TestChecker_Fallback(t *testing.T) {
+	k := newTestChecker(t, ModeFallback, nil)
+	req := models.TTSRequest{
+		Voice: "zh-cn-xiaoxiaoneural", Style: "Cheerful", Role: "Boy",
+		Spans: []models.Span{
+			{Text: "一", Voice: "zh-CN-YunxiNeural", Style: "cheerful", StyleDegree: 1.5},
+			{Text: "二", Style: "general"},
+		},
+		Cast: map[string]models.CastMember{"旁白": {Voice: "en-US-JennyNeural", Style: "sad"}},
+	}
+	warnings, err := k.Check(context.Background(), &req)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(warnings) != 3 {
+		t.Errorf("warnings = %v, want 3", warnings)
+	}
+	if req.Style != "Cheerful" || req.Role != "" {
+		t.Errorf("请求 style = %q, role = %q, want Cheerful 和空角色", req.Style, req.Role)
+	}
+	if got := req.Spans[0]; got.Style != "" || got.StyleDegree != 0 {
+		t.Errorf("片段应改用默认风格, got %+v", got)
+	}
+	if got := req.Cast["旁白"].Style; got != "" {
+		t.Errorf("角色表风格应改为默认值, got %q", got)
+	}
+}
+
+// TestChecker_Strict 测试 strict 模式和始终拒绝的情况，错误信息列出可用的值
+func TestChecker_Strict(t *testing.T) {
+	tests := []struct {
+		name, mode string
+		req        models.TTSRequest
+		want       string
+	}{
+		{"不支持的风格", ModeStrict, models.TTSRequest{Voice: "zh-CN-YunxiNeural", Style: "cheerful"}, "general, narration-relaxed"},
+		{"不支持的角色", ModeStrict, models.TTSRequest{Voice: "zh-CN-YunxiNeural", Role: "Girl"}, "supported: none"},
+		{"片段语音的风格", ModeStrict, models.TTSRequest{Voice: "zh-CN-XiaoxiaoNeural", Spans: []models.Span{{Text: "x", Voice: "en-US-JennyNeural", Style: "sad"}}}, "en-US-JennyNeural"},
+		{"未知语音", ModeFallback, models.TTSRequest{Voice: "zh-CN-XiaoNeural"}, "zh-CN-XiaoxiaoNeural, zh-CN-YunxiNeural"},
+		{"未知语言的语音", ModeFallback, models.TTSRequest{Voice: "xx-YY-Nobody"}, "/api/voices"},
+		{"未知对白语音", ModeFallback, models.TTSRequest{Voice: "zh-CN-YunxiNeural", DialogueVoice: "zh-CN-Nobody"}, "zh-CN-Nobody"},
+		{"未知格式", ModeFallback, models.TTSRequest{Voice: "zh-CN-YunxiNeural", Format: "ogg"}, "riff-24khz-16bit-mono-pcm"},
+		{"SSML 未知语音", ModeFallback, models.TTSRequest{SSML: `<speak><voice name="zh-CN-Nobody">你好</voice></speak>`}, "zh-CN-Nobody"},
+		{"SSML 不支持的风格", ModeStrict, models.TTSRequest{SSML: `<speak xmlns:mstts="https://www.w3.org/2001/mstts"><voice name="zh-CN-YunxiNeural"><mstts:express-as style="sad">你好</mstts:express-as></voice></speak>`}, `style "sad"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestChecker(t, tt.mode, nil).Check(context.Background(), &tt.req)
+			if !errors.Is(err, ErrUnsupported) {
+				t.Fatalf("Check() error = %v, want ErrUnsupported", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("错误信息 %q 应包含 %q", err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+// TestChecker_SSML 测试 SSML 中按 <voice> 检查风格，fallback 模式下只返回警告不改写 SSML
+func TestChecker_SSML(t *testing.T) {
+	ssml := `<speak xmlns:mstts="https://www.w3.org/2001/mstts">` +
+		`<voice name="Microsoft Server Speech Text to Speech Voice (zh-CN, XiaoxiaoNeural)"><mstts:express-as style="sad">一</mstts:express-as></voice>` +
+		`<voice name="zh-CN-YunxiNeural"><mstts:express-as style="sad">二</mstts:express-as></voice></speak>`
+	req := models.TTSRequest{SSML: ssml}
+	warnings, err := newTestChecker(t, ModeFallback, nil).Check(context.Background(), &req)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	want := []string{`style "sad" not supported by zh-CN-YunxiNeural, using general`}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("warnings = %v, want %v", warnings, want)
+	}
+	if req.SSML != ssml {
+		t.Errorf("SSML 不应被改写")
+	}
+}
+
+// TestChecker_Catalog 测试语音列表不可用时跳过检查，获取成功后缓存，模式为 off 时不检查
+func TestChecker_Catalog(t *testing.T) {
+	calls := 0
+	failing := true
+	k := newTestChecker(t, ModeStrict, func(context.Context) ([]models.Voice, error) {
+		calls++
+		if failing {
+			return nil, errors.New("network down")
+		}
+		return testVoices, nil
+	})
+	req := models.TTSRequest{Voice: "zh-CN-Nobody"}
+	for i := 0; i < 2; i++ {
+		if _, err := k.Check(context.Background(), &req); !errors.Is(err, ErrCatalogUnavailable) {
+			t.Fatalf("期望 ErrCatalogUnavailable, got %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("获取失败后应等待 catalogRetry 再重试, 获取了 %d 次", calls)
+	}
+
+	failing = false
+	k.expiry = time.Time{} // 跳过重试等待
+	for i := 0; i < 2; i++ {
+		if _, err := k.Check(context.Background(), &req); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("期望 ErrUnsupported, got %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("语音列表应被缓存, 获取了 %d 次", calls)
+	}
+
+	// 目录过期后获取失败：返回旧的目录，重试等待期间不再请求上游
+	failing = true
+	k.expiry = time.Time{}
+	for i := 0; i < 2; i++ {
+		if _, err := k.Check(context.Background(), &req); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("应使用旧的目录, got %v", err)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("获取失败后应等待 catalogRetry 再重试, 获取了 %d 次", calls)
+	}
+
+	if _, err := newTestChecker(t, ModeOff, nil).Check(context.Background(), &req); err != nil {
+		t.Errorf("off 模式不应检查, got %v", err)
+	}
+	if _, err := NewChecker("loose", nil, nil); err == nil {
+		t.Errorf("未知模式应返回错误")
+	}
+}
+
+// TestChecker_CatalogConcurrent 测试并发请求共用一次语音目录的获取
+func TestChecker_CatalogConcurrent(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	k := newTestChecker(t, ModeStrict, func(context.Context) ([]models.Voice, error) {
+		calls.Add(1)
+		<-release
+		return testVoices, nil
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := k.Catalog(context.Background()); err != nil {
+				t.Errorf("Catalog() error = %v", err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("并发请求应共用一次获取, 获取了 %d 次", n)
+	}
+}
@@ -0,0 +1,242 @@
+package voices
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"tts/internal/models"
+)
+
+// ErrInvalidQuery 表示语音列表的查询参数无效
+var ErrInvalidQuery = errors.New("invalid voice query")
+
+// 排序字段，前缀 - 表示降序
+const (
+	SortPreferred      = "preferred"        // 优先的语音、优先的语言排在前面
+	SortLocale         = "locale"           // 语言区域
+	SortName           = "name"             // ShortName
+	SortLocalName      = "local_name"       // 本地化名称
+	SortDisplayName    = "display_name"     // 显示名称
+	SortGender         = "gender"           // 性别
+	SortStatus         = "status"           // 发布状态
+	SortWordsPerMinute = "words_per_minute" // 每分钟词数
+)
+
+// MaxPageSize 每页语音数的上限
+const MaxPageSize = 500
+
+// Query 语音列表的筛选条件，空字段不筛选，字符串比较忽略大小写
+type Query struct {
+	Locale    string // 语言区域前缀，如 zh、zh-CN
+	Language  string // 语言（如 zh、en），包括多语言语音可以朗读的其他语言
+	Gender    string // Female、Male、Neutral
+	Style     string // 支持的说话风格
+	Role      string // 支持的角色扮演
+	Status    string // 发布状态，如 GA、Preview
+	VoiceType string // 语音类型，如 Neural
+	Search    string // 在 ShortName、DisplayName、LocalName、LocaleName 和 Name 中搜索
+}
+
+// Filter 返回符合条件的语音，不修改 list
+func Filter(list []models.Voice, q Query) []models.Voice {
+	search := strings.ToLower(strings.TrimSpace(q.Search))
+	result := make([]models.Voice, 0, len(list))
+	for _, v := range list {
+		switch {
+		case q.Locale != "" && !hasPrefixFold(v.Locale, q.Locale):
+		case q.Language != "" && !speaks(v, q.Language):
+		case q.Gender != "" && !strings.EqualFold(v.Gender, q.Gender):
+		case q.Style != "" && !contains(v.StyleList, q.Style):
+		case q.Role != "" && !contains(v.RolePlayList, q.Role):
+		case q.Status != "" && !strings.EqualFold(v.Status, q.Status):
+		case q.VoiceType != "" && !strings.EqualFold(v.VoiceType, q.VoiceType):
+		case search != "" && !matches(v, search):
+		default:
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// speaks 语音的语言区域或其他语言区域是否属于该语言
+func speaks(v models.Voice, language string) bool {
+	for _, locale := range append([]string{v.Locale}, v.SecondaryLocaleList...) {
+		if strings.EqualFold(strings.SplitN(locale, "-", 2)[0], language) || strings.EqualFold(locale, language) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(v models.Voice, search string) bool {
+	for _, field := range []string{v.ShortName, v.DisplayName, v.LocalName, v.LocaleName, v.Name} {
+		if strings.Contains(strings.ToLower(field), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortKey 一个排序字段
+type sortKey struct {
+	field string
+	desc  bool
+}
+
+// Sorter 按配置的字段顺序排序语音列表
+type Sorter struct {
+	keys             []sortKey
+	preferredVoices  []string // 小写的 ShortName 模式
+	preferredLocales []string // 小写的语言区域前缀
+}
+
+// NewSorter 创建排序器：keys 为排序字段（如 preferred、-words_per_minute），
+// preferredVoices 为优先的语音（支持 * 通配符），preferredLocales 为优先的语言区域前缀，均按顺序优先
+func NewSorter(keys, preferredVoices, preferredLocales []string) (*Sorter, error) {
+	s := &Sorter{}
+	for _, pattern := range preferredVoices {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: invalid voice pattern %q", ErrInvalidQuery, pattern)
+		}
+		s.preferredVoices = append(s.preferredVoices, pattern)
+	}
+	for _, locale := range preferredLocales {
+		s.preferredLocales = append(s.preferredLocales, strings.ToLower(strings.TrimSpace(locale)))
+	}
+	return s.WithKeys(keys)
+}
+
+// WithKeys 返回使用另一组排序字段的排序器，优先的语音和语言保持不变
+func (s *Sorter) WithKeys(keys []string) (*Sorter, error) {
+	sorted := &Sorter{preferredVoices: s.preferredVoices, preferredLocales: s.preferredLocales}
+	for _, key := range keys {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			continue
+		}
+		k := sortKey{field: strings.TrimPrefix(key, "-"), desc: strings.HasPrefix(key, "-")}
+		switch k.field {
+		case SortPreferred, SortLocale, SortName, SortLocalName, SortDisplayName, SortGender, SortStatus, SortWordsPerMinute:
+		default:
+			return nil, fmt.Errorf("%w: unknown sort field %q", ErrInvalidQuery, key)
+		}
+		sorted.keys = append(sorted.keys, k)
+	}
+	return sorted, nil
+}
+
+// Sort 按排序字段排序，字段都相同时按 ShortName 排序
+func (s *Sorter) Sort(list []models.Voice) {
+	sort.SliceStable(list, func(i, j int) bool {
+		for _, k := range s.keys {
+			c := s.compare(k.field, &list[i], &list[j])
+			if k.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return list[i].ShortName < list[j].ShortName
+	})
+}
+
+func (s *Sorter) compare(field string, a, b *models.Voice) int {
+	switch field {
+	case SortPreferred:
+		return compareInts(s.rank(a), s.rank(b))
+	case SortLocale:
+		return strings.Compare(a.Locale, b.Locale)
+	case SortName:
+		return strings.Compare(a.ShortName, b.ShortName)
+	case SortLocalName:
+		return strings.Compare(a.LocalName, b.LocalName)
+	case SortDisplayName:
+		return strings.Compare(a.DisplayName, b.DisplayName)
+	case SortGender:
+		return strings.Compare(a.Gender, b.Gender)
+	case SortStatus:
+		return strings.Compare(a.Status, b.Status)
+	case SortWordsPerMinute:
+		wa, _ := strconv.ParseFloat(a.WordsPerMinute, 64)
+		wb, _ := strconv.ParseFloat(b.WordsPerMinute, 64)
+		return compareInts(int(wa*100), int(wb*100))
+	}
+	return 0
+}
+
+// rank 优先级：先按优先的语音，再按优先的语言，都不匹配的排在最后
+func (s *Sorter) rank(v *models.Voice) int {
+	name := strings.ToLower(v.ShortName)
+	for i, pattern := range s.preferredVoices {
+		if ok, _ := path.Match(pattern, name); ok {
+			return i
+		}
+	}
+	for i, prefix := range s.preferredLocales {
+		if hasPrefixFold(v.Locale, prefix) {
+			return len(s.preferredVoices) + i
+		}
+	}
+	return len(s.preferredVoices) + len(s.preferredLocales)
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Paginate 返回第 page 页（从 1 开始），pageSize 为 0 时不分页
+func Paginate(list []models.Voice, page, pageSize int) ([]models.Voice, error) {
+	if pageSize == 0 && page <= 1 {
+		return list, nil
+	}
+	if page < 1 || pageSize < 1 || pageSize > MaxPageSize {
+		return nil, fmt.Errorf("%w: page must be at least 1 and page_size between 1 and %d", ErrInvalidQuery, MaxPageSize)
+	}
+	// 先按页数比较，避免 (page-1)*pageSize 溢出
+	if page-1 >= (len(list)+pageSize-1)/pageSize {
+		return []models.Voice{}, nil
+	}
+	start := (page - 1) * pageSize
+	return list[start:min(start+pageSize, len(list))], nil
+}
+
+// Group 按语言区域分组的语音
+type Group struct {
+	Locale     string         `json:"locale"`
+	LocaleName string         `json:"locale_name"`
+	Count      int            `json:"count"`
+	Voices     []models.Voice `json:"voices"`
+}
+
+// GroupByLocale 按语言区域分组，分组按语言区域第一次出现的顺序排列，组内保持原顺序
+func GroupByLocale(list []models.Voice) []Group {
+	var groups []Group
+	index := make(map[string]int)
+	for _, v := range list {
+		i, ok := index[v.Locale]
+		if !ok {
+			i = len(groups)
+			index[v.Locale] = i
+			groups = append(groups, Group{Locale: v.Locale, LocaleName: v.LocaleName})
+		}
+		groups[i].Voices = append(groups[i].Voices, v)
+		groups[i].Count++
+	}
+	return groups
+}
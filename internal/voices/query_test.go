@@ -0,0 +1,119 @@
+package voices
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"tts/internal/models"
+)
+
+var listVoices = []models.Voice{
+	{ShortName: "en-US-AvaMultilingualNeural", LocalName: "Ava", Gender: "Female", Locale: "en-US", LocaleName: "English (United States)", Status: "GA", WordsPerMinute: "150", SecondaryLocaleList: []string{"zh-CN", "ja-JP"}},
+	{ShortName: "zh-CN-YunxiNeural", LocalName: "云希", Gender: "Male", Locale: "zh-CN", LocaleName: "Chinese (Mandarin, Simplified)", Status: "GA", WordsPerMinute: "293", StyleList: []string{"cheerful"}, RolePlayList: []string{"Boy"}},
+	{ShortName: "zh-TW-HsiaoChenNeural", LocalName: "曉臻", Gender: "Female", Locale: "zh-TW", Status: "GA"},
+	{ShortName: "zh-CN-XiaoxiaoNeural", LocalName: "晓晓", Gender: "Female", Locale: "zh-CN", Status: "GA", StyleList: []string{"cheerful", "sad"}, RolePlayList: []string{"Girl"}},
+	{ShortName: "zh-CN-XiaoxiaoDialectsNeural", LocalName: "晓晓 方言", Gender: "Female", Locale: "zh-CN", Status: "Preview"},
+}
+
+func shortNames(list []models.Voice) []string {
+	names := make([]string, len(list))
+	for i, v := range list {
+		names[i] = v.ShortName
+	}
+	return names
+}
+
+// TestFilter 测试各筛选条件和搜索
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"语言区域前缀", Query{Locale: "zh-cn"}, []string{"zh-CN-YunxiNeural", "zh-CN-XiaoxiaoNeural", "zh-CN-XiaoxiaoDialectsNeural"}},
+		{"语言包括其他语言区域", Query{Language: "ja"}, []string{"en-US-AvaMultilingualNeural"}},
+		{"性别和风格", Query{Gender: "female", Style: "Cheerful"}, []string{"zh-CN-XiaoxiaoNeural"}},
+		{"角色", Query{Role: "boy"}, []string{"zh-CN-YunxiNeural"}},
+		{"状态", Query{Status: "preview"}, []string{"zh-CN-XiaoxiaoDialectsNeural"}},
+		{"搜索本地名称", Query{Search: "晓晓"}, []string{"zh-CN-XiaoxiaoNeural", "zh-CN-XiaoxiaoDialectsNeural"}},
+		{"搜索语言名称", Query{Search: "mandarin"}, []string{"zh-CN-YunxiNeural"}},
+		{"没有匹配", Query{Locale: "fr"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shortNames(Filter(listVoices, tt.query)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSorter 测试默认排序（晓晓优先、中文其次、按本地名称）和请求指定的排序
+func TestSorter(t *testing.T) {
+	s, err := NewSorter([]string{SortPreferred, SortLocalName}, []string{"*xiaoxiao*"}, []string{"zh"})
+	if err != nil {
+		t.Fatalf("NewSorter() error = %v", err)
+	}
+	list := append([]models.Voice(nil), listVoices...)
+	s.Sort(list)
+	want := []string{"zh-CN-XiaoxiaoNeural", "zh-CN-XiaoxiaoDialectsNeural", "zh-CN-YunxiNeural", "zh-TW-HsiaoChenNeural", "en-US-AvaMultilingualNeural"}
+	if got := shortNames(list); !reflect.DeepEqual(got, want) {
+		t.Errorf("默认排序 = %v, want %v", got, want)
+	}
+
+	byWPM, err := s.WithKeys([]string{"-words_per_minute", "name"})
+	if err != nil {
+		t.Fatalf("WithKeys() error = %v", err)
+	}
+	byWPM.Sort(list)
+	want = []string{"zh-CN-YunxiNeural", "en-US-AvaMultilingualNeural", "zh-CN-XiaoxiaoDialectsNeural", "zh-CN-XiaoxiaoNeural", "zh-TW-HsiaoChenNeural"}
+	if got := shortNames(list); !reflect.DeepEqual(got, want) {
+		t.Errorf("按语速降序 = %v, want %v", got, want)
+	}
+
+	if _, err := s.WithKeys([]string{"age"}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("未知排序字段期望 ErrInvalidQuery, got %v", err)
+	}
+	if _, err := NewSorter(nil, []string{"zh-["}, nil); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("无效的语音模式期望 ErrInvalidQuery, got %v", err)
+	}
+}
+
+// TestPaginateAndGroup 测试分页和按语言区域分组
+func TestPaginateAndGroup(t *testing.T) {
+	page, err := Paginate(listVoices, 2, 2)
+	if err != nil {
+		t.Fatalf("Paginate() error = %v", err)
+	}
+	if got, want := shortNames(page), []string{"zh-TW-HsiaoChenNeural", "zh-CN-XiaoxiaoNeural"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Paginate() = %v, want %v", got, want)
+	}
+	if page, _ := Paginate(listVoices, 9, 2); len(page) != 0 {
+		t.Errorf("超出范围的页应为空, got %v", shortNames(page))
+	}
+	if page, err := Paginate(listVoices, math.MaxInt, MaxPageSize); err != nil || len(page) != 0 {
+		t.Errorf("超大页码应返回空列表, got %v, %v", page, err)
+	}
+	if all, _ := Paginate(listVoices, 1, 0); len(all) != len(listVoices) {
+		t.Errorf("page_size 为 0 时不分页")
+	}
+	for _, p := range [][2]int{{0, 10}, {2, 0}, {1, MaxPageSize + 1}} {
+		if _, err := Paginate(listVoices, p[0], p[1]); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("Paginate(%d, %d) error = %v, want ErrInvalidQuery", p[0], p[1], err)
+		}
+	}
+
+	groups := GroupByLocale(listVoices)
+	var locales []string
+	for _, g := range groups {
+		locales = append(locales, g.Locale)
+	}
+	if want := []string{"en-US", "zh-CN", "zh-TW"}; !reflect.DeepEqual(locales, want) {
+		t.Errorf("GroupByLocale() = %v, want %v", locales, want)
+	}
+	if groups[1].Count != 3 || groups[1].Voices[0].ShortName != "zh-CN-YunxiNeural" {
+		t.Errorf("分组内容错误: %+v", groups[1])
+	}
+}